@@ -0,0 +1,87 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const envTargetAddressFile = "TARGET_ADDRESS_FILE"
+
+// newAddressFileCheck returns a check reading the target address from TARGET_ADDRESS_FILE before each attempt.
+// The check is rebuilt whenever the address changed, so an address written or updated by another process is picked up.
+func newAddressFileCheck(cfg Config, logger *slog.Logger) checkFunc {
+	var address string
+	var check checkFunc
+
+	return func(ctx context.Context) error {
+		current, err := readTargetAddressFile(cfg.TargetAddressFile, cfg.CheckType)
+		if err != nil {
+			return err
+		}
+
+		if check == nil || current != address {
+			if check != nil {
+				logger.Info(fmt.Sprintf("Address of %s changed from %s to %s", cfg.TargetName, address, current))
+			}
+
+			targetCfg := cfg
+			targetCfg.TargetAddress, targetCfg.TargetAddressFile = current, ""
+			check, address = newTargetCheck(targetCfg, logger), current
+		}
+
+		return check(ctx)
+	}
+}
+
+// readTargetAddressFile reads and validates the target address from the given file.
+// A missing or empty file is reported as an error, as the address may not have been written yet.
+func readTargetAddressFile(path, checkType string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%s %s does not exist yet", envTargetAddressFile, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	address := strings.TrimSpace(string(data))
+	if address == "" {
+		return "", fmt.Errorf("%s %s is empty", envTargetAddressFile, path)
+	}
+
+	if err := validateAddress(envTargetAddressFile, checkType, address); err != nil {
+		return "", err
+	}
+
+	return address, nil
+}
+
+// validateTargetAddressFile checks if the address file is supported with the given configuration.
+// Without TARGET_NAME, the name is inferred from TARGET_ADDRESS, which is otherwise ignored.
+func validateTargetAddressFile(cfg *Config) error {
+	if cfg.TargetAddressFile == "" {
+		return nil
+	}
+
+	if len(cfg.Targets) > 0 || strings.Contains(cfg.TargetAddress, ",") {
+		return fmt.Errorf("%s cannot be combined with multiple targets", envTargetAddressFile)
+	}
+
+	if cfg.RequireDualStack {
+		return fmt.Errorf("%s cannot be combined with %s", envTargetAddressFile, envRequireDualStack)
+	}
+
+	if cfg.TargetName == "" {
+		if cfg.TargetAddress == "" {
+			return fmt.Errorf("%s is required with %s", envTargetName, envTargetAddressFile)
+		}
+		cfg.TargetName = inferTargetName(cfg.TargetAddress)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddressFileCheck(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "address")
+	cfg := Config{TargetName: "database", TargetAddressFile: path, CheckType: checkTypeTCP, DialTimeout: time.Second}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+	check := newAddressFileCheck(cfg, logger)
+
+	steps := []struct {
+		name    string
+		address string // written to the file unless empty
+		err     string
+	}{
+		{name: "File missing", err: "TARGET_ADDRESS_FILE " + path + " does not exist yet"},
+		{name: "Address written late", address: newListener(t).Addr().String() + "\n"},
+		{name: "Address updated", address: closedAddress(t), err: "connection refused"},
+		{name: "Invalid address", address: "database", err: "invalid TARGET_ADDRESS_FILE format, must be host:port"},
+	}
+
+	for _, step := range steps {
+		if step.address != "" {
+			if err := os.WriteFile(path, []byte(step.address), 0o644); err != nil {
+				t.Fatalf("%s: failed to write address file: %v", step.name, err)
+			}
+		}
+
+		err := check(context.Background())
+		if step.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", step.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), step.err) {
+			t.Errorf("%s: expected error containing %q but got %v", step.name, step.err, err)
+		}
+	}
+
+	if !strings.Contains(stdOut.String(), "Address of database changed from ") {
+		t.Errorf("Expected the changed address to be logged but got %q", stdOut.String())
+	}
+}
+
+func TestReadTargetAddressFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		checkType string
+		expected  string
+		err       string
+	}{
+		{name: "Address", content: "db:5432", checkType: checkTypeTCP, expected: "db:5432"},
+		{name: "Trailing newline", content: "db:5432\n", checkType: checkTypeTCP, expected: "db:5432"},
+		{name: "URL", content: "http://api:8080/healthz", checkType: checkTypeHTTP, expected: "http://api:8080/healthz"},
+		{name: "Empty", content: " \n", checkType: checkTypeTCP, err: "is empty"},
+		{name: "Schema", content: "tcp://db:5432", checkType: checkTypeTCP, err: "TARGET_ADDRESS_FILE should not include a schema (tcp)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "address")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write address file: %v", err)
+			}
+
+			address, err := readTargetAddressFile(path, tt.checkType)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if address != tt.expected {
+				t.Errorf("Expected address %q but got %q", tt.expected, address)
+			}
+		})
+	}
+}
+
+func TestValidateTargetAddressFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+		err      string
+	}{
+		{name: "Disabled", cfg: Config{}},
+		{name: "Name", cfg: Config{TargetAddressFile: "/run/db/address", TargetName: "database"}, expected: "database"},
+		{name: "Name from address", cfg: Config{TargetAddressFile: "/run/db/address", TargetAddress: "postgres.local:5432"}, expected: "postgres"},
+		{name: "Name missing", cfg: Config{TargetAddressFile: "/run/db/address"}, err: "TARGET_NAME is required with TARGET_ADDRESS_FILE"},
+		{name: "Multiple addresses", cfg: Config{TargetAddressFile: "/run/db/address", TargetAddress: "db-1:5432,db-2:5432"}, err: "TARGET_ADDRESS_FILE cannot be combined with multiple targets"},
+		{name: "Indexed targets", cfg: Config{TargetAddressFile: "/run/db/address", Targets: []Target{{Name: "db", Address: "db:5432"}}}, err: "TARGET_ADDRESS_FILE cannot be combined with multiple targets"},
+		{name: "Dual stack", cfg: Config{TargetAddressFile: "/run/db/address", TargetName: "database", RequireDualStack: true}, err: "TARGET_ADDRESS_FILE cannot be combined with REQUIRE_DUAL_STACK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg
+			err := validateTargetAddressFile(&cfg)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.TargetName != tt.expected {
+				t.Errorf("Expected target name %q but got %q", tt.expected, cfg.TargetName)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"time"
+)
+
+const envAttemptTimeout = "ATTEMPT_TIMEOUT"
+
+// withAttemptTimeout bounds every attempt of the check by the timeout, including all protocol exchanges after connecting.
+// A zero timeout returns the check unchanged.
+func withAttemptTimeout(timeout time.Duration, check checkFunc) checkFunc {
+	if timeout <= 0 {
+		return check
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return check(ctx)
+	}
+}
+
+// withPanicRecovery turns a panic of the check, e.g. on a malformed response, into a failed attempt,
+// so a single bad response does not end the wait. The panic is logged with its stack trace at debug level.
+// Panics in goroutines started by the check cannot be recovered.
+func withPanicRecovery(cfg Config, logger *slog.Logger, check checkFunc) checkFunc {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("check panicked: %v", r)
+				logger.Error(fmt.Sprintf("%s check panicked, treating the attempt as not ready", cfg.TargetName),
+					slog.String("panic", fmt.Sprint(r)))
+				logger.Debug(fmt.Sprintf("%s check panic stack trace", cfg.TargetName), slog.String("stack", string(debug.Stack())))
+			}
+		}()
+
+		return check(ctx)
+	}
+}
+
+// withOnAttempt calls onAttempt after every attempt of the check with the number of the attempt and its result.
+// A nil callback returns the check unchanged.
+func withOnAttempt(onAttempt func(attempt int, err error), check checkFunc) checkFunc {
+	if onAttempt == nil {
+		return check
+	}
+	var attempt int
+	return func(ctx context.Context) error {
+		err := check(ctx)
+		attempt++
+		onAttempt(attempt, err)
+		return err
+	}
+}
+
+// bindConnDeadline sets the deadline of the connection to the timeout or the deadline of the context, whichever is earlier,
+// and interrupts pending reads and writes once the context is done.
+// The returned function must be called once the connection is no longer used.
+func bindConnDeadline(ctx context.Context, conn net.Conn, timeout time.Duration) (func() bool, error) {
+	deadline, ok := ctx.Deadline()
+	if timeout > 0 && (!ok || time.Now().Add(timeout).Before(deadline)) {
+		deadline, ok = time.Now().Add(timeout), true
+	}
+
+	if ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	return context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	}), nil
+}
+
+// validateAttemptTimeout checks if the attempt timeout is valid.
+func validateAttemptTimeout(cfg Config) error {
+	if cfg.AttemptTimeout < 0 {
+		return fmt.Errorf("invalid %s value: attempt timeout cannot be negative", envAttemptTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHungServer starts a TCP server accepting connections without ever responding.
+func newHungServer(t *testing.T) string {
+	t.Helper()
+
+	lis := newListener(t)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name  string
+		cfg   func(t *testing.T) Config
+		ready bool
+	}{
+		{
+			name: "Probe bounded by DIAL_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeTCP, TargetAddress: newHungServer(t), ProbeExpect: []byte("+PONG"), DialTimeout: 100 * time.Millisecond}
+			},
+		},
+		{
+			name: "Probe bounded by ATTEMPT_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeTCP, TargetAddress: newHungServer(t), ProbeExpect: []byte("+PONG"), DialTimeout: time.Minute, AttemptTimeout: 100 * time.Millisecond}
+			},
+		},
+		{
+			name: "Banner bounded by ATTEMPT_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeTCP, TargetAddress: newHungServer(t), CaptureResponseFile: t.TempDir() + "/banner", DialTimeout: time.Minute, AttemptTimeout: 100 * time.Millisecond}
+			},
+			ready: true, // a target sending no banner is ready with an empty banner
+		},
+		{
+			name: "TLS handshake bounded by ATTEMPT_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeTLS, TargetAddress: newHungServer(t), DialTimeout: time.Minute, AttemptTimeout: 100 * time.Millisecond}
+			},
+		},
+		{
+			name: "TLS handshake bounded by DIAL_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeTLS, TargetAddress: newHungServer(t), DialTimeout: 100 * time.Millisecond}
+			},
+		},
+		{
+			name: "HTTP response bounded by ATTEMPT_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				return Config{CheckType: checkTypeHTTP, TargetAddress: "http://" + newHungServer(t), DialTimeout: time.Minute, AttemptTimeout: 100 * time.Millisecond}
+			},
+		},
+		{
+			name: "HTTP body bounded by ATTEMPT_TIMEOUT",
+			cfg: func(t *testing.T) Config {
+				release := make(chan struct{})
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					w.(http.Flusher).Flush()
+					<-release // never finish the body
+				}))
+				t.Cleanup(server.Close)
+				t.Cleanup(func() { close(release) })
+
+				return Config{CheckType: checkTypeHTTP, TargetAddress: server.URL, DialTimeout: time.Minute, AttemptTimeout: 100 * time.Millisecond}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg(t)
+			check := withAttemptTimeout(cfg.AttemptTimeout, newCheck(cfg, &net.Dialer{Timeout: cfg.DialTimeout}, logger))
+
+			start := time.Now()
+			err := check(context.Background())
+			elapsed := time.Since(start)
+
+			if tt.ready && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !tt.ready && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if elapsed > 2*time.Second {
+				t.Errorf("Expected the attempt to time out but it took %s", elapsed)
+			}
+		})
+	}
+
+	t.Run("Canceled context interrupts probe", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := probe(ctx, &net.Dialer{}, newHungServer(t), nil, []byte("+PONG"), false, time.Minute)
+		if err == nil {
+			t.Error("Expected error but got nil")
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the probe to end on cancel but it took %s", elapsed)
+		}
+	})
+
+	t.Run("Successful attempt", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, 0)
+		check := withAttemptTimeout(time.Second, func(ctx context.Context) error {
+			return checkTLS(ctx, &net.Dialer{}, address, &tls.Config{RootCAs: pool}, handshakeRetrier{})
+		})
+
+		if err := check(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestValidateAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	err := validateAttemptTimeout(Config{AttemptTimeout: -time.Second})
+
+	expected := "invalid ATTEMPT_TIMEOUT value: attempt timeout cannot be negative"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestOnAttempt(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		attempt int
+		err     error
+	}
+	var calls []call
+
+	refused := errors.New("connection refused")
+	failures := 2
+	cfg := Config{
+		TargetName: "database",
+		CheckType:  checkTypeTCP,
+		Interval:   10 * time.Millisecond,
+		OnAttempt: func(attempt int, err error) {
+			calls = append(calls, call{attempt, err})
+		},
+	}
+	check := func(ctx context.Context) error {
+		if failures > 0 {
+			failures--
+			return refused
+		}
+		return nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []call{{1, refused}, {2, refused}, {3, nil}}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v but got %v", expected, calls)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	t.Parallel()
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	var attemptErrs []error
+	cfg := Config{
+		TargetName: "database",
+		CheckType:  checkTypeTCP,
+		Interval:   10 * time.Millisecond,
+		LogAttempt: slog.LevelWarn,
+		OnAttempt: func(attempt int, err error) {
+			attemptErrs = append(attemptErrs, err)
+		},
+	}
+
+	// the first response is malformed and makes the check panic
+	panicked := false
+	check := func(ctx context.Context) error {
+		if !panicked {
+			panicked = true
+			var response map[string]string
+			response["status"] = "SERVING"
+		}
+		return nil
+	}
+
+	if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(attemptErrs) != 2 || attemptErrs[0] == nil || !strings.HasPrefix(attemptErrs[0].Error(), "check panicked: ") {
+		t.Errorf("Expected the panic to fail the first attempt but got %v", attemptErrs)
+	}
+	for _, expected := range []string{
+		"database check panicked, treating the attempt as not ready",
+		"database is not ready ✗",
+		"database is ready ✓",
+	} {
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package wait
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envBackoff    = "BACKOFF"
+	envBackoffMax = "BACKOFF_MAX"
+	envJitter     = "JITTER"
+
+	envFastInterval = "FAST_INTERVAL"
+	envFastDuration = "FAST_DURATION"
+)
+
+const (
+	backoffConstant    = "constant"    // Waits the interval between all attempts.
+	backoffExponential = "exponential" // Doubles the wait after each failed attempt.
+)
+
+// backoff computes the wait between attempts.
+type backoff struct {
+	interval    time.Duration // The initial wait.
+	max         time.Duration // The maximum wait, 0 means unlimited.
+	exponential bool          // Whether the wait doubles after each failed attempt.
+	next        time.Duration // The wait after the next failed attempt.
+	jitter      time.Duration // The maximum random deviation of each wait.
+	jitterRatio float64       // The maximum random deviation of each wait relative to the wait.
+
+	fastInterval time.Duration // The wait during the fast phase.
+	fastUntil    time.Time     // When the fast phase ends.
+}
+
+// newBackoff returns the backoff of the configuration.
+// The fast phase of FAST_DURATION starts now.
+func newBackoff(cfg Config) *backoff {
+	b := &backoff{
+		interval:    cfg.Interval,
+		max:         cfg.BackoffMax,
+		exponential: cfg.Backoff == backoffExponential,
+		next:        cfg.Interval,
+		jitter:      cfg.Jitter,
+		jitterRatio: cfg.JitterRatio,
+	}
+	if cfg.FastDuration > 0 {
+		b.fastInterval = cfg.FastInterval
+		b.fastUntil = time.Now().Add(cfg.FastDuration)
+	}
+	return b
+}
+
+// wait returns how long to wait after an attempt, randomized by the jitter.
+// Failed attempts grow the wait exponentially up to the maximum, a successful attempt resets it.
+func (b *backoff) wait(failed bool) time.Duration {
+	return b.randomize(b.delay(failed))
+}
+
+// delay returns how long to wait after an attempt without jitter.
+// During the fast phase, the fast interval is used and the exponential backoff only starts afterwards.
+func (b *backoff) delay(failed bool) time.Duration {
+	if time.Now().Before(b.fastUntil) {
+		return b.fastInterval
+	}
+
+	if !b.exponential {
+		return b.interval
+	}
+
+	if !failed {
+		b.next = b.interval
+		return b.interval
+	}
+
+	wait := b.next
+	b.next *= 2
+	if b.max > 0 && b.next > b.max {
+		b.next = b.max
+	}
+	return wait
+}
+
+// randomize shifts the wait by a random deviation of up to the jitter in either direction,
+// so many instances started at once do not check the same target in lockstep.
+func (b *backoff) randomize(wait time.Duration) time.Duration {
+	jitter := b.jitter
+	if b.jitterRatio > 0 {
+		jitter = time.Duration(float64(wait) * b.jitterRatio)
+	}
+	if jitter <= 0 {
+		return wait
+	}
+
+	wait += time.Duration(rand.Int64N(int64(2*jitter)+1)) - jitter
+	return max(wait, 0)
+}
+
+// parseJitter parses a jitter either as a duration like '500ms' or as a percentage of the wait like '20%'.
+func parseJitter(s string) (time.Duration, float64, error) {
+	if percentStr, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.ParseFloat(percentStr, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		if percent < 0 || percent > 100 {
+			return 0, 0, errors.New("percentage must be between 0% and 100%")
+		}
+		return 0, percent / 100, nil
+	}
+
+	jitter, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	if jitter < 0 {
+		return 0, 0, errors.New("jitter cannot be negative")
+	}
+	return jitter, 0, nil
+}
+
+// validateBackoff checks if the backoff settings are valid.
+func validateBackoff(cfg *Config) error {
+	if cfg.Backoff == "" {
+		cfg.Backoff = backoffConstant
+	}
+
+	if cfg.Backoff != backoffConstant && cfg.Backoff != backoffExponential {
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envBackoff, backoffConstant, backoffExponential)
+	}
+
+	if cfg.BackoffMax != 0 && cfg.Backoff != backoffExponential {
+		return fmt.Errorf("%s requires %s=%s", envBackoffMax, envBackoff, backoffExponential)
+	}
+
+	if cfg.BackoffMax < 0 {
+		return fmt.Errorf("invalid %s value: maximum cannot be negative", envBackoffMax)
+	}
+
+	if cfg.BackoffMax > 0 && cfg.BackoffMax < cfg.Interval {
+		return fmt.Errorf("invalid %s value: maximum cannot be lower than %s", envBackoffMax, envInterval)
+	}
+
+	if cfg.FastInterval < 0 {
+		return fmt.Errorf("invalid %s value: interval cannot be negative", envFastInterval)
+	}
+
+	if cfg.FastDuration < 0 {
+		return fmt.Errorf("invalid %s value: duration cannot be negative", envFastDuration)
+	}
+
+	if (cfg.FastInterval > 0) != (cfg.FastDuration > 0) {
+		return fmt.Errorf("%s and %s must be set together", envFastInterval, envFastDuration)
+	}
+
+	return nil
+}
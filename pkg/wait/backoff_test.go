@@ -0,0 +1,181 @@
+package wait
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("Constant", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff(Config{Interval: time.Second, Backoff: backoffConstant})
+		for i := 0; i < 3; i++ {
+			if wait := b.wait(true); wait != time.Second {
+				t.Errorf("Expected wait %s but got %s", time.Second, wait)
+			}
+		}
+	})
+
+	t.Run("Exponential up to max", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff(Config{Interval: time.Second, Backoff: backoffExponential, BackoffMax: 5 * time.Second})
+
+		expected := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+		for i, want := range expected {
+			if wait := b.wait(true); wait != want {
+				t.Errorf("Expected wait %s after failure %d but got %s", want, i+1, wait)
+			}
+		}
+	})
+
+	t.Run("Reset after success", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff(Config{Interval: time.Second, Backoff: backoffExponential})
+		b.wait(true)
+		b.wait(true)
+
+		if wait := b.wait(false); wait != time.Second {
+			t.Errorf("Expected wait %s after success but got %s", time.Second, wait)
+		}
+		if wait := b.wait(true); wait != time.Second {
+			t.Errorf("Expected wait %s after reset but got %s", time.Second, wait)
+		}
+	})
+
+	t.Run("Fast interval then interval", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff(Config{Interval: time.Second, FastInterval: 100 * time.Millisecond, FastDuration: time.Minute})
+		if wait := b.wait(true); wait != 100*time.Millisecond {
+			t.Errorf("Expected wait %s during the fast phase but got %s", 100*time.Millisecond, wait)
+		}
+
+		b.fastUntil = time.Now().Add(-time.Second)
+		if wait := b.wait(true); wait != time.Second {
+			t.Errorf("Expected wait %s after the fast phase but got %s", time.Second, wait)
+		}
+	})
+
+	t.Run("Jitter within bounds", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			cfg      Config
+			min, max time.Duration
+		}{
+			{
+				name: "Duration",
+				cfg:  Config{Interval: 2 * time.Second, Jitter: 500 * time.Millisecond},
+				min:  1500 * time.Millisecond,
+				max:  2500 * time.Millisecond,
+			},
+			{
+				name: "Percentage",
+				cfg:  Config{Interval: 2 * time.Second, JitterRatio: 0.1},
+				min:  1800 * time.Millisecond,
+				max:  2200 * time.Millisecond,
+			},
+			{
+				name: "Larger than interval",
+				cfg:  Config{Interval: time.Second, Jitter: 5 * time.Second},
+				min:  0,
+				max:  6 * time.Second,
+			},
+		}
+
+		for _, tt := range tests {
+			b := newBackoff(tt.cfg)
+
+			varied := false
+			for i := 0; i < 1000; i++ {
+				wait := b.wait(true)
+				if wait < tt.min || wait > tt.max {
+					t.Fatalf("%s: expected wait between %s and %s but got %s", tt.name, tt.min, tt.max, wait)
+				}
+				varied = varied || wait != tt.cfg.Interval
+			}
+			if !varied {
+				t.Errorf("%s: expected the wait to vary", tt.name)
+			}
+		}
+	})
+}
+
+func TestParseJitter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		jitter time.Duration
+		ratio  float64
+		err    string
+	}{
+		{name: "Duration", input: "500ms", jitter: 500 * time.Millisecond},
+		{name: "Percentage", input: "20%", ratio: 0.2},
+		{name: "Percentage above 100", input: "150%", err: "percentage must be between 0% and 100%"},
+		{name: "Negative duration", input: "-1s", err: "jitter cannot be negative"},
+		{name: "Invalid", input: "some", err: `time: invalid duration "some"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			jitter, ratio, err := parseJitter(tt.input)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if jitter != tt.jitter || ratio != tt.ratio {
+				t.Errorf("Expected jitter %s and ratio %v but got %s and %v", tt.jitter, tt.ratio, jitter, ratio)
+			}
+		})
+	}
+}
+
+func TestValidateBackoff(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Default", cfg: Config{Interval: time.Second}},
+		{name: "Exponential", cfg: Config{Interval: time.Second, Backoff: backoffExponential, BackoffMax: time.Minute}},
+		{name: "Unsupported backoff", cfg: Config{Interval: time.Second, Backoff: "linear"}, err: "invalid BACKOFF value: must be one of constant, exponential"},
+		{name: "Negative max", cfg: Config{Interval: time.Second, Backoff: backoffExponential, BackoffMax: -time.Second}, err: "invalid BACKOFF_MAX value: maximum cannot be negative"},
+		{name: "Max below interval", cfg: Config{Interval: time.Minute, Backoff: backoffExponential, BackoffMax: time.Second}, err: "invalid BACKOFF_MAX value: maximum cannot be lower than INTERVAL"},
+		{name: "Max without exponential backoff", cfg: Config{Interval: time.Second, BackoffMax: time.Minute}, err: "BACKOFF_MAX requires BACKOFF=exponential"},
+		{name: "Fast interval", cfg: Config{Interval: time.Second, FastInterval: 100 * time.Millisecond, FastDuration: time.Minute}},
+		{name: "Negative fast interval", cfg: Config{Interval: time.Second, FastInterval: -time.Second, FastDuration: time.Minute}, err: "invalid FAST_INTERVAL value: interval cannot be negative"},
+		{name: "Negative fast duration", cfg: Config{Interval: time.Second, FastInterval: time.Second, FastDuration: -time.Minute}, err: "invalid FAST_DURATION value: duration cannot be negative"},
+		{name: "Fast interval without duration", cfg: Config{Interval: time.Second, FastInterval: time.Second}, err: "FAST_INTERVAL and FAST_DURATION must be set together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateBackoff(&tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
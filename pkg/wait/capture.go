@@ -0,0 +1,105 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const envCaptureResponseFile = "CAPTURE_RESPONSE_FILE"
+
+// maxCaptureSize bounds how much of a response is captured.
+const maxCaptureSize = 64 << 10
+
+// captureFunc stores the response of a successful check.
+type captureFunc func(data []byte) error
+
+// newCapture returns the capture writing to the configured file, or nil if capturing is disabled.
+// A failed write aborts waiting, since it will not resolve by retrying.
+func newCapture(cfg Config) captureFunc {
+	if cfg.CaptureResponseFile == "" {
+		return nil
+	}
+
+	return func(data []byte) error {
+		if err := os.WriteFile(cfg.CaptureResponseFile, truncate(data, maxCaptureSize), 0o644); err != nil {
+			return &abortError{err: fmt.Errorf("failed to capture response: %w", err)}
+		}
+		return nil
+	}
+}
+
+// readBanner connects to the given address, sends the payload (if any) and returns the first data the target sends.
+// A target sending nothing before the timeout expires has an empty banner.
+func readBanner(ctx context.Context, dialer Dialer, address string, send []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	stop, err := bindConnDeadline(ctx, conn, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	if len(send) > 0 {
+		if _, err := conn.Write(send); err != nil {
+			return nil, fmt.Errorf("failed to send probe: %w", err)
+		}
+	}
+
+	buf := make([]byte, maxCaptureSize)
+	n, err := conn.Read(buf)
+	if err != nil && n == 0 && !isTimeout(err) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read banner: %w", err)
+	}
+
+	return buf[:n], nil
+}
+
+// describeTLS returns the negotiated TLS parameters and the details of the leaf certificate.
+func describeTLS(state tls.ConnectionState) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "version: %s\n", tls.VersionName(state.Version))
+	fmt.Fprintf(&b, "cipher_suite: %s\n", tls.CipherSuiteName(state.CipherSuite))
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		fmt.Fprintf(&b, "subject: %s\n", cert.Subject)
+		fmt.Fprintf(&b, "issuer: %s\n", cert.Issuer)
+		fmt.Fprintf(&b, "dns_names: %s\n", strings.Join(cert.DNSNames, ", "))
+		fmt.Fprintf(&b, "serial_number: %s\n", cert.SerialNumber)
+		fmt.Fprintf(&b, "not_before: %s\n", cert.NotBefore.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&b, "not_after: %s\n", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return []byte(b.String())
+}
+
+// validateCaptureResponse checks if capturing the response is supported by the configuration.
+func validateCaptureResponse(cfg Config) error {
+	if cfg.CaptureResponseFile == "" {
+		return nil
+	}
+
+	if len(cfg.Targets) > 0 {
+		return fmt.Errorf("%s cannot be used with multiple targets", envCaptureResponseFile)
+	}
+
+	if cfg.CheckType == checkTypeS3 {
+		return fmt.Errorf("%s is not supported for check type %q", envCaptureResponseFile, checkTypeS3)
+	}
+
+	if cfg.ReverseCheckListen != "" {
+		return fmt.Errorf("%s cannot be used with %s", envCaptureResponseFile, envReverseCheckListen)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureResponse(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("HTTP body", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		file := filepath.Join(t.TempDir(), "response")
+		cfg := Config{TargetAddress: server.URL, DialTimeout: time.Second, CheckType: checkTypeHTTP, CaptureResponseFile: file}
+
+		if err := newCheck(cfg, &net.Dialer{}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if data, _ := os.ReadFile(file); string(data) != `{"status":"ok"}` {
+			t.Errorf("Expected captured response %q but got %q", `{"status":"ok"}`, data)
+		}
+	})
+
+	t.Run("TCP banner", func(t *testing.T) {
+		t.Parallel()
+
+		address := newEchoServer(t, "+PONG\r\n")
+
+		file := filepath.Join(t.TempDir(), "response")
+		cfg := Config{TargetAddress: address, DialTimeout: time.Second, CheckType: checkTypeTCP, ProbeSend: []byte("PING\r\n"), CaptureResponseFile: file}
+
+		if err := newCheck(cfg, &net.Dialer{}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if data, _ := os.ReadFile(file); string(data) != "+PONG\r\n" {
+			t.Errorf("Expected captured response %q but got %q", "+PONG\r\n", data)
+		}
+	})
+
+	t.Run("Silent TCP target", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "response")
+		cfg := Config{TargetAddress: newListener(t).Addr().String(), DialTimeout: 50 * time.Millisecond, CheckType: checkTypeTCP, CaptureResponseFile: file}
+
+		if err := newCheck(cfg, &net.Dialer{}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if data, err := os.ReadFile(file); err != nil || len(data) != 0 {
+			t.Errorf("Expected empty captured response but got %q (%v)", data, err)
+		}
+	})
+
+	t.Run("TLS details", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, tls.VersionTLS13)
+
+		file := filepath.Join(t.TempDir(), "response")
+		capture := newCapture(Config{CaptureResponseFile: file})
+
+		state, err := handshakeTLS(context.Background(), &net.Dialer{}, address, &tls.Config{RootCAs: pool}, handshakeRetrier{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := capture(describeTLS(state)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(file)
+		for _, field := range []string{"version: TLS 1.3\n", "subject: O=Acme Co\n", "not_after: "} {
+			if !strings.Contains(string(data), field) {
+				t.Errorf("Expected captured details to contain %q but got %q", field, data)
+			}
+		}
+	})
+
+	t.Run("Failed write aborts", func(t *testing.T) {
+		t.Parallel()
+
+		capture := newCapture(Config{CaptureResponseFile: filepath.Join(t.TempDir(), "missing", "response")})
+
+		var abortErr *abortError
+		if err := capture([]byte("data")); !errors.As(err, &abortErr) {
+			t.Errorf("Expected abort error but got %v", err)
+		}
+	})
+}
+
+func TestValidateCaptureResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{CheckType: checkTypeS3}},
+		{name: "Single target", cfg: Config{CheckType: checkTypeTCP, CaptureResponseFile: "response"}},
+		{name: "Multiple targets", cfg: Config{CheckType: checkTypeTCP, CaptureResponseFile: "response", Targets: []Target{{Address: "db:5432"}}}, err: "CAPTURE_RESPONSE_FILE cannot be used with multiple targets"},
+		{name: "S3 check", cfg: Config{CheckType: checkTypeS3, CaptureResponseFile: "response"}, err: `CAPTURE_RESPONSE_FILE is not supported for check type "s3"`},
+		{name: "Reverse check", cfg: Config{CheckType: checkTypeTCP, CaptureResponseFile: "response", ReverseCheckListen: "127.0.0.1:0"}, err: "CAPTURE_RESPONSE_FILE cannot be used with REVERSE_CHECK_LISTEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateCaptureResponse(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package wait
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	envExpectCertChange        = "EXPECT_CERT_CHANGE"
+	envExpectedCertFingerprint = "EXPECTED_CERT_FINGERPRINT"
+)
+
+// certWatcher waits for the certificate presented by the target to be rotated.
+type certWatcher struct {
+	mu       sync.Mutex
+	name     string
+	change   bool   // Whether the certificate must differ from the first one seen.
+	expected string // The fingerprint the certificate must have, if set.
+	initial  string // The fingerprint of the first certificate seen.
+	logger   *slog.Logger
+}
+
+// newCertWatcher returns a watcher for the rotation settings, or nil if none are set.
+func newCertWatcher(cfg Config, logger *slog.Logger) *certWatcher {
+	if !cfg.ExpectCertChange && cfg.ExpectedCertFingerprint == "" {
+		return nil
+	}
+	return &certWatcher{
+		name:     cfg.TargetName,
+		change:   cfg.ExpectCertChange,
+		expected: cfg.ExpectedCertFingerprint,
+		logger:   logger,
+	}
+}
+
+// observe checks the certificate of a successful handshake.
+// It returns an error until the certificate was rotated.
+func (w *certWatcher) observe(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("target presented no certificate")
+	}
+	fingerprint := certFingerprint(state.PeerCertificates[0].Raw)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.initial == "" {
+		w.initial = fingerprint
+		w.logger.Info(fmt.Sprintf("%s presents certificate %s", w.name, fingerprint), slog.String("fingerprint", fingerprint))
+		if w.change {
+			return fmt.Errorf("waiting for certificate %s to change", fingerprint)
+		}
+	}
+
+	if w.change && fingerprint == w.initial {
+		return fmt.Errorf("certificate %s did not change yet", fingerprint)
+	}
+
+	if w.expected != "" && fingerprint != w.expected {
+		return fmt.Errorf("certificate %s does not match %s %s", fingerprint, envExpectedCertFingerprint, w.expected)
+	}
+
+	if fingerprint != w.initial {
+		w.logger.Info(fmt.Sprintf("%s certificate changed", w.name),
+			slog.String("old_fingerprint", w.initial),
+			slog.String("new_fingerprint", fingerprint),
+		)
+	}
+	return nil
+}
+
+// certFingerprint returns the SHA-256 fingerprint of a DER encoded certificate as lowercase hex.
+func certFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseCertFingerprint parses a SHA-256 fingerprint in hex, optionally separated by colons as printed by openssl.
+func parseCertFingerprint(s string) (string, error) {
+	fingerprint := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+
+	decoded, err := hex.DecodeString(fingerprint)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("must be a SHA-256 fingerprint of 64 hex digits")
+	}
+
+	return fingerprint, nil
+}
+
+// validateCertChange checks if waiting for a certificate rotation is supported with the given configuration.
+func validateCertChange(cfg Config) error {
+	if !cfg.ExpectCertChange && cfg.ExpectedCertFingerprint == "" {
+		return nil
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType != checkTypeTLS {
+			return fmt.Errorf("%s and %s can only be used with check type %q", envExpectCertChange, envExpectedCertFingerprint, checkTypeTLS)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package wait
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// connectionState returns a connection state presenting a certificate with the given DER encoding.
+func connectionState(der string) tls.ConnectionState {
+	return tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Raw: []byte(der)}}}
+}
+
+func TestCertWatcher(t *testing.T) {
+	oldFingerprint := certFingerprint([]byte("old"))
+	newFingerprint := certFingerprint([]byte("new"))
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		if watcher := newCertWatcher(Config{}, nil); watcher != nil {
+			t.Errorf("Expected no watcher but got %v", watcher)
+		}
+	})
+
+	t.Run("Wait for change", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		watcher := newCertWatcher(Config{TargetName: "api", ExpectCertChange: true}, slog.New(slog.NewTextHandler(&output, nil)))
+
+		for range 2 {
+			if err := watcher.observe(connectionState("old")); err == nil {
+				t.Fatal("Expected error before the certificate changed but got nil")
+			}
+		}
+
+		if err := watcher.observe(connectionState("new")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "old_fingerprint=" + oldFingerprint + " new_fingerprint=" + newFingerprint
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+
+	t.Run("Wait for expected fingerprint", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "api", ExpectedCertFingerprint: newFingerprint}
+		watcher := newCertWatcher(cfg, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+		err := watcher.observe(connectionState("old"))
+		expected := "certificate " + oldFingerprint + " does not match EXPECTED_CERT_FINGERPRINT " + newFingerprint
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+
+		if err := watcher.observe(connectionState("new")); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Expected fingerprint already presented", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "api", ExpectedCertFingerprint: oldFingerprint}
+		watcher := newCertWatcher(cfg, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+		if err := watcher.observe(connectionState("old")); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Change to expected fingerprint", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "api", ExpectCertChange: true, ExpectedCertFingerprint: newFingerprint}
+		watcher := newCertWatcher(cfg, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+		for _, der := range []string{"old", "other"} {
+			if err := watcher.observe(connectionState(der)); err == nil {
+				t.Fatalf("Expected error for certificate %q but got nil", der)
+			}
+		}
+
+		if err := watcher.observe(connectionState("new")); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestParseCertFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := certFingerprint([]byte("cert"))
+
+	tests := []struct {
+		name  string
+		input string
+		err   bool
+	}{
+		{name: "Lowercase hex", input: fingerprint},
+		{name: "OpenSSL format", input: strings.ToUpper(colonSeparated(fingerprint))},
+		{name: "Too short", input: fingerprint[:62], err: true},
+		{name: "Not hex", input: strings.Repeat("z", 64), err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parseCertFingerprint(tt.input)
+			if tt.err {
+				expected := "must be a SHA-256 fingerprint of 64 hex digits"
+				if err == nil || err.Error() != expected {
+					t.Errorf("Expected error %q but got %v", expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if parsed != fingerprint {
+				t.Errorf("Expected fingerprint %q but got %q", fingerprint, parsed)
+			}
+		})
+	}
+}
+
+// colonSeparated separates each byte of a hex string with a colon.
+func colonSeparated(s string) string {
+	var pairs []string
+	for i := 0; i < len(s); i += 2 {
+		pairs = append(pairs, s[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
+
+func TestValidateCertChange(t *testing.T) {
+	t.Parallel()
+
+	err := validateCertChange(Config{CheckType: checkTypeTCP, ExpectCertChange: true})
+
+	expected := `EXPECT_CERT_CHANGE and EXPECTED_CERT_FINGERPRINT can only be used with check type "tls"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
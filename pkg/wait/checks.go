@@ -0,0 +1,317 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	checkTypeTCP  = "tcp"  // Checks if a TCP connection can be established.
+	checkTypeTLS  = "tls"  // Checks if a TLS handshake succeeds.
+	checkTypeS3   = "s3"   // Checks if a bucket of an S3-compatible endpoint is accessible.
+	checkTypeHTTP = "http" // Checks if a HTTP endpoint responds successfully.
+	checkTypeFD   = "fd"   // Checks if a local process has opened enough file descriptors.
+	checkTypeGRPC = "grpc" // Checks if a gRPC server reports the service as serving.
+	checkTypeExec = "exec" // Checks if a custom command exits successfully.
+	checkTypeDNS  = "dns"  // Checks if the target host resolves to at least one address.
+	checkTypeSRV  = "srv"  // Checks if a TCP connection can be established to the endpoint of an SRV record.
+)
+
+func init() {
+	registerCheck(checkTypeTCP, newTCPCheck)
+	registerCheck(checkTypeTLS, newTLSCheck)
+}
+
+const (
+	envTLSServerName         = "TLS_SERVER_NAME"
+	envTLSInsecureSkipVerify = "TLS_INSECURE_SKIP_VERIFY"
+)
+
+// tlsVersions maps the supported TLS_MIN_VERSION values to their TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// checkFunc performs a single readiness check against a target.
+type checkFunc func(ctx context.Context) error
+
+// checkBuilder returns the check of a check type for a target, connecting to it with the dialer.
+// capture is nil unless the response of a successful check is captured.
+type checkBuilder func(cfg Config, dialer Dialer, logger *slog.Logger, capture captureFunc) checkFunc
+
+var (
+	checkRegistryMu sync.RWMutex
+	checkRegistry   = make(map[string]checkBuilder)
+)
+
+// registerCheck makes the check type available for CHECK_TYPE, replacing any builder registered for it before.
+// Every check type registers itself from the file implementing it.
+func registerCheck(checkType string, build checkBuilder) {
+	checkRegistryMu.Lock()
+	defer checkRegistryMu.Unlock()
+
+	checkRegistry[checkType] = build
+}
+
+// lookupCheck returns the builder registered for the check type.
+func lookupCheck(checkType string) (checkBuilder, bool) {
+	checkRegistryMu.RLock()
+	defer checkRegistryMu.RUnlock()
+
+	build, ok := checkRegistry[checkType]
+	return build, ok
+}
+
+// validateCheckType checks if the given check type is registered.
+func validateCheckType(envName, checkType string) error {
+	if _, ok := lookupCheck(checkType); !ok {
+		return fmt.Errorf("invalid %s value: unsupported check type %q", envName, checkType)
+	}
+	return nil
+}
+
+// isURLCheckType reports whether the check type expects the target address to be a URL.
+func isURLCheckType(checkType string) bool {
+	return checkType == checkTypeS3 || checkType == checkTypeHTTP
+}
+
+// usesCheckType reports whether any target of the configuration uses the given check type.
+func usesCheckType(cfg Config, checkType string) bool {
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType == checkType {
+			return true
+		}
+	}
+	return false
+}
+
+// newTLSConfig returns the TLS configuration for TLS checks and HTTPS requests.
+func newTLSConfig(cfg Config) *tls.Config {
+	tlsConfig := &tls.Config{
+		MinVersion:         cfg.TLSMinVersion,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+	if cfg.ClockSkew > 0 && !cfg.TLSInsecureSkipVerify {
+		withClockSkew(tlsConfig, cfg.ClockSkew, tlsHost(cfg))
+	}
+	return tlsConfig
+}
+
+// parseTLSVersion parses a TLS version like '1.2' into its protocol version.
+func parseTLSVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q, must be one of 1.0, 1.1, 1.2, 1.3", version)
+	}
+	return v, nil
+}
+
+// newTargetCheck returns the check for the target, dialing with the configured dial timeout.
+// With PROXY_ADDRESS set, the target is dialed through the SOCKS5 proxy.
+// With HTTP_PROXY_ADDRESS set, the target is dialed through a CONNECT tunnel of the HTTP proxy.
+// With SEND_PROXY_HEADER set, a PROXY protocol header is sent on every new connection.
+// With CLOSE_RESET set, every new connection is closed with a RST instead of a FIN.
+// With LOG_EXTRA_FIELDS set, the local address of every new connection is recorded for the attempt log.
+// With TARGET_ADDRESS_FILE set, the address is read from the file before each attempt.
+// Every attempt is bounded by ATTEMPT_TIMEOUT from when the check runs, so waiting for a MAX_CONCURRENCY slot does not count.
+func newTargetCheck(cfg Config, logger *slog.Logger) checkFunc {
+	if cfg.TargetAddressFile != "" {
+		return newAddressFileCheck(cfg, logger)
+	}
+
+	netDialer := &net.Dialer{
+		Timeout: cfg.DialTimeout,
+	}
+
+	var dialer Dialer = netDialer
+	if cfg.SendProxyHeader {
+		dialer = &proxyHeaderDialer{forward: netDialer}
+	} else if cfg.ProxyAddress != "" {
+		dialer = newSOCKS5Dialer(cfg.ProxyAddress, netDialer)
+	} else if cfg.HTTPProxyAddress != "" {
+		dialer = newHTTPConnectDialer(cfg.HTTPProxyAddress, netDialer)
+	}
+	if cfg.CloseReset {
+		dialer = &closeResetDialer{forward: dialer}
+	}
+	if cfg.LogExtraFields {
+		dialer = &localAddrDialer{forward: dialer}
+	}
+	return withAttemptTimeout(cfg.AttemptTimeout, newCheck(cfg, dialer, logger))
+}
+
+// newCheck returns the check matching the configured check type.
+// With expected IPs configured, the target host is resolved and verified before each check.
+// With LOG_EXTRA_FIELDS set, the addresses of the target host are logged at debug level on each attempt.
+// With LOG_CNAME_CHAIN set, the CNAME chain of the target host is logged once it was resolved.
+func newCheck(cfg Config, dialer Dialer, logger *slog.Logger) checkFunc {
+	check := newTypedCheck(cfg, dialer, logger)
+
+	if len(cfg.ExpectedIPs) > 0 {
+		typedCheck := check
+		host := targetHost(cfg)
+		check = func(ctx context.Context) error {
+			if err := verifyResolvedIPs(ctx, net.DefaultResolver, host, cfg.ExpectedIPs); err != nil {
+				return err
+			}
+			return typedCheck(ctx)
+		}
+	}
+
+	// behind a proxy, the target host is resolved by the proxy, and DNS and SRV checks log the addresses themselves
+	if cfg.LogExtraFields && cfg.ProxyAddress == "" && cfg.HTTPProxyAddress == "" && cfg.CheckType != checkTypeFD && cfg.CheckType != checkTypeExec && cfg.CheckType != checkTypeDNS && cfg.CheckType != checkTypeSRV {
+		check = logResolvedIPs(cfg, logger, net.DefaultResolver, check)
+	}
+
+	if cfg.LogCNAMEChain {
+		conf := readResolvConf("/etc/resolv.conf")
+		check = logCNAMEChain(cfg, logger, conf, newDNSCNAMELookup(dialer, conf.server), check)
+	}
+
+	return check
+}
+
+// newTypedCheck returns the check registered for the configured check type.
+// With CAPTURE_RESPONSE_FILE set, the response of a successful check is captured.
+func newTypedCheck(cfg Config, dialer Dialer, logger *slog.Logger) checkFunc {
+	build, ok := lookupCheck(cfg.CheckType)
+	if !ok {
+		build = newTCPCheck // validated configurations only use registered check types
+	}
+	return build(cfg, dialer, logger, newCapture(cfg))
+}
+
+// newTCPCheck returns the check for tcp targets, connecting to the target
+// or running the probe, reverse check or connection variant configured for it.
+func newTCPCheck(cfg Config, dialer Dialer, logger *slog.Logger, capture captureFunc) checkFunc {
+	if cfg.Protocol == protocolUDP {
+		return newUDPCheck(cfg, dialer, capture)
+	}
+	if cfg.ConcurrentConns > 0 {
+		return newConcurrentConnectionsCheck(cfg, dialer, logger)
+	}
+	if cfg.ReverseCheckListen != "" {
+		return func(ctx context.Context) error {
+			return checkReverse(ctx, dialer, cfg)
+		}
+	}
+	if capture != nil {
+		return func(ctx context.Context) error {
+			var response []byte
+			var err error
+			if len(cfg.ProbeExpect) > 0 {
+				response, err = probe(ctx, dialer, cfg.TargetAddress, cfg.ProbeSend, cfg.ProbeExpect, cfg.ProbeFirstLine, cfg.DialTimeout)
+			} else {
+				response, err = readBanner(ctx, dialer, cfg.TargetAddress, cfg.ProbeSend, cfg.DialTimeout)
+			}
+			if err != nil {
+				return err
+			}
+			return capture(response)
+		}
+	}
+	if len(cfg.ProbeSend) > 0 || len(cfg.ProbeExpect) > 0 {
+		return func(ctx context.Context) error {
+			return checkProbe(ctx, dialer, cfg.TargetAddress, cfg.ProbeSend, cfg.ProbeExpect, cfg.ProbeFirstLine, cfg.DialTimeout)
+		}
+	}
+	if cfg.LogTCPMSS {
+		return newTCPMSSCheck(cfg, dialer, logger)
+	}
+	if cfg.RequireDualStack {
+		return newDualStackCheck(cfg, dialer, logger)
+	}
+	if cfg.VerifyWritable {
+		return newVerifyWritableCheck(cfg, dialer)
+	}
+	return func(ctx context.Context) error {
+		return CheckConnection(ctx, dialer, cfg.TargetAddress)
+	}
+}
+
+// newTLSCheck returns the check for tls targets.
+// With EXPECT_CERT_CHANGE or EXPECTED_CERT_FINGERPRINT set, the certificate of every handshake is observed.
+func newTLSCheck(cfg Config, dialer Dialer, logger *slog.Logger, capture captureFunc) checkFunc {
+	tlsConfig := newTLSConfig(cfg)
+	retrier := newHandshakeRetrier(cfg, logger)
+	watcher := newCertWatcher(cfg, logger)
+	if capture == nil && watcher == nil {
+		return func(ctx context.Context) error {
+			return checkTLS(ctx, dialer, cfg.TargetAddress, tlsConfig, retrier)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		state, err := handshakeTLS(ctx, dialer, cfg.TargetAddress, tlsConfig, retrier)
+		if err != nil {
+			return err
+		}
+		if watcher != nil {
+			if err := watcher.observe(state); err != nil {
+				return err
+			}
+		}
+		if capture == nil {
+			return nil
+		}
+		return capture(describeTLS(state))
+	}
+}
+
+// newHTTPClient returns a HTTP client dialing with the given dialer.
+// Besides dialing, the TLS handshake and waiting for the response headers are bound by the dial timeout.
+// With handshake retries, HTTPS connections retry failed TLS handshakes within a single attempt.
+func newHTTPClient(cfg Config, dialer Dialer, retrier handshakeRetrier) *http.Client {
+	tlsConfig := newTLSConfig(cfg)
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.DialTimeout,
+		ResponseHeaderTimeout: cfg.DialTimeout,
+		DisableKeepAlives:     true,
+	}
+	if retrier.retries > 0 {
+		transport.DialTLSContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialTLS(ctx, dialer, network, address, tlsConfig, retrier)
+		}
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// checkTLS tries to establish a connection to the given address and complete a TLS handshake.
+// Failed handshakes are retried as configured by the retrier.
+func checkTLS(ctx context.Context, dialer Dialer, address string, tlsConfig *tls.Config, retrier handshakeRetrier) error {
+	_, err := handshakeTLS(ctx, dialer, address, tlsConfig, retrier)
+	return err
+}
+
+// handshakeTLS performs the handshake like checkTLS and returns the state of the connection.
+func handshakeTLS(ctx context.Context, dialer Dialer, address string, tlsConfig *tls.Config, retrier handshakeRetrier) (tls.ConnectionState, error) {
+	conn, err := dialTLS(ctx, dialer, "tcp", address, tlsConfig, retrier)
+	if err != nil {
+		if tlsConfig.MinVersion != 0 && isTLSVersionError(err) {
+			return tls.ConnectionState{}, fmt.Errorf("required at least %s: %w", tls.VersionName(tlsConfig.MinVersion), err)
+		}
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState(), nil
+}
+
+// isTLSVersionError reports whether a handshake failed because the peers could not agree on a protocol version.
+func isTLSVersionError(err error) bool {
+	return strings.Contains(err.Error(), "protocol version")
+}
@@ -0,0 +1,206 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTLSServer starts a TLS server and returns its address and a pool trusting its certificate.
+func newTLSServer(t *testing.T, maxVersion uint16) (string, *x509.CertPool) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{MaxVersion: maxVersion}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	return server.Listener.Addr().String(), pool
+}
+
+func TestRegisterCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Cleanup(func() {
+		checkRegistryMu.Lock()
+		defer checkRegistryMu.Unlock()
+		delete(checkRegistry, "fake")
+	})
+
+	// the fake target becomes ready on the third round
+	var built, rounds int
+	registerCheck("fake", func(cfg Config, dialer Dialer, logger *slog.Logger, capture captureFunc) checkFunc {
+		built++
+		return func(ctx context.Context) error {
+			rounds++
+			if rounds < 3 {
+				return errors.New("not started yet")
+			}
+			return nil
+		}
+	})
+
+	cfg := Config{TargetAddress: "fake:1", CheckType: "fake", Interval: 10 * time.Millisecond}
+	if err := ValidateConfig(&cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pollTarget(context.Background(), cfg, logger, newTargetCheck(cfg, logger)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if built != 1 || rounds != 3 {
+		t.Errorf("Expected the check to be built once and called 3 times but it was built %d times and called %d times", built, rounds)
+	}
+}
+
+func TestValidateCheckType(t *testing.T) {
+	t.Parallel()
+
+	for _, checkType := range []string{checkTypeTCP, checkTypeTLS, checkTypeS3, checkTypeHTTP, checkTypeFD, checkTypeGRPC, checkTypeExec, checkTypeDNS, checkTypeSRV} {
+		if err := validateCheckType(envCheckType, checkType); err != nil {
+			t.Errorf("Expected check type %q to be registered but got %v", checkType, err)
+		}
+	}
+
+	expected := `invalid CHECK_TYPE value: unsupported check type "smtp"`
+	if err := validateCheckType(envCheckType, "smtp"); err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	t.Run("Valid TLS_MIN_VERSION", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := ParseConfig(func(key string) string {
+			if key == "TLS_MIN_VERSION" {
+				return "1.3"
+			}
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.TLSMinVersion != tls.VersionTLS13 {
+			t.Errorf("Expected TLS version %x but got %x", tls.VersionTLS13, cfg.TLSMinVersion)
+		}
+	})
+
+	t.Run("Invalid TLS_MIN_VERSION", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseConfig(func(key string) string {
+			if key == "TLS_MIN_VERSION" {
+				return "1.4"
+			}
+			return ""
+		})
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid TLS_MIN_VERSION value: unsupported TLS version \"1.4\", must be one of 1.0, 1.1, 1.2, 1.3"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestCheckTLS(t *testing.T) {
+	t.Run("Successful handshake", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, 0)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		if err := checkTLS(context.Background(), dialer, address, &tls.Config{RootCAs: pool}, handshakeRetrier{}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newTLSServer(t, 0)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		if err := checkTLS(context.Background(), dialer, address, &tls.Config{}, handshakeRetrier{}); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+
+	t.Run("TLS version below minimum", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, tls.VersionTLS12)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		err := checkTLS(context.Background(), dialer, address, &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS13}, handshakeRetrier{})
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "required at least TLS 1.3"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Server name", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, 0)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		tlsConfig := newTLSConfig(Config{TLSServerName: "example.com"})
+		tlsConfig.RootCAs = pool
+
+		if err := checkTLS(context.Background(), dialer, address, tlsConfig, handshakeRetrier{}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Server name not in certificate", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newTLSServer(t, 0)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		tlsConfig := newTLSConfig(Config{TLSServerName: "db.example.org"})
+		tlsConfig.RootCAs = pool
+
+		err := checkTLS(context.Background(), dialer, address, tlsConfig, handshakeRetrier{})
+		expected := "certificate is valid for"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newTLSServer(t, 0)
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		tlsConfig := newTLSConfig(Config{TLSInsecureSkipVerify: true, ClockSkew: defaultClockSkew})
+
+		if err := checkTLS(context.Background(), dialer, address, tlsConfig, handshakeRetrier{}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyWithClockSkew(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	cert := server.Certificate()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	state := tls.ConnectionState{ServerName: "example.com", PeerCertificates: []*x509.Certificate{cert}}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		skew     time.Duration
+		host     string
+		ip       string
+		expected string
+	}{
+		{name: "Valid", now: cert.NotBefore.Add(time.Hour), skew: time.Minute},
+		{name: "Not yet valid within skew", now: cert.NotBefore.Add(-time.Minute), skew: 5 * time.Minute},
+		{name: "Expired within skew", now: cert.NotAfter.Add(time.Minute), skew: 5 * time.Minute},
+		{name: "Not yet valid beyond skew", now: cert.NotBefore.Add(-time.Hour), skew: 5 * time.Minute, expected: "certificate has expired or is not yet valid"},
+		{name: "Expired beyond skew", now: cert.NotAfter.Add(time.Hour), skew: 5 * time.Minute, expected: "certificate has expired or is not yet valid"},
+		{name: "Wrong host", now: cert.NotBefore.Add(time.Hour), skew: time.Minute, host: "wrong.example.org", expected: "certificate is valid for"},
+		{name: "IP address", now: cert.NotBefore.Add(time.Hour), skew: time.Minute, ip: "127.0.0.1"},
+		{name: "Wrong IP address", now: cert.NotBefore.Add(time.Hour), skew: time.Minute, ip: "192.0.2.1", expected: "certificate is valid for"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := state
+			if tt.host != "" {
+				state.ServerName = tt.host
+			}
+			if tt.ip != "" {
+				state.ServerName = "" // IP addresses are not sent via SNI
+			}
+
+			err := verifyWithClockSkew(state, roots, tt.ip, tt.now, tt.skew)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q but got %v", tt.expected, err)
+			}
+		})
+	}
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		t.Parallel()
+
+		err := verifyWithClockSkew(state, x509.NewCertPool(), "", cert.NotBefore.Add(time.Hour), time.Minute)
+		expected := "certificate signed by unknown authority"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("No server name", func(t *testing.T) {
+		t.Parallel()
+
+		state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		err := verifyWithClockSkew(state, roots, "", cert.NotBefore.Add(time.Hour), time.Minute)
+		expected := "tls: no server name to verify the certificate against"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestCheckTLSWithClockSkew(t *testing.T) {
+	t.Parallel()
+
+	address, pool := newTLSServer(t, 0)
+	dialer := &net.Dialer{Timeout: time.Second}
+
+	t.Run("IP address", func(t *testing.T) {
+		t.Parallel()
+
+		tlsConfig := newTLSConfig(Config{TargetAddress: address, ClockSkew: time.Minute})
+		tlsConfig.RootCAs = pool
+
+		if err := checkTLS(context.Background(), dialer, address, tlsConfig, handshakeRetrier{}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Certificate not valid for the IP address", func(t *testing.T) {
+		t.Parallel()
+
+		// the certificate of the test server is valid for 127.0.0.1, but not for the configured server name
+		tlsConfig := newTLSConfig(Config{TargetAddress: address, TLSServerName: "192.0.2.1", ClockSkew: time.Minute})
+		tlsConfig.RootCAs = pool
+
+		err := checkTLS(context.Background(), dialer, address, tlsConfig, handshakeRetrier{})
+		expected := "certificate is valid for 127.0.0.1, ::1, not 192.0.2.1"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestTLSHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{name: "Address", cfg: Config{TargetAddress: "127.0.0.1:443", CheckType: checkTypeTLS}, expected: "127.0.0.1"},
+		{name: "URL", cfg: Config{TargetAddress: "https://[::1]:8443/healthz", CheckType: checkTypeHTTP}, expected: "::1"},
+		{name: "Server name", cfg: Config{TargetAddress: "127.0.0.1:443", TLSServerName: "db.example.com"}, expected: "db.example.com"},
+		{name: "Invalid address", cfg: Config{TargetAddress: "localhost"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if host := tlsHost(tt.cfg); host != tt.expected {
+				t.Errorf("Expected host %q but got %q", tt.expected, host)
+			}
+		})
+	}
+}
+
+func TestValidateClockSkew(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(func(key string) string {
+		switch key {
+		case "TARGET_ADDRESS":
+			return "localhost:443"
+		case "CLOCK_SKEW":
+			return "-1m"
+		}
+		return ""
+	})
+	if err == nil {
+		err = ValidateConfig(&cfg)
+	}
+
+	expected := "invalid CLOCK_SKEW value: tolerance cannot be negative"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
@@ -0,0 +1,59 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+const envCloseReset = "CLOSE_RESET"
+
+// closeResetDialer disables lingering on every new TCP connection, so closing it sends a RST instead of a FIN.
+// The socket is released right away instead of staying in TIME_WAIT, which keeps frequent checks from exhausting
+// the local ports of a busy host, at the cost of the target seeing a reset instead of a graceful close.
+type closeResetDialer struct {
+	forward Dialer
+}
+
+// DialContext connects to the address and sets the linger of the connection to zero.
+func (d *closeResetDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if tcpConn, ok := conn.(interface{ SetLinger(sec int) error }); ok {
+		if err := tcpConn.SetLinger(0); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to disable linger: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// validateCloseReset checks if connections can be closed with a reset with the given configuration.
+// Through a proxy, the reset would only reach the proxy and not the target, so proxies are rejected.
+func validateCloseReset(cfg Config) error {
+	if !cfg.CloseReset {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{fmt.Sprintf("%s=%s", envProtocol, protocolUDP), cfg.Protocol == protocolUDP},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeFD), usesCheckType(cfg, checkTypeFD)},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeExec), usesCheckType(cfg, checkTypeExec)},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeDNS), usesCheckType(cfg, checkTypeDNS)},
+		{envProxyAddress, cfg.ProxyAddress != ""},
+		{envHTTPProxyAddress, cfg.HTTPProxyAddress != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envCloseReset, conflict.env)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCloseResetDialer(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialer   Dialer
+		expected error
+	}{
+		{name: "Graceful close", dialer: &net.Dialer{Timeout: time.Second}, expected: io.EOF},
+		{name: "Close with reset", dialer: &closeResetDialer{forward: &net.Dialer{Timeout: time.Second}}, expected: syscall.ECONNRESET},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			closed := make(chan error, 1)
+			address := newConnServer(t, func(conn *net.TCPConn) {
+				defer conn.Close()
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+				_, err := conn.Read(make([]byte, 1))
+				closed <- err
+			})
+
+			if err := CheckConnection(context.Background(), tt.dialer, address); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := <-closed; !errors.Is(err, tt.expected) {
+				t.Errorf("Expected the target to see %v but got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateCloseReset(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{Protocol: protocolUDP}},
+		{name: "TCP", cfg: Config{CloseReset: true, CheckType: checkTypeTCP, Protocol: protocolTCP}},
+		{name: "HTTP", cfg: Config{CloseReset: true, CheckType: checkTypeHTTP}},
+		{name: "UDP", cfg: Config{CloseReset: true, Protocol: protocolUDP}, err: "CLOSE_RESET cannot be combined with PROTOCOL=udp"},
+		{name: "Exec check", cfg: Config{CloseReset: true, CheckType: checkTypeExec}, err: "CLOSE_RESET cannot be combined with CHECK_TYPE=exec"},
+		{name: "SOCKS5 proxy", cfg: Config{CloseReset: true, CheckType: checkTypeTCP, ProxyAddress: "bastion:1080"}, err: "CLOSE_RESET cannot be combined with PROXY_ADDRESS"},
+		{name: "HTTP proxy", cfg: Config{CloseReset: true, CheckType: checkTypeTCP, HTTPProxyAddress: "proxy:3128"}, err: "CLOSE_RESET cannot be combined with HTTP_PROXY_ADDRESS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateCloseReset(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
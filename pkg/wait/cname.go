@@ -0,0 +1,241 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const envLogCNAMEChain = "LOG_CNAME_CHAIN"
+
+// maxCNAMEHops bounds the length of a CNAME chain, protecting against loops.
+const maxCNAMEHops = 16
+
+// cnameLookup returns the target of the CNAME record of the fully qualified name, or an empty string if there is none.
+// A name that does not exist fails with a not found *net.DNSError.
+type cnameLookup func(ctx context.Context, name string) (string, error)
+
+// resolvConf holds the settings of /etc/resolv.conf needed to qualify names like the system resolver.
+type resolvConf struct {
+	server string   // The first name server as host:port.
+	search []string // The search domains appended to names with fewer dots than ndots first.
+	ndots  int      // The number of dots from which a name is tried as is before the search domains.
+}
+
+// readResolvConf reads the first name server, the search domains and ndots from the resolv.conf file.
+// Missing settings fall back to the defaults of the system resolver.
+func readResolvConf(path string) resolvConf {
+	conf := resolvConf{server: "127.0.0.1:53", ndots: 1}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return conf
+	}
+	defer f.Close()
+
+	serverSet := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			if !serverSet {
+				conf.server = net.JoinHostPort(fields[1], "53")
+				serverSet = true
+			}
+		case "domain", "search":
+			conf.search = fields[1:] // the last search or domain line wins
+		case "options":
+			for _, option := range fields[1:] {
+				if value, ok := strings.CutPrefix(option, "ndots:"); ok {
+					if ndots, err := strconv.Atoi(value); err == nil && ndots >= 0 {
+						conf.ndots = min(ndots, 15)
+					}
+				}
+			}
+		}
+	}
+
+	return conf
+}
+
+// searchNames returns the fully qualified names the host is tried as, in the order of the system resolver:
+// names with at least ndots dots are tried as is first, all others after the search domains.
+func (c resolvConf) searchNames(host string) []string {
+	if strings.HasSuffix(host, ".") {
+		return []string{host}
+	}
+
+	names := make([]string, 0, len(c.search)+1)
+	for _, domain := range c.search {
+		names = append(names, host+"."+strings.TrimSuffix(domain, ".")+".")
+	}
+	if strings.Count(host, ".") >= c.ndots {
+		return append([]string{host + "."}, names...)
+	}
+	return append(names, host+".")
+}
+
+// resolveCNAMEChain follows the CNAME records starting at the first of the names that exists
+// and returns all names along the chain.
+func resolveCNAMEChain(ctx context.Context, lookup cnameLookup, names []string) ([]string, error) {
+	var err error
+	for _, name := range names {
+		var target string
+		target, err = lookup(ctx, name)
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			continue // try the next search domain
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		chain := []string{strings.TrimSuffix(name, ".")}
+		for target != "" {
+			if len(chain) > maxCNAMEHops {
+				return chain, fmt.Errorf("CNAME chain of %s exceeds %d hops", chain[0], maxCNAMEHops)
+			}
+			chain = append(chain, strings.TrimSuffix(target, "."))
+			if target, err = lookup(ctx, target); err != nil {
+				return chain, err
+			}
+		}
+		return chain, nil
+	}
+
+	return nil, err
+}
+
+// logCNAMEChain wraps the check to log the CNAME chain of the target host once.
+// The chain is only looked up once an attempt got past resolving the target host, so failing resolutions
+// do not send an extra query on every attempt. Failing to look up the chain does not affect the check.
+func logCNAMEChain(cfg Config, logger *slog.Logger, conf resolvConf, lookup cnameLookup, check checkFunc) checkFunc {
+	host := targetHost(cfg)
+	if net.ParseIP(host) != nil {
+		return check // addresses have no CNAME records
+	}
+
+	done := false
+	return func(ctx context.Context) error {
+		err := check(ctx)
+		if done || (err != nil && classifyError(err) == reasonDNS) || ctx.Err() != nil {
+			return err
+		}
+		done = true
+
+		chain, lookupErr := resolveCNAMEChain(ctx, lookup, conf.searchNames(host))
+		if lookupErr != nil {
+			logger.Debug(fmt.Sprintf("Failed to look up the CNAME chain of %s", cfg.TargetName), slog.String("error", lookupErr.Error()))
+			return err
+		}
+		logger.Info(fmt.Sprintf("%s resolved via CNAME chain", cfg.TargetName), slog.String("cname_chain", strings.Join(chain, " -> ")))
+		return err
+	}
+}
+
+// newDNSCNAMELookup returns a lookup querying the CNAME record of a name from the given DNS server.
+// The system resolver only exposes the canonical name, not the aliases in between, so the records are queried directly.
+func newDNSCNAMELookup(dialer Dialer, server string) cnameLookup {
+	return func(ctx context.Context, name string) (string, error) {
+		qname, err := dnsmessage.NewName(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid DNS name %q", name)
+		}
+
+		id := uint16(rand.N(1 << 16))
+		builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+		if err := builder.StartQuestions(); err != nil {
+			return "", err
+		}
+		if err := builder.Question(dnsmessage.Question{Name: qname, Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET}); err != nil {
+			return "", fmt.Errorf("invalid DNS name %q: %w", name, err)
+		}
+		query, err := builder.Finish()
+		if err != nil {
+			return "", err
+		}
+
+		conn, err := dialer.DialContext(ctx, "udp", server)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		stop, err := bindConnDeadline(ctx, conn, dialTimeout(dialer))
+		if err != nil {
+			return "", err
+		}
+		defer stop()
+
+		if _, err := conn.Write(query); err != nil {
+			return "", err
+		}
+
+		buf := make([]byte, 1232)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return "", err
+		}
+
+		return parseCNAMEResponse(buf[:n], id, qname)
+	}
+}
+
+// parseCNAMEResponse returns the target of the CNAME record of the name in the DNS response,
+// or an empty string if the response contains none.
+func parseCNAMEResponse(msg []byte, id uint16, name dnsmessage.Name) (string, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		return "", fmt.Errorf("invalid DNS response: %w", err)
+	}
+	if header.ID != id {
+		return "", errors.New("DNS response ID mismatch")
+	}
+
+	switch header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return "", &net.DNSError{Err: "no such host", Name: strings.TrimSuffix(name.String(), "."), IsNotFound: true}
+	default:
+		return "", fmt.Errorf("DNS query for %s failed with %s", strings.TrimSuffix(name.String(), "."), header.RCode)
+	}
+
+	if err := parser.SkipAllQuestions(); err != nil {
+		return "", fmt.Errorf("invalid DNS response: %w", err)
+	}
+
+	for {
+		answer, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid DNS response: %w", err)
+		}
+
+		if answer.Type == dnsmessage.TypeCNAME && strings.EqualFold(answer.Name.String(), name.String()) {
+			cname, err := parser.CNAMEResource()
+			if err != nil {
+				return "", fmt.Errorf("invalid DNS response: %w", err)
+			}
+			return cname.CNAME.String(), nil
+		}
+		if err := parser.SkipAnswer(); err != nil {
+			return "", fmt.Errorf("invalid DNS response: %w", err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newDNSServer starts a DNS server answering CNAME queries from the given records, keyed by fully qualified name.
+// Names without a target and unknown names are answered with an empty answer, unknown names with NXDOMAIN if nxdomain is set.
+func newDNSServer(t *testing.T, records map[string]string, nxdomain bool) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var parser dnsmessage.Parser
+			header, err := parser.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			question, err := parser.Question()
+			if err != nil {
+				continue
+			}
+
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: header.ID, Response: true, RecursionDesired: true, RecursionAvailable: true},
+				Questions: []dnsmessage.Question{question},
+			}
+			target, ok := records[question.Name.String()]
+			switch {
+			case ok && target != "":
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)},
+				}}
+			case !ok && nxdomain:
+				resp.Header.RCode = dnsmessage.RCodeNameError
+			}
+
+			packed, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestResolveCNAMEChain(t *testing.T) {
+	t.Run("Follow chain", func(t *testing.T) {
+		t.Parallel()
+
+		records := map[string]string{"db.example.com": "db.eu.example.com.", "db.eu.example.com.": "db-1.eu.example.net."}
+		lookup := func(ctx context.Context, name string) (string, error) {
+			return records[name], nil
+		}
+
+		chain, err := resolveCNAMEChain(context.Background(), lookup, []string{"db.example.com"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "db.example.com -> db.eu.example.com -> db-1.eu.example.net"
+		if got := strings.Join(chain, " -> "); got != expected {
+			t.Errorf("Expected chain %q but got %q", expected, got)
+		}
+	})
+
+	t.Run("Loop", func(t *testing.T) {
+		t.Parallel()
+
+		lookup := func(ctx context.Context, name string) (string, error) {
+			return "db.example.com", nil
+		}
+
+		_, err := resolveCNAMEChain(context.Background(), lookup, []string{"db.example.com"})
+
+		expected := "CNAME chain of db.example.com exceeds 16 hops"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestLogCNAMEChain(t *testing.T) {
+	t.Parallel()
+
+	var lookups []string
+	lookup := func(ctx context.Context, name string) (string, error) {
+		lookups = append(lookups, name)
+		if name == "db.example.com." {
+			return "db-1.example.net.", nil
+		}
+		return "", nil
+	}
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
+	// the target host only resolves from the second attempt on
+	results := []error{&net.DNSError{Err: "no such host", Name: "db.example.com", IsNotFound: true}, nil, nil}
+	cfg := Config{TargetName: "db", TargetAddress: "db.example.com:5432", CheckType: checkTypeTCP}
+	check := logCNAMEChain(cfg, logger, resolvConf{ndots: 1}, lookup, func(ctx context.Context) error {
+		err := results[0]
+		results = results[1:]
+		return err
+	})
+
+	for i := 0; i < 3; i++ {
+		_ = check(context.Background())
+	}
+
+	expected := `msg="db resolved via CNAME chain" cname_chain="db.example.com -> db-1.example.net"`
+	if count := strings.Count(output.String(), expected); count != 1 {
+		t.Errorf("Expected the chain to be logged once but got %q", output.String())
+	}
+	if expectedLookups := []string{"db.example.com.", "db-1.example.net."}; !reflect.DeepEqual(lookups, expectedLookups) {
+		t.Errorf("Expected lookups %q only after the host resolved but got %q", expectedLookups, lookups)
+	}
+}
+
+func TestReadResolvConf(t *testing.T) {
+	t.Run("Kubernetes", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "resolv.conf")
+		content := "search ns.svc.cluster.local svc.cluster.local cluster.local\nnameserver 10.96.0.10\nnameserver 10.96.0.11\noptions ndots:5\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write resolv.conf: %v", err)
+		}
+
+		expected := resolvConf{
+			server: "10.96.0.10:53",
+			search: []string{"ns.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+			ndots:  5,
+		}
+		if conf := readResolvConf(path); !reflect.DeepEqual(conf, expected) {
+			t.Errorf("Expected %+v but got %+v", expected, conf)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Parallel()
+
+		expected := resolvConf{server: "127.0.0.1:53", ndots: 1}
+		if conf := readResolvConf(filepath.Join(t.TempDir(), "resolv.conf")); !reflect.DeepEqual(conf, expected) {
+			t.Errorf("Expected %+v but got %+v", expected, conf)
+		}
+	})
+}
+
+func TestSearchNames(t *testing.T) {
+	t.Parallel()
+
+	conf := resolvConf{search: []string{"ns.svc.cluster.local", "cluster.local"}, ndots: 2}
+
+	tests := []struct {
+		host     string
+		expected []string
+	}{
+		{host: "db", expected: []string{"db.ns.svc.cluster.local.", "db.cluster.local.", "db."}},
+		{host: "db.ns", expected: []string{"db.ns.ns.svc.cluster.local.", "db.ns.cluster.local.", "db.ns."}},
+		{host: "db.example.com", expected: []string{"db.example.com.", "db.example.com.ns.svc.cluster.local.", "db.example.com.cluster.local."}},
+		{host: "db.example.com.", expected: []string{"db.example.com."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			t.Parallel()
+
+			if names := conf.searchNames(tt.host); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %q but got %q", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestDNSCNAMELookup(t *testing.T) {
+	t.Run("Chain from DNS server", func(t *testing.T) {
+		t.Parallel()
+
+		server := newDNSServer(t, map[string]string{"db.example.com.": "db.eu.example.com.", "db.eu.example.com.": "db-1.example.net."}, false)
+		lookup := newDNSCNAMELookup(&net.Dialer{Timeout: time.Second}, server)
+
+		chain, err := resolveCNAMEChain(context.Background(), lookup, []string{"db.example.com."})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "db.example.com -> db.eu.example.com -> db-1.example.net"
+		if got := strings.Join(chain, " -> "); got != expected {
+			t.Errorf("Expected chain %q but got %q", expected, got)
+		}
+	})
+
+	t.Run("Short name qualified by search domain", func(t *testing.T) {
+		t.Parallel()
+
+		server := newDNSServer(t, map[string]string{"db.ns.svc.cluster.local.": "db-1.example.net.", "db-1.example.net.": ""}, true)
+		lookup := newDNSCNAMELookup(&net.Dialer{Timeout: time.Second}, server)
+		conf := resolvConf{search: []string{"cluster.local", "ns.svc.cluster.local"}, ndots: 5}
+
+		chain, err := resolveCNAMEChain(context.Background(), lookup, conf.searchNames("db"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "db.ns.svc.cluster.local -> db-1.example.net"
+		if got := strings.Join(chain, " -> "); got != expected {
+			t.Errorf("Expected chain %q but got %q", expected, got)
+		}
+	})
+
+	t.Run("Unknown host", func(t *testing.T) {
+		t.Parallel()
+
+		server := newDNSServer(t, nil, true)
+		lookup := newDNSCNAMELookup(&net.Dialer{Timeout: time.Second}, server)
+
+		_, err := resolveCNAMEChain(context.Background(), lookup, []string{"missing.example.com.", "missing."})
+
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Errorf("Expected not found DNS error but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+const envColor = "COLOR"
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// logTone marks records whose outcome is highlighted in colored output.
+type logTone int
+
+const (
+	toneReady logTone = iota + 1
+	toneNotReady
+)
+
+type logToneKey struct{}
+
+// withLogTone returns a context marking the records logged with it with the given tone.
+func withLogTone(ctx context.Context, tone logTone) context.Context {
+	return context.WithValue(ctx, logToneKey{}, tone)
+}
+
+// validateColor checks if the color mode is valid.
+func validateColor(cfg *Config) error {
+	switch cfg.Color {
+	case "":
+		cfg.Color = colorAuto
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s, %s", envColor, colorAuto, colorAlways, colorNever)
+	}
+	return nil
+}
+
+// resolveColor decides whether to color the output in auto mode, which is only done on a terminal.
+func resolveColor(color string, output io.Writer) string {
+	if color != colorAuto && color != "" {
+		return color
+	}
+	if isTerminal(output) {
+		return colorAlways
+	}
+	return colorNever
+}
+
+// isTerminal reports whether the output is a character device like a terminal.
+func isTerminal(output io.Writer) bool {
+	f, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorWriter writes each record in the color selected for it.
+type colorWriter struct {
+	mu     sync.Mutex // serializes records, as the color is selected per record
+	output io.Writer
+	color  string
+}
+
+// Write writes p in the current color, keeping the trailing newline after the reset sequence.
+func (w *colorWriter) Write(p []byte) (int, error) {
+	if w.color == "" {
+		return w.output.Write(p)
+	}
+
+	line := p
+	var newline []byte
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line, newline = line[:n-1], line[n-1:]
+	}
+
+	colored := make([]byte, 0, len(p)+len(w.color)+len(ansiReset))
+	colored = append(colored, w.color...)
+	colored = append(colored, line...)
+	colored = append(colored, ansiReset...)
+	colored = append(colored, newline...)
+
+	if _, err := w.output.Write(colored); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// colorHandler colors the records of the text handler by their outcome:
+// ready records are green, not ready records yellow and errors red.
+type colorHandler struct {
+	slog.Handler
+	writer *colorWriter
+}
+
+// newColorHandler returns a text handler writing colored records to output.
+func newColorHandler(output io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	writer := &colorWriter{output: output}
+	return &colorHandler{Handler: slog.NewTextHandler(writer, opts), writer: writer}
+}
+
+// Handle writes the record in the color matching its tone or level.
+func (h *colorHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	h.writer.color = recordColor(ctx, r)
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a colored handler with the given attributes.
+func (h *colorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &colorHandler{Handler: h.Handler.WithAttrs(attrs), writer: h.writer}
+}
+
+// WithGroup returns a colored handler with the given group.
+func (h *colorHandler) WithGroup(name string) slog.Handler {
+	return &colorHandler{Handler: h.Handler.WithGroup(name), writer: h.writer}
+}
+
+// recordColor returns the color of the record, none if it is neither an outcome nor an error.
+func recordColor(ctx context.Context, r slog.Record) string {
+	tone, _ := ctx.Value(logToneKey{}).(logTone)
+	switch {
+	case r.Level >= slog.LevelError:
+		return ansiRed
+	case tone == toneReady:
+		return ansiGreen
+	case tone == toneNotReady:
+		return ansiYellow
+	default:
+		return ""
+	}
+}
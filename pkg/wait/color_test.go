@@ -0,0 +1,112 @@
+package wait
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		color    string
+		expected string
+		errorMsg string
+	}{
+		{name: "Default", color: "", expected: colorAuto},
+		{name: "Always", color: "always", expected: colorAlways},
+		{name: "Never", color: "never", expected: colorNever},
+		{name: "Invalid", color: "rainbow", errorMsg: "invalid COLOR value: must be one of auto, always, never"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{Color: tt.color}
+			err := validateColor(&cfg)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("Expected error %q but got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Color != tt.expected {
+				t.Errorf("Expected color %q but got %q", tt.expected, cfg.Color)
+			}
+		})
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name     string
+		color    string
+		output   io.Writer
+		expected string
+	}{
+		{name: "Auto without terminal", color: colorAuto, output: &strings.Builder{}, expected: colorNever},
+		{name: "Auto with regular file", color: colorAuto, output: file, expected: colorNever},
+		{name: "Always without terminal", color: colorAlways, output: &strings.Builder{}, expected: colorAlways},
+		{name: "Never", color: colorNever, output: file, expected: colorNever},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if color := resolveColor(tt.color, tt.output); color != tt.expected {
+				t.Errorf("Expected %q but got %q", tt.expected, color)
+			}
+		})
+	}
+}
+
+func TestColorHandler(t *testing.T) {
+	t.Parallel()
+
+	var output strings.Builder
+	logger := setupLogger(Config{LogFormat: logFormatText, Color: colorAlways}, &output, nil)
+	ctx := context.Background()
+
+	logger.InfoContext(withLogTone(ctx, toneReady), "database is ready ✓")
+	logger.Log(withLogTone(ctx, toneNotReady), slog.LevelWarn, "database is not ready ✗")
+	logger.Error("database cannot become ready ✗")
+	logger.Info("Waiting for database to become ready...")
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines but got %d: %q", len(lines), output.String())
+	}
+
+	expected := []struct {
+		prefix string
+		suffix string
+	}{
+		{ansiGreen, ansiReset},
+		{ansiYellow, ansiReset},
+		{ansiRed, ansiReset},
+		{"time=", `msg="Waiting for database to become ready..."`},
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(lines[i], e.prefix) || !strings.HasSuffix(lines[i], e.suffix) {
+			t.Errorf("Expected line %d to start with %q and end with %q but got %q", i, e.prefix, e.suffix, lines[i])
+		}
+	}
+}
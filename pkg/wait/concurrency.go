@@ -0,0 +1,124 @@
+package wait
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	envMaxConcurrency  = "MAX_CONCURRENCY"
+	envConcurrency     = "CONCURRENCY" // another name for MAX_CONCURRENCY, the size of the pool checking the targets
+	envConcurrencyRamp = "CONCURRENCY_RAMP"
+)
+
+// concurrencyLimiter bounds the number of concurrent checks across all targets.
+// During the ramp window, the limit grows linearly from 1 up to the maximum,
+// which smooths the initial burst of dials when waiting for many targets.
+type concurrencyLimiter struct {
+	max   int           // The maximum number of concurrent checks.
+	ramp  time.Duration // The window over which the limit grows to the maximum.
+	start time.Time     // When the ramp started.
+
+	mu      sync.Mutex
+	active  int           // The number of running checks.
+	changed chan struct{} // Closed whenever a check finished.
+}
+
+// newConcurrencyLimiter returns a limiter for the given number of targets,
+// or nil if the checks of all targets may run at once.
+func newConcurrencyLimiter(cfg Config, targets int) *concurrencyLimiter {
+	limit := targets
+	if cfg.MaxConcurrency > 0 && cfg.MaxConcurrency < targets {
+		limit = cfg.MaxConcurrency
+	}
+
+	if limit >= targets && cfg.ConcurrencyRamp <= 0 {
+		return nil
+	}
+
+	return &concurrencyLimiter{
+		max:     limit,
+		ramp:    cfg.ConcurrencyRamp,
+		start:   time.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// limit returns the current limit and when it increases next.
+// The returned time is zero once the maximum is reached.
+func (l *concurrencyLimiter) limit(now time.Time) (int, time.Time) {
+	elapsed := now.Sub(l.start)
+	if l.ramp <= 0 || l.max <= 1 || elapsed >= l.ramp {
+		return l.max, time.Time{}
+	}
+
+	// the limit increases by one every step, reaching the maximum at the end of the ramp
+	step := l.ramp / time.Duration(l.max-1)
+	current := 1 + int(elapsed/step)
+	return current, l.start.Add(time.Duration(current) * step)
+}
+
+// acquire blocks until a check may run or the context is canceled.
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		limit, next := l.limit(time.Now())
+		if l.active < limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		if err := l.wait(ctx, changed, next); err != nil {
+			return err
+		}
+	}
+}
+
+// wait blocks until a check finished, the limit increases at next, or the context is canceled.
+func (l *concurrencyLimiter) wait(ctx context.Context, changed <-chan struct{}, next time.Time) error {
+	var rampUp <-chan time.Time
+	if !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+		rampUp = timer.C
+	}
+
+	select {
+	case <-changed:
+	case <-rampUp:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// release marks a check as finished.
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// wrap returns a check that only runs once the limiter allows it.
+// A nil limiter returns the check unchanged.
+func (l *concurrencyLimiter) wrap(check checkFunc) checkFunc {
+	if l == nil {
+		return check
+	}
+
+	return func(ctx context.Context) error {
+		if err := l.acquire(ctx); err != nil {
+			return err
+		}
+		defer l.release()
+
+		return check(ctx)
+	}
+}
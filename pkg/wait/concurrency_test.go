@@ -0,0 +1,176 @@
+package wait
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Run("Unlimited", func(t *testing.T) {
+		t.Parallel()
+
+		if limiter := newConcurrencyLimiter(Config{}, 10); limiter != nil {
+			t.Errorf("Expected no limiter but got %+v", limiter)
+		}
+
+		if limiter := newConcurrencyLimiter(Config{MaxConcurrency: 20}, 10); limiter != nil {
+			t.Errorf("Expected no limiter but got %+v", limiter)
+		}
+	})
+
+	t.Run("Ramp up limit", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newConcurrencyLimiter(Config{MaxConcurrency: 5, ConcurrencyRamp: 4 * time.Second}, 10)
+
+		tests := []struct {
+			elapsed time.Duration
+			limit   int
+		}{
+			{0, 1},
+			{999 * time.Millisecond, 1},
+			{time.Second, 2},
+			{3500 * time.Millisecond, 4},
+			{4 * time.Second, 5},
+			{time.Minute, 5},
+		}
+
+		for _, tt := range tests {
+			if limit, _ := limiter.limit(limiter.start.Add(tt.elapsed)); limit != tt.limit {
+				t.Errorf("Expected limit %d after %s but got %d", tt.limit, tt.elapsed, limit)
+			}
+		}
+	})
+
+	t.Run("Bound concurrent checks", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newConcurrencyLimiter(Config{MaxConcurrency: 2}, 6)
+
+		var active, peak atomic.Int32
+		check := limiter.wrap(func(ctx context.Context) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := check(context.Background()); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if p := peak.Load(); p != 2 {
+			t.Errorf("Expected at most %d concurrent checks but got %d", 2, p)
+		}
+	})
+
+	t.Run("Context cancel while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newConcurrencyLimiter(Config{MaxConcurrency: 1}, 2)
+		if err := limiter.acquire(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if err := limiter.acquire(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected %v but got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("Waiting for a slot does not count against ATTEMPT_TIMEOUT", func(t *testing.T) {
+		t.Parallel()
+
+		// every target answers after 150ms, so the last of 4 targets checked one by one gets its slot after 450ms
+		lis := newListener(t)
+		go func() {
+			for {
+				conn, err := lis.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					time.Sleep(150 * time.Millisecond)
+					conn.Write([]byte("+PONG\r\n"))
+				}()
+			}
+		}()
+
+		var targets []Target
+		for _, name := range []string{"database", "cache", "queue", "search"} {
+			targets = append(targets, Target{Name: name, Address: lis.Addr().String()})
+		}
+		cfg := Config{
+			CheckType:      checkTypeTCP,
+			Interval:       time.Second,
+			DialTimeout:    time.Second,
+			AttemptTimeout: 300 * time.Millisecond,
+			ProbeExpect:    []byte("+PONG"),
+			MaxConcurrency: 1,
+			OneShot:        true,
+			Targets:        targets,
+		}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestParseConcurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected int
+		err      string
+	}{
+		{name: "CONCURRENCY", env: map[string]string{"CONCURRENCY": "4"}, expected: 4},
+		{name: "MAX_CONCURRENCY", env: map[string]string{"MAX_CONCURRENCY": "2"}, expected: 2},
+		{name: "Both", env: map[string]string{"CONCURRENCY": "4", "MAX_CONCURRENCY": "2"}, err: "CONCURRENCY cannot be combined with MAX_CONCURRENCY"},
+		{name: "Negative", env: map[string]string{"CONCURRENCY": "-1"}, err: "invalid CONCURRENCY value: concurrency cannot be negative"},
+		{name: "Invalid", env: map[string]string{"CONCURRENCY": "many"}, err: `invalid CONCURRENCY value: strconv.Atoi: parsing "many": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := ParseConfig(func(key string) string { return tt.env[key] })
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.MaxConcurrency != tt.expected {
+				t.Errorf("Expected a concurrency of %d but got %d", tt.expected, cfg.MaxConcurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package wait
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const envConfigFile = "CONFIG_FILE"
+
+// configFileTargetKeys maps the keys of a target in the targets list to their indexed environment variables.
+var configFileTargetKeys = map[string]string{
+	"name":    envIndexedTargetName,
+	"address": envIndexedTargetAddress,
+	"type":    envIndexedTargetType,
+	"timeout": envIndexedTargetTimeout,
+}
+
+// withFallback returns a getenv that falls back to the given values, e.g. of a config file, for unset environment variables.
+func withFallback(getenv func(string) string, values map[string]string) func(string) string {
+	return func(key string) string {
+		if value := getenv(key); value != "" {
+			return value
+		}
+		return values[key]
+	}
+}
+
+// loadConfigFile reads a YAML config file and returns its values keyed by environment variable.
+func loadConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseConfigFile(bufio.NewScanner(f))
+}
+
+// parseConfigFile parses the subset of YAML used by config files.
+// Top-level keys are environment variable names, case-insensitive, with scalar values.
+// The only nested value is a list of targets with a name, address, type and timeout each,
+// which is translated to indexed targets. The list may be indented or not.
+func parseConfigFile(scanner *bufio.Scanner) (map[string]string, error) {
+	values := make(map[string]string)
+
+	inTargets := false
+	index := 0
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := stripYAMLComment(scanner.Text())
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		// the items of the targets list may start at the same column as the key, as YAML allows
+		if !indented && !(inTargets && line[0] == '-') {
+			key, value, err := parseYAMLPair(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNum, err)
+			}
+
+			inTargets = strings.EqualFold(key, "targets") && value == ""
+			if inTargets {
+				continue
+			}
+			if value == "" {
+				return nil, fmt.Errorf("line %d: nested values are only supported for targets", lineNum)
+			}
+
+			values[strings.ToUpper(key)] = value
+			continue
+		}
+
+		if !inTargets {
+			return nil, fmt.Errorf("line %d: unexpected indentation", lineNum)
+		}
+
+		item := strings.TrimSpace(line)
+		if rest, found := strings.CutPrefix(item, "-"); found {
+			index++
+			item = strings.TrimSpace(rest)
+		}
+		if index == 0 {
+			return nil, fmt.Errorf("line %d: targets must be a list", lineNum)
+		}
+
+		key, value, err := parseYAMLPair(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+
+		envFormat, ok := configFileTargetKeys[strings.ToLower(key)]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unsupported target key %q", lineNum, key)
+		}
+		values[fmt.Sprintf(envFormat, index)] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// parseYAMLPair splits a "key: value" line and unquotes the value.
+func parseYAMLPair(line string) (string, string, error) {
+	key, value, found := strings.Cut(line, ":")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", fmt.Errorf("expected key: value but got %q", strings.TrimSpace(line))
+	}
+
+	value, err := unquoteYAML(strings.TrimSpace(value))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid value of %s: %s", key, err)
+	}
+
+	return key, value, nil
+}
+
+// unquoteYAML removes the quotes of single- or double-quoted scalars.
+func unquoteYAML(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	default:
+		return value, nil
+	}
+}
+
+// stripYAMLComment removes a trailing comment outside of quoted scalars and trailing whitespace.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case (c == '"' || c == '\'') && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
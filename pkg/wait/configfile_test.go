@@ -0,0 +1,172 @@
+package wait
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "Scalars",
+			content: `---
+# shared defaults
+INTERVAL: 5s
+dial_timeout: "500ms" # lowercase keys are supported
+LOG_FORMAT: 'json'
+TARGET_ADDRESS: db:5432
+`,
+			expected: map[string]string{
+				"INTERVAL":       "5s",
+				"DIAL_TIMEOUT":   "500ms",
+				"LOG_FORMAT":     "json",
+				"TARGET_ADDRESS": "db:5432",
+			},
+		},
+		{
+			name: "Targets",
+			content: `CHECK_TYPE: tcp
+targets:
+  - name: Postgres
+    address: postgres:5432
+  - address: valkey:6379
+    type: tls
+    timeout: 5s
+`,
+			expected: map[string]string{
+				"CHECK_TYPE":       "tcp",
+				"TARGET_1_NAME":    "Postgres",
+				"TARGET_1_ADDRESS": "postgres:5432",
+				"TARGET_2_ADDRESS": "valkey:6379",
+				"TARGET_2_TYPE":    "tls",
+				"TARGET_2_TIMEOUT": "5s",
+			},
+		},
+		{
+			name: "Unindented targets",
+			content: `targets:
+- name: db
+  address: postgres:5432
+- address: valkey:6379
+INTERVAL: 5s
+`,
+			expected: map[string]string{
+				"TARGET_1_NAME":    "db",
+				"TARGET_1_ADDRESS": "postgres:5432",
+				"TARGET_2_ADDRESS": "valkey:6379",
+				"INTERVAL":         "5s",
+			},
+		},
+		{
+			name:     "Quoted hash",
+			content:  `PROBE_EXPECT: "# ready"`,
+			expected: map[string]string{"PROBE_EXPECT": "# ready"},
+		},
+		{
+			name:    "Missing colon",
+			content: "INTERVAL 5s",
+			err:     `line 1: expected key: value but got "INTERVAL 5s"`,
+		},
+		{
+			name:    "Unsupported nested value",
+			content: "http:\n  method: GET",
+			err:     "line 1: nested values are only supported for targets",
+		},
+		{
+			name:    "Unexpected indentation",
+			content: "INTERVAL: 5s\n  DIAL_TIMEOUT: 1s",
+			err:     "line 2: unexpected indentation",
+		},
+		{
+			name:    "Unsupported target key",
+			content: "targets:\n  - port: 5432",
+			err:     `line 2: unsupported target key "port"`,
+		},
+		{
+			name:    "Targets not a list",
+			content: "targets:\n  address: postgres:5432",
+			err:     "line 2: targets must be a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := parseConfigFile(bufio.NewScanner(strings.NewReader(tt.content)))
+			if tt.err != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %q", tt.err, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithConfigFile(t *testing.T) {
+	t.Run("Environment overrides config file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "taco.yaml")
+		content := "TARGET_NAME: database\nTARGET_ADDRESS: localhost:5432\nINTERVAL: 5s\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		env := map[string]string{
+			"CONFIG_FILE": path,
+			"INTERVAL":    "1s",
+		}
+
+		cfg, err := ParseConfig(func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.TargetName != "database" {
+			t.Errorf("Expected target name %q but got %q", "database", cfg.TargetName)
+		}
+		if cfg.Interval != time.Second {
+			t.Errorf("Expected interval %s but got %s", time.Second, cfg.Interval)
+		}
+	})
+
+	t.Run("Missing config file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "missing.yaml")
+		env := map[string]string{"CONFIG_FILE": path}
+
+		_, err := ParseConfig(func(key string) string { return env[key] })
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if !strings.HasPrefix(err.Error(), "invalid CONFIG_FILE value: open ") {
+			t.Errorf("Expected open error but got %q", err.Error())
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+)
+
+const envConcurrentConns = "CONCURRENT_CONNS"
+
+// checkConcurrentConnections opens n connections to the given address at the same time
+// and returns how many succeeded. All connections are closed before returning.
+// The error joins the errors of all failed connections.
+func checkConcurrentConnections(ctx context.Context, dialer Dialer, address string, n int) (int, error) {
+	var wg sync.WaitGroup
+	conns := make([]net.Conn, n)
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conns[i], errs[i] = dialer.DialContext(ctx, "tcp", address)
+		}(i)
+	}
+
+	wg.Wait()
+
+	// keep all connections open until every dial finished, so they are truly concurrent
+	succeeded := 0
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Close()
+			succeeded++
+		}
+	}
+
+	return succeeded, errors.Join(errs...)
+}
+
+// newConcurrentConnectionsCheck returns the check opening the configured number of concurrent connections.
+// It logs how many connections succeeded on every attempt.
+func newConcurrentConnectionsCheck(cfg Config, dialer Dialer, logger *slog.Logger) checkFunc {
+	return func(ctx context.Context) error {
+		succeeded, err := checkConcurrentConnections(ctx, dialer, cfg.TargetAddress, cfg.ConcurrentConns)
+		logger.Info(fmt.Sprintf("%d/%d concurrent connections to %s succeeded", succeeded, cfg.ConcurrentConns, cfg.TargetName),
+			slog.Int("succeeded", succeeded),
+			slog.Int("concurrent_conns", cfg.ConcurrentConns),
+		)
+		if err != nil {
+			return fmt.Errorf("only %d/%d concurrent connections succeeded: %w", succeeded, cfg.ConcurrentConns, err)
+		}
+		return nil
+	}
+}
+
+// validateConcurrentConns checks if the concurrent connections setting is valid.
+// The setting only applies to plain TCP connects.
+func validateConcurrentConns(cfg Config) error {
+	if cfg.ConcurrentConns < 0 {
+		return fmt.Errorf("invalid %s value: connections cannot be negative", envConcurrentConns)
+	}
+
+	if cfg.ConcurrentConns == 0 {
+		return nil
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType != checkTypeTCP {
+			return fmt.Errorf("%s can only be used with check type %q", envConcurrentConns, checkTypeTCP)
+		}
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envProtocol, cfg.Protocol == protocolUDP},
+		{envReverseCheckListen, cfg.ReverseCheckListen != ""},
+		{envProbeSend, len(cfg.ProbeSend) > 0},
+		{envProbeExpect, len(cfg.ProbeExpect) > 0},
+		{envCaptureResponseFile, cfg.CaptureResponseFile != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envConcurrentConns, conflict.env)
+		}
+	}
+
+	return nil
+}
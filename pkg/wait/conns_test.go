@@ -0,0 +1,82 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConcurrentConnectionsCheck(t *testing.T) {
+	t.Run("All connections succeed", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "postgres", TargetAddress: newListener(t).Addr().String(), ConcurrentConns: 5}
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		if err := newConcurrentConnectionsCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "5/5 concurrent connections to postgres succeeded"
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+
+	t.Run("Connections fail", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "postgres", TargetAddress: closedAddress(t), ConcurrentConns: 3}
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		err := newConcurrentConnectionsCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background())
+		if err == nil || !strings.HasPrefix(err.Error(), "only 0/3 concurrent connections succeeded: ") {
+			t.Errorf("Expected error about failed connections but got %v", err)
+		}
+
+		expected := "0/3 concurrent connections to postgres succeeded"
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+}
+
+func TestValidateConcurrentConns(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{CheckType: checkTypeTLS}},
+		{name: "Plain TCP", cfg: Config{CheckType: checkTypeTCP, ConcurrentConns: 10}},
+		{name: "Negative", cfg: Config{CheckType: checkTypeTCP, ConcurrentConns: -1}, err: "invalid CONCURRENT_CONNS value: connections cannot be negative"},
+		{name: "TLS check", cfg: Config{CheckType: checkTypeTLS, ConcurrentConns: 2}, err: `CONCURRENT_CONNS can only be used with check type "tcp"`},
+		{name: "UDP", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, ConcurrentConns: 2}, err: "CONCURRENT_CONNS cannot be combined with PROTOCOL"},
+		{name: "Probe", cfg: Config{CheckType: checkTypeTCP, ProbeSend: []byte("PING"), ConcurrentConns: 2}, err: "CONCURRENT_CONNS cannot be combined with PROBE_SEND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateConcurrentConns(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package wait
+
+import "time"
+
+const envDedupLogs = "DEDUP_LOGS"
+
+// dedupSummaryInterval is how often a summary is logged while identical failures are suppressed.
+const dedupSummaryInterval = time.Minute
+
+// logDeduper collapses consecutive failures with the same error into a periodic summary.
+// A nil logDeduper logs every failure.
+type logDeduper struct {
+	lastErr    string    // The error of the last failed attempt.
+	repeated   int       // How many consecutive attempts failed with lastErr.
+	lastLogged time.Time // When the last record about lastErr was logged.
+}
+
+// failure records a failed attempt and returns whether it is logged as usual.
+// A repeated failure is not logged, but a summary is due once per dedupSummaryInterval.
+func (d *logDeduper) failure(err error, now time.Time) (log, summarize bool) {
+	if d == nil {
+		return true, false
+	}
+
+	if err.Error() != d.lastErr {
+		d.lastErr, d.repeated, d.lastLogged = err.Error(), 1, now
+		return true, false
+	}
+
+	d.repeated++
+	if now.Sub(d.lastLogged) < dedupSummaryInterval {
+		return false, false
+	}
+	d.lastLogged = now
+	return false, true
+}
+
+// reset forgets the last failure after a successful attempt, so the next failure is logged immediately.
+func (d *logDeduper) reset() {
+	if d == nil {
+		return
+	}
+	*d = logDeduper{}
+}
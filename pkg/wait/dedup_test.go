@@ -0,0 +1,94 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogDeduper(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	refused := errors.New("connection refused")
+	reset := errors.New("connection reset by peer")
+
+	steps := []struct {
+		name      string
+		err       error
+		after     time.Duration
+		log       bool
+		summarize bool
+	}{
+		{name: "First failure", err: refused, log: true},
+		{name: "Repeated failure", err: refused, after: time.Second},
+		{name: "Summary due", err: refused, after: dedupSummaryInterval, summarize: true},
+		{name: "Repeated after summary", err: refused, after: dedupSummaryInterval + time.Second},
+		{name: "Changed error", err: reset, after: dedupSummaryInterval + 2*time.Second, log: true},
+	}
+
+	var d logDeduper
+	for _, step := range steps {
+		log, summarize := d.failure(step.err, start.Add(step.after))
+		if log != step.log || summarize != step.summarize {
+			t.Errorf("%s: expected log %t and summarize %t but got %t and %t", step.name, step.log, step.summarize, log, summarize)
+		}
+	}
+
+	d.reset()
+	if log, _ := d.failure(reset, start); !log {
+		t.Error("Expected the first failure after a reset to be logged")
+	}
+
+	var disabled *logDeduper
+	if log, _ := disabled.failure(refused, start); !log {
+		t.Error("Expected every failure to be logged without deduplication")
+	}
+}
+
+func TestDedupLogs(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		errs     []string
+		expected int
+	}{
+		{name: "Disabled", cfg: Config{}, errs: []string{"connection refused", "connection refused", "connection refused"}, expected: 3},
+		{name: "Identical failures", cfg: Config{DedupLogs: true}, errs: []string{"connection refused", "connection refused", "connection refused"}, expected: 1},
+		{name: "Changed failure", cfg: Config{DedupLogs: true}, errs: []string{"connection refused", "connection refused", "i/o timeout"}, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg
+			cfg.TargetName = "database"
+			cfg.Interval = 10 * time.Millisecond
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+			errs := tt.errs
+			check := func(ctx context.Context) error {
+				if len(errs) == 0 {
+					return nil
+				}
+				err := errors.New(errs[0])
+				errs = errs[1:]
+				return err
+			}
+
+			if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if count := strings.Count(stdOut.String(), "database is not ready ✗"); count != tt.expected {
+				t.Errorf("Expected %d not ready lines but got %d in %q", tt.expected, count, stdOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+const envFailFastOnTimeout = "FAIL_FAST_ON_TIMEOUT"
+
+// dialError classifies why a target could not be reached.
+type dialError string
+
+const (
+	dialErrorRefused dialError = "refused" // Nothing listens on the target yet, retrying soon is fine.
+	dialErrorTimeout dialError = "timeout" // The target did not answer in time, e.g. due to a firewall dropping packets.
+)
+
+// classifyDialError tells a refused connection apart from a timeout.
+// Failures to resolve the target host and all other failures are not classified and return an empty dialError.
+func classifyDialError(err error) dialError {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "" // reported as cannot resolve host instead
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return dialErrorRefused
+	}
+
+	var netErr net.Error
+	if (errors.As(err, &netErr) && netErr.Timeout()) || errors.Is(err, context.DeadlineExceeded) {
+		return dialErrorTimeout
+	}
+
+	return ""
+}
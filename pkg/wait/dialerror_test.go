@@ -0,0 +1,101 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyDialError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected dialError
+	}{
+		{name: "Refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, expected: dialErrorRefused},
+		{name: "Dial timeout", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, expected: dialErrorTimeout},
+		{name: "Attempt timeout", err: fmt.Errorf("attempt timed out: %w", context.DeadlineExceeded), expected: dialErrorTimeout},
+		{name: "DNS timeout", err: &net.DNSError{Err: "i/o timeout", Name: "db", IsTimeout: true}},
+		{name: "Other", err: errors.New("unexpected status: 503 Service Unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if dialErr := classifyDialError(tt.err); dialErr != tt.expected {
+				t.Errorf("Expected %q but got %q", tt.expected, dialErr)
+			}
+		})
+	}
+}
+
+func TestFailFastOnTimeout(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		expected string
+		err      string
+	}{
+		{
+			name:     "Refused attempts do not count",
+			errs:     []error{refused, refused, refused, timeout},
+			expected: "dial_error=refused",
+		},
+		{
+			name:     "Timed out attempts",
+			errs:     []error{timeout, refused, timeout},
+			expected: "dial_error=timeout",
+			err:      "FAIL_FAST_ON_TIMEOUT exhausted after 2 timed out attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{TargetName: "database", Interval: 10 * time.Millisecond, FailFastOnTimeout: 2}
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+			errs := tt.errs
+			check := func(ctx context.Context) error {
+				if len(errs) == 0 {
+					return nil
+				}
+				err := errs[0]
+				errs = errs[1:]
+				return err
+			}
+
+			err := pollTarget(context.Background(), cfg, logger, check)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			} else {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("Expected error starting with %q but got %v", tt.err, err)
+				}
+				if code := ExitCode(err); code != defaultExitCodeConnection {
+					t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+				}
+			}
+
+			if !strings.Contains(stdOut.String(), tt.expected) {
+				t.Errorf("Expected output to contain %q but got %q", tt.expected, stdOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+)
+
+func init() {
+	registerCheck(checkTypeDNS, func(cfg Config, _ Dialer, logger *slog.Logger, capture captureFunc) checkFunc {
+		return newDNSCheck(cfg, net.DefaultResolver, logger, capture)
+	})
+}
+
+// newDNSCheck returns a check that succeeds once the host of the target resolves to at least one address,
+// e.g. to wait for the DNS record of a new service to propagate before it has a port to dial.
+// The resolved addresses are logged on success and captured with CAPTURE_RESPONSE_FILE.
+func newDNSCheck(cfg Config, resolver *net.Resolver, logger *slog.Logger, capture func([]byte) error) checkFunc {
+	host := targetHost(cfg)
+
+	return func(ctx context.Context) error {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return fmt.Errorf("%s resolved to no addresses", host)
+		}
+
+		logger.Info(fmt.Sprintf("%s resolved to %s", host, strings.Join(addrs, ", ")))
+
+		if capture == nil {
+			return nil
+		}
+		return capture([]byte(strings.Join(addrs, "\n") + "\n"))
+	}
+}
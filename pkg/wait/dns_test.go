@@ -0,0 +1,94 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDNSCheck(t *testing.T) {
+	t.Run("Host resolves", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		var captured []byte
+		capture := func(response []byte) error {
+			captured = response
+			return nil
+		}
+
+		cfg := Config{TargetAddress: "localhost", CheckType: checkTypeDNS}
+		if err := newDNSCheck(cfg, net.DefaultResolver, logger, capture)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !strings.Contains(stdOut.String(), "localhost resolved to ") {
+			t.Errorf("Expected the resolved addresses to be logged, got %q", stdOut.String())
+		}
+		if len(captured) == 0 {
+			t.Error("Expected the resolved addresses to be captured")
+		}
+	})
+
+	t.Run("Port is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+		cfg := Config{TargetAddress: "localhost:5432", CheckType: checkTypeDNS}
+		if err := newDNSCheck(cfg, net.DefaultResolver, logger, nil)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Host does not resolve", func(t *testing.T) {
+		t.Parallel()
+
+		logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+		cfg := Config{TargetAddress: "taco.invalid", CheckType: checkTypeDNS}
+		err := newDNSCheck(cfg, net.DefaultResolver, logger, nil)(context.Background())
+
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			t.Fatalf("Expected DNS error but got %v", err)
+		}
+	})
+}
+
+func TestValidateDNSAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		address  string
+		errorMsg string
+	}{
+		{name: "Host without port", address: "postgres.default.svc.cluster.local"},
+		{name: "Host with port", address: "postgres:5432"},
+		{name: "Schema", address: "dns://postgres", errorMsg: "TARGET_ADDRESS should not include a schema (dns)"},
+		{name: "Empty", address: "", errorMsg: "TARGET_ADDRESS environment variable is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateAddress(envTargetAddress, checkTypeDNS, tt.address)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error %q but got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Package wait waits for TCP services and other dependencies to become ready.
+//
+// It implements the taco command, and can be embedded to wait for dependencies on startup
+// instead of running taco as a separate process:
+//
+//	cfg, err := wait.ParseConfig(os.Getenv)
+//	if err != nil {
+//		return err
+//	}
+//	if err := wait.ValidateConfig(&cfg); err != nil {
+//		return err
+//	}
+//	if err := wait.WaitForTargets(ctx, cfg, slog.Default()); err != nil {
+//		return err
+//	}
+//
+// To configure it without environment variables, pass ParseConfig a getenv looking up the settings elsewhere,
+// e.g. in a map, so unset settings get the same defaults as with the taco command.
+package wait
@@ -0,0 +1,96 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const envRequireDualStack = "REQUIRE_DUAL_STACK"
+
+// ipFamilies lists the networks dialed to verify dual-stack readiness and their names.
+var ipFamilies = []struct {
+	network string
+	name    string
+}{
+	{"tcp4", "IPv4"},
+	{"tcp6", "IPv6"},
+}
+
+// newDualStackCheck returns a check connecting to the target over both IPv4 and IPv6.
+// The target is only ready if both families connect, and the result of each family is logged.
+func newDualStackCheck(cfg Config, dialer Dialer, logger *slog.Logger) checkFunc {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, family := range ipFamilies {
+			err := dialFamily(ctx, dialer, family.network, family.name, cfg.TargetAddress)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("%s is not reachable via %s", cfg.TargetName, family.name), slog.String("error", err.Error()))
+				errs = append(errs, err)
+				continue
+			}
+			logger.Info(fmt.Sprintf("%s is reachable via %s", cfg.TargetName, family.name))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// dialFamily connects to the address over the given network.
+// A host without an address of that family is reported as such instead of the generic dial error.
+func dialFamily(ctx context.Context, dialer Dialer, network, name, address string) error {
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) && strings.Contains(addrErr.Err, "no suitable address") {
+			host, _, _ := net.SplitHostPort(address)
+			return fmt.Errorf("%s has no %s address", host, name)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return conn.Close()
+}
+
+// validateDualStack checks if dual-stack verification is supported with the given configuration.
+func validateDualStack(cfg Config) error {
+	if !cfg.RequireDualStack {
+		return nil
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType != checkTypeTCP {
+			return fmt.Errorf("%s can only be used with check type %q", envRequireDualStack, checkTypeTCP)
+		}
+
+		host, _, err := net.SplitHostPort(targetCfg.TargetAddress)
+		if err != nil {
+			return err
+		}
+		if _, err := netip.ParseAddr(host); err == nil {
+			return fmt.Errorf("%s requires a host name with both IPv4 and IPv6 addresses, but %s is an IP address", envRequireDualStack, host)
+		}
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envProtocol, cfg.Protocol == protocolUDP},
+		{envConcurrentConns, cfg.ConcurrentConns > 0},
+		{envLogTCPMSS, cfg.LogTCPMSS},
+		{envReverseCheckListen, cfg.ReverseCheckListen != ""},
+		{envProbeSend, len(cfg.ProbeSend) > 0},
+		{envProbeExpect, len(cfg.ProbeExpect) > 0},
+		{envCaptureResponseFile, cfg.CaptureResponseFile != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envRequireDualStack, conflict.env)
+		}
+	}
+
+	return nil
+}
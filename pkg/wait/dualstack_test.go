@@ -0,0 +1,85 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDualStackCheck(t *testing.T) {
+	t.Run("Host without IPv6 address", func(t *testing.T) {
+		t.Parallel()
+
+		// localhost only resolves to 127.0.0.1 in the test environment
+		if addrs, err := net.DefaultResolver.LookupNetIP(context.Background(), "ip6", "localhost"); err == nil && len(addrs) > 0 {
+			t.Skip("localhost resolves to an IPv6 address")
+		}
+
+		_, port, _ := net.SplitHostPort(newListener(t).Addr().String())
+		cfg := Config{TargetName: "postgres", TargetAddress: net.JoinHostPort("localhost", port), RequireDualStack: true}
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		err := newDualStackCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background())
+
+		expected := "localhost has no IPv6 address"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+
+		for _, expected := range []string{"postgres is reachable via IPv4", "postgres is not reachable via IPv6"} {
+			if !strings.Contains(output.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+			}
+		}
+	})
+
+	t.Run("Connection refused", func(t *testing.T) {
+		t.Parallel()
+
+		_, port, _ := net.SplitHostPort(closedAddress(t))
+		cfg := Config{TargetName: "postgres", TargetAddress: net.JoinHostPort("localhost", port), RequireDualStack: true}
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+		err := newDualStackCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background())
+		if err == nil || !strings.HasPrefix(err.Error(), "IPv4: dial tcp4 ") {
+			t.Errorf("Expected IPv4 dial error but got %v", err)
+		}
+	})
+}
+
+func TestValidateDualStack(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{CheckType: checkTypeTLS, TargetAddress: "127.0.0.1:443"}},
+		{name: "Host name", cfg: Config{CheckType: checkTypeTCP, TargetAddress: "postgres:5432", RequireDualStack: true}},
+		{name: "IP address", cfg: Config{CheckType: checkTypeTCP, TargetAddress: "[::1]:5432", RequireDualStack: true}, err: "REQUIRE_DUAL_STACK requires a host name with both IPv4 and IPv6 addresses, but ::1 is an IP address"},
+		{name: "TLS check", cfg: Config{CheckType: checkTypeTLS, TargetAddress: "postgres:5432", RequireDualStack: true}, err: `REQUIRE_DUAL_STACK can only be used with check type "tcp"`},
+		{name: "Probe", cfg: Config{CheckType: checkTypeTCP, TargetAddress: "postgres:5432", ProbeSend: []byte("PING"), RequireDualStack: true}, err: "REQUIRE_DUAL_STACK cannot be combined with PROBE_SEND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDualStack(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
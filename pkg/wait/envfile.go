@@ -0,0 +1,94 @@
+package wait
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const envEnvFile = "ENV_FILE"
+
+// loadEnvFile reads a .env file and returns its values keyed by environment variable.
+func loadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseEnvFile(bufio.NewScanner(f))
+}
+
+// parseEnvFile parses KEY=value lines as used by .env files.
+// Blank lines, comments and an 'export ' prefix are ignored. Values may be quoted;
+// double-quoted values support escape sequences like \n, and unquoted values end at a ' #' comment.
+func parseEnvFile(scanner *bufio.Scanner) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !isEnvName(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNum)
+		}
+
+		value, err := unquoteEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// unquoteEnvValue removes the quotes of a value, or a trailing comment of an unquoted value.
+func unquoteEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted value %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated single-quoted value %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		return value, nil
+	}
+}
+
+// isEnvName reports whether the name is a valid environment variable name.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "Values",
+			content: `# local development
+TARGET_NAME=database
+export TARGET_ADDRESS=localhost:5432
+INTERVAL = 500ms # faster locally
+
+MSG_READY="{name} is up\tand running"
+MSG_NOT_READY='{name} is #down'
+EXEC_COMMAND=
+`,
+			expected: map[string]string{
+				"TARGET_NAME":    "database",
+				"TARGET_ADDRESS": "localhost:5432",
+				"INTERVAL":       "500ms",
+				"MSG_READY":      "{name} is up\tand running",
+				"MSG_NOT_READY":  "{name} is #down",
+				"EXEC_COMMAND":   "",
+			},
+		},
+		{
+			name:    "Missing separator",
+			content: "TARGET_NAME=database\nTARGET_ADDRESS localhost:5432\n",
+			err:     "line 2: expected KEY=value",
+		},
+		{
+			name:    "Invalid name",
+			content: "1TARGET=database\n",
+			err:     "line 1: expected KEY=value",
+		},
+		{
+			name:    "Unterminated quote",
+			content: "MSG_READY=\"{name} is up\n",
+			err:     `line 1: invalid double-quoted value "{name} is up`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := parseEnvFile(bufio.NewScanner(strings.NewReader(tt.content)))
+			if tt.err != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %q", tt.err, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithEnvFile(t *testing.T) {
+	t.Run("Environment overrides env file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "taco.yaml")
+		if err := os.WriteFile(configFile, []byte("INTERVAL: 5s\nDIAL_TIMEOUT: 5s\nTARGET_NAME: cache\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		envFile := filepath.Join(dir, ".env")
+		content := "TARGET_NAME=database\nTARGET_ADDRESS=localhost:5432\nINTERVAL=3s\nCONFIG_FILE=" + configFile + "\n"
+		if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write env file: %v", err)
+		}
+
+		env := map[string]string{
+			"ENV_FILE":    envFile,
+			"TARGET_NAME": "postgres",
+		}
+
+		cfg, err := ParseConfig(func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// environment > env file > config file
+		if cfg.TargetName != "postgres" {
+			t.Errorf("Expected target name %q but got %q", "postgres", cfg.TargetName)
+		}
+		if cfg.TargetAddress != "localhost:5432" {
+			t.Errorf("Expected target address %q but got %q", "localhost:5432", cfg.TargetAddress)
+		}
+		if cfg.Interval != 3*time.Second {
+			t.Errorf("Expected interval %s but got %s", 3*time.Second, cfg.Interval)
+		}
+		if cfg.DialTimeout != 5*time.Second {
+			t.Errorf("Expected dial timeout %s but got %s", 5*time.Second, cfg.DialTimeout)
+		}
+	})
+
+	t.Run("Malformed env file", func(t *testing.T) {
+		t.Parallel()
+
+		envFile := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(envFile, []byte("TARGET_ADDRESS localhost:5432\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write env file: %v", err)
+		}
+
+		env := map[string]string{"ENV_FILE": envFile}
+
+		err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard)
+
+		expected := "configuration error: invalid ENV_FILE value: line 1: expected KEY=value"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
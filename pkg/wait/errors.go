@@ -0,0 +1,187 @@
+package wait
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// failureReason classifies why a connection attempt failed.
+type failureReason string
+
+const (
+	reasonDNS        failureReason = "dns"        // The target host could not be resolved.
+	reasonConnection failureReason = "connection" // The target could not be reached.
+	reasonAuth       failureReason = "auth"       // The target rejected the credentials.
+)
+
+// failureReasons lists all failure reasons in a fixed order, so ties are broken deterministically.
+var failureReasons = []failureReason{reasonDNS, reasonConnection, reasonAuth}
+
+// errAuth marks failures caused by the target rejecting the credentials.
+var errAuth = errors.New("access denied")
+
+const (
+	defaultExitCodeConfig     = 1 // default exit code for configuration errors
+	defaultExitCodeDNS        = 3 // default exit code when giving up mostly due to DNS failures
+	defaultExitCodeConnection = 4 // default exit code when giving up mostly due to connection failures
+)
+
+// classifyError determines the failure reason of a connection error.
+func classifyError(err error) failureReason {
+	if errors.Is(err, errAuth) {
+		return reasonAuth
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return reasonDNS
+	}
+
+	return reasonConnection
+}
+
+// failureTally counts failed attempts per failure reason.
+type failureTally map[failureReason]int
+
+// add records a failed attempt and returns its reason.
+func (t failureTally) add(err error) failureReason {
+	reason := classifyError(err)
+	t[reason]++
+	return reason
+}
+
+// dominant returns the reason with the most recorded failures.
+// On a tie, last wins, so the most recent failure decides.
+// Other ties are broken in the order of failureReasons.
+func (t failureTally) dominant(last failureReason) failureReason {
+	dominant := last
+	for _, reason := range failureReasons {
+		if t[reason] > t[dominant] {
+			dominant = reason
+		}
+	}
+	return dominant
+}
+
+// abortError marks failures that will not resolve by waiting, so waiting is aborted immediately.
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string {
+	return e.err.Error()
+}
+
+func (e *abortError) Unwrap() error {
+	return e.err
+}
+
+// giveUpError is returned when waiting for a target is abandoned before it became ready.
+type giveUpError struct {
+	cause    error         // Why waiting was abandoned.
+	outcome  string        // The outcome matching the cause, e.g. outcomeTimeout once MAX_WAIT elapsed.
+	lastErr  error         // The error of the last failed attempt, if any.
+	reason   failureReason // The dominant failure reason across all attempts.
+	exitCode int           // The exit code the process should terminate with.
+}
+
+func (e *giveUpError) Error() string {
+	if e.lastErr == nil {
+		return e.cause.Error()
+	}
+	return fmt.Sprintf("%s (mostly %s failures, last error: %s)", e.cause, e.reason, e.lastErr)
+}
+
+func (e *giveUpError) Unwrap() []error {
+	if e.lastErr == nil {
+		return []error{e.cause}
+	}
+	return []error{e.cause, e.lastErr}
+}
+
+// ExitCode returns the exit code matching the dominant failure reason.
+func (e *giveUpError) ExitCode() int {
+	return e.exitCode
+}
+
+// exitCodeFor returns the configured exit code for the given failure reason.
+// Unset exit codes fall back to their defaults.
+func exitCodeFor(cfg Config, reason failureReason) int {
+	switch reason {
+	case reasonDNS:
+		if cfg.ExitCodeDNS != 0 {
+			return cfg.ExitCodeDNS
+		}
+		return defaultExitCodeDNS
+	case reasonConnection:
+		if cfg.ExitCodeConnection != 0 {
+			return cfg.ExitCodeConnection
+		}
+		return defaultExitCodeConnection
+	default:
+		return 1
+	}
+}
+
+// timeoutExitCode returns the exit code when giving up after MAX_WAIT.
+// If EXIT_CODE_TIMEOUT is unset, the given exit code of the failure reason is used.
+func timeoutExitCode(cfg Config, fallback int) int {
+	if cfg.ExitCodeTimeout != 0 {
+		return cfg.ExitCodeTimeout
+	}
+	return fallback
+}
+
+// configError marks errors in the configuration, which terminate the process with EXIT_CODE_CONFIG.
+type configError struct {
+	err      error
+	exitCode int
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the exit code configured for configuration errors.
+func (e *configError) ExitCode() int {
+	return e.exitCode
+}
+
+// configExitCode returns the exit code for configuration errors set in EXIT_CODE_CONFIG.
+// It is read on its own, as the rest of the configuration may not be parseable.
+func configExitCode(getenv func(string) string) int {
+	code, err := strconv.Atoi(getenv(envExitCodeConfig))
+	if err != nil || code < 1 || code > 255 {
+		return defaultExitCodeConfig
+	}
+	return code
+}
+
+// ExitCode returns the process exit code for an error returned by Run.
+func ExitCode(err error) int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
+
+// runError carries the log format resolved by Run, so the error terminating the process is reported in that format.
+type runError struct {
+	err       error
+	logFormat string
+}
+
+func (e *runError) Error() string {
+	return e.err.Error()
+}
+
+func (e *runError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,212 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Run("DNS error", func(t *testing.T) {
+		t.Parallel()
+
+		err := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "database", IsNotFound: true}}
+		if reason := classifyError(err); reason != reasonDNS {
+			t.Errorf("Expected reason %q but got %q", reasonDNS, reason)
+		}
+	})
+
+	t.Run("Connection error", func(t *testing.T) {
+		t.Parallel()
+
+		err := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+		if reason := classifyError(err); reason != reasonConnection {
+			t.Errorf("Expected reason %q but got %q", reasonConnection, reason)
+		}
+	})
+}
+
+func TestFailureTally(t *testing.T) {
+	t.Run("Most frequent reason wins", func(t *testing.T) {
+		t.Parallel()
+
+		tally := failureTally{}
+		tally.add(&net.DNSError{Err: "no such host"})
+		tally.add(&net.DNSError{Err: "no such host"})
+		last := tally.add(errors.New("connection refused"))
+
+		if reason := tally.dominant(last); reason != reasonDNS {
+			t.Errorf("Expected reason %q but got %q", reasonDNS, reason)
+		}
+	})
+
+	t.Run("Last reason wins on tie", func(t *testing.T) {
+		t.Parallel()
+
+		tally := failureTally{}
+		tally.add(&net.DNSError{Err: "no such host"})
+		last := tally.add(errors.New("connection refused"))
+
+		if reason := tally.dominant(last); reason != reasonConnection {
+			t.Errorf("Expected reason %q but got %q", reasonConnection, reason)
+		}
+	})
+
+	t.Run("Fixed order on tie without last reason", func(t *testing.T) {
+		t.Parallel()
+
+		for i := 0; i < 100; i++ {
+			tally := failureTally{}
+			tally.add(errors.New("connection refused"))
+			tally.add(errors.New("connection refused"))
+			tally.add(&net.DNSError{Err: "no such host"})
+			tally.add(&net.DNSError{Err: "no such host"})
+			last := tally.add(fmt.Errorf("login failed: %w", errAuth))
+
+			if reason := tally.dominant(last); reason != reasonDNS {
+				t.Fatalf("Expected reason %q but got %q", reasonDNS, reason)
+			}
+		}
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	t.Run("Give up due to DNS", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("wrapped: %w", &giveUpError{
+			cause:    context.DeadlineExceeded,
+			lastErr:  &net.DNSError{Err: "no such host"},
+			reason:   reasonDNS,
+			exitCode: exitCodeFor(Config{ExitCodeDNS: 42}, reasonDNS),
+		})
+
+		if code := ExitCode(err); code != 42 {
+			t.Errorf("Expected exit code %d but got %d", 42, code)
+		}
+	})
+
+	t.Run("Default exit codes", func(t *testing.T) {
+		t.Parallel()
+
+		if code := exitCodeFor(Config{}, reasonDNS); code != defaultExitCodeDNS {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeDNS, code)
+		}
+
+		if code := exitCodeFor(Config{}, reasonConnection); code != defaultExitCodeConnection {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+		}
+	})
+
+	t.Run("Unclassified error", func(t *testing.T) {
+		t.Parallel()
+
+		if code := ExitCode(errors.New("configuration error")); code != 1 {
+			t.Errorf("Expected exit code %d but got %d", 1, code)
+		}
+	})
+}
+
+func TestRunExitCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      func(t *testing.T) map[string]string
+		expected int
+		errorMsg string
+	}{
+		{
+			name:     "Invalid flag",
+			args:     []string{"-bogus"},
+			env:      func(t *testing.T) map[string]string { return map[string]string{"EXIT_CODE_CONFIG": "2"} },
+			expected: 2,
+			errorMsg: "configuration error: flag provided but not defined: -bogus",
+		},
+		{
+			name:     "Unexpected argument",
+			args:     []string{"foo"},
+			env:      func(t *testing.T) map[string]string { return map[string]string{} },
+			expected: 1,
+			errorMsg: `configuration error: unexpected argument "foo"`,
+		},
+		{
+			name:     "Validation error",
+			env:      func(t *testing.T) map[string]string { return map[string]string{} },
+			expected: 1,
+			errorMsg: "validation error: TARGET_ADDRESS environment variable is required",
+		},
+		{
+			name:     "Validation error with EXIT_CODE_CONFIG",
+			env:      func(t *testing.T) map[string]string { return map[string]string{"EXIT_CODE_CONFIG": "2"} },
+			expected: 2,
+			errorMsg: "validation error: TARGET_ADDRESS environment variable is required",
+		},
+		{
+			name: "Parse error with EXIT_CODE_CONFIG",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"EXIT_CODE_CONFIG": "2", "INTERVAL": "soon"}
+			},
+			expected: 2,
+			errorMsg: `configuration error: invalid INTERVAL value: time: invalid duration "soon"`,
+		},
+		{
+			name:     "Invalid EXIT_CODE_CONFIG",
+			env:      func(t *testing.T) map[string]string { return map[string]string{"EXIT_CODE_CONFIG": "0"} },
+			expected: 1,
+			errorMsg: "configuration error: invalid EXIT_CODE_CONFIG value: exit code must be between 1 and 255",
+		},
+		{
+			name: "MAX_WAIT with EXIT_CODE_TIMEOUT",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"TARGET_ADDRESS": closedAddress(t), "INTERVAL": "50ms", "MAX_WAIT": "200ms", "EXIT_CODE_TIMEOUT": "5"}
+			},
+			expected: 5,
+		},
+		{
+			name: "MAX_WAIT without EXIT_CODE_TIMEOUT",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"TARGET_ADDRESS": closedAddress(t), "INTERVAL": "50ms", "MAX_WAIT": "200ms"}
+			},
+			expected: defaultExitCodeConnection,
+		},
+		{
+			name: "MAX_WAIT during INITIAL_DELAY",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"TARGET_ADDRESS": closedAddress(t), "INITIAL_DELAY": "5s", "MAX_WAIT": "100ms", "EXIT_CODE_TIMEOUT": "7"}
+			},
+			expected: 7,
+			errorMsg: "not ready within MAX_WAIT of 100ms: context deadline exceeded",
+		},
+		{
+			name: "MAX_RETRIES ignores EXIT_CODE_TIMEOUT",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"TARGET_ADDRESS": closedAddress(t), "INTERVAL": "10ms", "MAX_RETRIES": "1", "EXIT_CODE_TIMEOUT": "5"}
+			},
+			expected: defaultExitCodeConnection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := tt.env(t)
+			err := Run(context.Background(), tt.args, func(key string) string { return env[key] }, io.Discard, io.Discard)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+
+			if tt.errorMsg != "" && err.Error() != tt.errorMsg {
+				t.Errorf("Expected error %q but got %q", tt.errorMsg, err.Error())
+			}
+			if code := ExitCode(err); code != tt.expected {
+				t.Errorf("Expected exit code %d but got %d", tt.expected, code)
+			}
+		})
+	}
+}
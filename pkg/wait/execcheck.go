@@ -0,0 +1,78 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const envExecCommand = "EXEC_COMMAND"
+
+const (
+	execEnvTargetName    = "TACO_TARGET_NAME"    // Passes the target name to the command.
+	execEnvTargetAddress = "TACO_TARGET_ADDRESS" // Passes the target address to the command.
+)
+
+// execWaitDelay bounds how long a killed command may keep its output open, e.g. through a background process.
+const execWaitDelay = time.Second
+
+func init() {
+	registerCheck(checkTypeExec, func(cfg Config, _ Dialer, logger *slog.Logger, _ captureFunc) checkFunc {
+		return newExecCheck(cfg, logger)
+	})
+}
+
+// newExecCheck returns a check running the command with a shell, which succeeds if the command exits with 0.
+// The target is passed to the command in environment variables, its stderr is logged at debug level.
+func newExecCheck(cfg Config, logger *slog.Logger) checkFunc {
+	env := append(os.Environ(),
+		execEnvTargetName+"="+cfg.TargetName,
+		execEnvTargetAddress+"="+cfg.TargetAddress,
+	)
+
+	return func(ctx context.Context) error {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cfg.ExecCommand)
+		cmd.Env = env
+		cmd.Stderr = &stderr
+		cmd.WaitDelay = execWaitDelay
+
+		err := cmd.Run()
+		if stderr.Len() > 0 {
+			logger.Debug(fmt.Sprintf("%s check command wrote to stderr", cfg.TargetName), slog.String("stderr", strings.TrimSpace(stderr.String())))
+		}
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("check command killed: %w", ctx.Err())
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("check command exited with code %d", exitErr.ExitCode())
+		}
+
+		return fmt.Errorf("check command failed: %w", err)
+	}
+}
+
+// validateExecCheck checks if the command check is configured.
+func validateExecCheck(cfg Config) error {
+	if !usesCheckType(cfg, checkTypeExec) {
+		return nil
+	}
+
+	if cfg.ExecCommand == "" {
+		return fmt.Errorf("%s is required for check type %q", envExecCommand, checkTypeExec)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		err     string
+		stderr  string
+	}{
+		{
+			name:    "Command succeeds",
+			command: `test "$TACO_TARGET_ADDRESS" = "localhost:5432" && test "$TACO_TARGET_NAME" = "database"`,
+		},
+		{
+			name:    "Command fails",
+			command: "echo 'connection refused' >&2; exit 3",
+			err:     "check command exited with code 3",
+			stderr:  `stderr="connection refused"`,
+		},
+		{
+			name:    "Command exceeds attempt timeout",
+			command: "sleep 5",
+			timeout: 50 * time.Millisecond,
+			err:     "check command killed: context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{
+				TargetName:    "database",
+				TargetAddress: "localhost:5432",
+				CheckType:     checkTypeExec,
+				ExecCommand:   tt.command,
+			}
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			check := withAttemptTimeout(tt.timeout, newTargetCheck(cfg, logger))
+
+			start := time.Now()
+			err := check(context.Background())
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %q", tt.err, err.Error())
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Expected the command to be killed but it ran for %s", elapsed)
+			}
+			if !strings.Contains(stdOut.String(), tt.stderr) {
+				t.Errorf("Expected output to contain %q but got %q", tt.stderr, stdOut.String())
+			}
+		})
+	}
+}
+
+func TestValidateExecCheck(t *testing.T) {
+	t.Parallel()
+
+	err := validateExecCheck(Config{CheckType: checkTypeExec, TargetAddress: "localhost:5432"})
+	if err == nil {
+		t.Fatal("Expected error but got none")
+	}
+
+	expected := `EXEC_COMMAND is required for check type "exec"`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q but got %q", expected, err.Error())
+	}
+}
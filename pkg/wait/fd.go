@@ -0,0 +1,123 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const envFDThreshold = "FD_THRESHOLD"
+
+// procRoot is the mount point of the proc filesystem.
+const procRoot = "/proc"
+
+func init() {
+	registerCheck(checkTypeFD, func(cfg Config, _ Dialer, _ *slog.Logger, _ captureFunc) checkFunc {
+		return func(ctx context.Context) error {
+			return checkFDs(procRoot, cfg.TargetAddress, cfg.FDThreshold)
+		}
+	})
+}
+
+// checkFDs checks if a local process has opened at least threshold file descriptors.
+// The target is either a PID or a process name; with a name, any matching process may reach the threshold.
+// Opening its sockets and files is only a heuristic for a process having completed its initialization.
+func checkFDs(root, target string, threshold int) error {
+	pids, err := findPIDs(root, target)
+	if err != nil {
+		return err
+	}
+	if len(pids) == 0 {
+		return fmt.Errorf("no process %q found", target)
+	}
+
+	most, mostPID := -1, 0
+	for _, pid := range pids {
+		count, err := countFDs(root, pid)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // the process exited in the meantime
+		}
+		if err != nil {
+			return err
+		}
+		if count >= threshold {
+			return nil
+		}
+		if count > most {
+			most, mostPID = count, pid
+		}
+	}
+
+	if most < 0 {
+		return fmt.Errorf("no process %q found", target)
+	}
+
+	return fmt.Errorf("process %d has %d open file descriptors, expected at least %d", mostPID, most, threshold)
+}
+
+// findPIDs returns the PID of the target if it is numeric, otherwise the PIDs of all processes named like the target.
+func findPIDs(root, target string) ([]int, error) {
+	if pid, err := strconv.Atoi(target); err == nil {
+		return []int{pid}, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		comm, err := os.ReadFile(filepath.Join(root, entry.Name(), "comm"))
+		if err != nil {
+			continue // the process exited in the meantime
+		}
+		if strings.TrimSpace(string(comm)) == target {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids, nil
+}
+
+// countFDs returns the number of open file descriptors of the process.
+// Lacking the permission to inspect the process aborts waiting, as it will not resolve by waiting.
+func countFDs(root string, pid int) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(root, strconv.Itoa(pid), "fd"))
+	if errors.Is(err, fs.ErrPermission) {
+		return 0, &abortError{err: fmt.Errorf("cannot inspect file descriptors of process %d, run as the same user or with CAP_SYS_PTRACE: %w", pid, err)}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// validateFDCheck checks if the file descriptor check can be used.
+func validateFDCheck(cfg Config) error {
+	if !usesCheckType(cfg, checkTypeFD) {
+		return nil
+	}
+
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("check type %q is only supported on Linux", checkTypeFD)
+	}
+
+	if cfg.FDThreshold < 1 {
+		return fmt.Errorf("%s must be at least 1 for check type %q", envFDThreshold, checkTypeFD)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package wait
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newProcRoot creates a fake proc filesystem with processes of the given names and file descriptor counts.
+func newProcRoot(t *testing.T, processes map[int]struct {
+	name string
+	fds  int
+}) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for pid, process := range processes {
+		dir := filepath.Join(root, strconv.Itoa(pid))
+		if err := os.MkdirAll(filepath.Join(dir, "fd"), 0o755); err != nil {
+			t.Fatalf("Failed to create process: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "comm"), []byte(process.name+"\n"), 0o644); err != nil {
+			t.Fatalf("Failed to create process: %v", err)
+		}
+		for fd := 0; fd < process.fds; fd++ {
+			if err := os.WriteFile(filepath.Join(dir, "fd", strconv.Itoa(fd)), nil, 0o644); err != nil {
+				t.Fatalf("Failed to create file descriptor: %v", err)
+			}
+		}
+	}
+
+	return root
+}
+
+func TestCheckFDs(t *testing.T) {
+	root := newProcRoot(t, map[int]struct {
+		name string
+		fds  int
+	}{
+		100: {"postgres", 3},
+		101: {"postgres", 12},
+		200: {"valkey", 4},
+	})
+
+	tests := []struct {
+		name      string
+		target    string
+		threshold int
+		err       string
+	}{
+		{name: "Name reaches threshold", target: "postgres", threshold: 10},
+		{name: "PID reaches threshold", target: "200", threshold: 4},
+		{name: "Below threshold", target: "valkey", threshold: 5, err: "process 200 has 4 open file descriptors, expected at least 5"},
+		{name: "Most file descriptors reported", target: "postgres", threshold: 20, err: "process 101 has 12 open file descriptors, expected at least 20"},
+		{name: "Unknown name", target: "mysql", threshold: 1, err: `no process "mysql" found`},
+		{name: "Unknown PID", target: "300", threshold: 1, err: `no process "300" found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkFDs(root, tt.target, tt.threshold)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+
+	t.Run("Permission denied", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("permissions are not enforced for root")
+		}
+
+		fdDir := filepath.Join(root, "100", "fd")
+		if err := os.Chmod(fdDir, 0); err != nil {
+			t.Fatalf("Failed to change permissions: %v", err)
+		}
+		t.Cleanup(func() { _ = os.Chmod(fdDir, 0o755) })
+
+		var abortErr *abortError
+		if err := checkFDs(root, "100", 1); !errors.As(err, &abortErr) {
+			t.Errorf("Expected abort error but got %v", err)
+		}
+	})
+}
+
+func TestValidateFDCheck(t *testing.T) {
+	t.Parallel()
+
+	err := validateFDCheck(Config{CheckType: checkTypeFD, TargetAddress: "postgres"})
+
+	expected := `FD_THRESHOLD must be at least 1 for check type "fd"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
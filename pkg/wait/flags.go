@@ -0,0 +1,217 @@
+package wait
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// flagEnvs lists the environment variables that can also be set with a command-line flag.
+// Indexed targets have no flags, multiple targets are set with a comma-separated -target-address instead.
+var flagEnvs = []string{
+	envEnvFile,
+	envConfigFile,
+	envTargetName,
+	envTargetAddress,
+	envTargetAddressFile,
+	envTargets,
+	envCheckType,
+	envWaitFor,
+	envInterval,
+	envDialTimeout,
+	envAttemptTimeout,
+	envBackoff,
+	envBackoffMax,
+	envJitter,
+	envFastInterval,
+	envFastDuration,
+	envMaxWait,
+	envMaxRetries,
+	envOneShot,
+	envMonitor,
+	envMaxDNSAttempts,
+	envFailFastOnTimeout,
+	envInitialDelay,
+	envSettleAfter,
+	envSuccessThreshold,
+	envStabilizeFor,
+	envSkipIfUnset,
+	envLogFormat,
+	envColor,
+	envLogLevel,
+	envLogAttemptLevel,
+	envLogFile,
+	envLogTimeFormat,
+	envLogUTC,
+	envLogExtraFields,
+	envLogOutcome,
+	envOnLogError,
+	envSplitStreams,
+	envDedupLogs,
+	envMetricsAddr,
+	envOTLPEndpoint,
+	envStartupMatrix,
+	envMsgReady,
+	envMsgNotReady,
+	envStartupMessageMode,
+	envQuietStartup,
+	envMaxSpread,
+	envMatch,
+	envQuorum,
+	envMaxConcurrency,
+	envConcurrency,
+	envConcurrencyRamp,
+	envWindowSize,
+	envWindowSuccesses,
+	envProtocol,
+	envUDPAllowEmpty,
+	envUDPAllowSilent,
+	envProbeSend,
+	envProbeExpect,
+	envProbeEncoding,
+	envSendData,
+	envExpectBanner,
+	envConcurrentConns,
+	envFDThreshold,
+	envExecCommand,
+	envExpectedIPs,
+	envLogCNAMEChain,
+	envLogTCPMSS,
+	envRequireDualStack,
+	envVerifyWritable,
+	envProxyAddress,
+	envHTTPProxyAddress,
+	envSendProxyHeader,
+	envCloseReset,
+	envTLSMinVersion,
+	envTLSHandshakeRetries,
+	envTLSServerName,
+	envTLSInsecureSkipVerify,
+	envClockSkew,
+	envExpectCertChange,
+	envExpectedCertFingerprint,
+	envHTTPTrace,
+	envExpectedStatus,
+	envFatalStatus,
+	envExpectedBody,
+	envHTTPMethod,
+	envHTTPHeaders,
+	envStableBodyAttempts,
+	envCaptureResponseFile,
+	envStatusFile,
+	envGRPCService,
+	envGRPCTLS,
+	envGRPCReflectionFallback,
+	envGRPCReadyEndpoint,
+	envGRPCReadyMethod,
+	envNotifySocket,
+	envS3Bucket,
+	envS3Region,
+	envS3AccessKeyID,
+	envS3SecretAccessKey,
+	envS3SessionToken,
+	envReverseCheckListen,
+	envReverseCheckAddress,
+	envReverseCheckTimeout,
+	envOnReadyExec,
+	envOnReadyExecRetries,
+	envOnReadyExecRetryInterval,
+	envOnReadyExecRequired,
+	envOnReadyWebhook,
+	envExitCodeConfig,
+	envExitCodeTimeout,
+	envExitCodeDNS,
+	envExitCodeConnection,
+}
+
+// boolFlagEnvs lists the environment variables whose flags may be passed without a value to enable them.
+var boolFlagEnvs = map[string]bool{
+	envOneShot:                true,
+	envMonitor:                true,
+	envLogUTC:                 true,
+	envLogExtraFields:         true,
+	envLogOutcome:             true,
+	envSplitStreams:           true,
+	envDedupLogs:              true,
+	envStartupMatrix:          true,
+	envQuietStartup:           true,
+	envUDPAllowEmpty:          true,
+	envUDPAllowSilent:         true,
+	envLogCNAMEChain:          true,
+	envLogTCPMSS:              true,
+	envRequireDualStack:       true,
+	envVerifyWritable:         true,
+	envTLSInsecureSkipVerify:  true,
+	envExpectCertChange:       true,
+	envHTTPTrace:              true,
+	envGRPCTLS:                true,
+	envGRPCReflectionFallback: true,
+	envSendProxyHeader:        true,
+	envCloseReset:             true,
+	envOnReadyExecRequired:    true,
+}
+
+// flagValue holds the raw value of a flag, it is parsed like the environment variable it overrides.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// flagName returns the flag name of an environment variable, e.g. -target-address for TARGET_ADDRESS.
+func flagName(env string) string {
+	return strings.ToLower(strings.ReplaceAll(env, "_", "-"))
+}
+
+// withFlags parses the command-line arguments and returns a getenv that prefers the values of set flags.
+// Environment variables remain the fallback for flags that are not set.
+func withFlags(args []string, getenv func(string) string, output io.Writer) (func(string) string, error) {
+	fs := flag.NewFlagSet("taco", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: taco [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Every flag overrides the environment variable of the same name, e.g. -%s overrides %s.\n\n", flagName(envTargetAddress), envTargetAddress)
+		fs.PrintDefaults()
+	}
+
+	values := make(map[string]*flagValue, len(flagEnvs))
+	for _, env := range flagEnvs {
+		values[env] = &flagValue{isBool: boolFlagEnvs[env]}
+		fs.Var(values[env], flagName(env), fmt.Sprintf("overrides %s", env))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))] = f.Value.String()
+	})
+
+	return func(key string) string {
+		if value, ok := set[key]; ok {
+			return value
+		}
+		return getenv(key)
+	}, nil
+}
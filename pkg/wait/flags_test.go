@@ -0,0 +1,111 @@
+package wait
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithFlags(t *testing.T) {
+	t.Run("Flags override environment variables", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": "localhost:5432",
+			"INTERVAL":       "5s",
+		}
+
+		args := []string{"-interval", "500ms", "-dial-timeout=2s", "-http-trace"}
+		getenv, err := withFlags(args, func(key string) string { return env[key] }, io.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		cfg, err := ParseConfig(getenv)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.TargetAddress != "localhost:5432" {
+			t.Errorf("Expected target address %q but got %q", "localhost:5432", cfg.TargetAddress)
+		}
+		if cfg.Interval != 500*time.Millisecond {
+			t.Errorf("Expected interval %s but got %s", 500*time.Millisecond, cfg.Interval)
+		}
+		if cfg.DialTimeout != 2*time.Second {
+			t.Errorf("Expected dial timeout %s but got %s", 2*time.Second, cfg.DialTimeout)
+		}
+		if !cfg.HTTPTrace {
+			t.Error("Expected HTTP trace to be enabled")
+		}
+		if cfg.LogFormat != logFormatText {
+			t.Errorf("Expected default log format %q but got %q", logFormatText, cfg.LogFormat)
+		}
+	})
+
+	t.Run("Invalid flag value", func(t *testing.T) {
+		t.Parallel()
+
+		getenv, err := withFlags([]string{"-target-address", "localhost:5432", "-interval", "soon"}, func(string) string { return "" }, io.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = ParseConfig(getenv)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid INTERVAL value: time: invalid duration \"soon\""
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unknown flag", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := withFlags([]string{"-target-port", "5432"}, func(string) string { return "" }, io.Discard)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "flag provided but not defined: -target-port"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unexpected argument", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := withFlags([]string{"-interval", "1s", "localhost:5432"}, func(string) string { return "" }, io.Discard)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "unexpected argument \"localhost:5432\""
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		_, err := withFlags([]string{"-h"}, func(string) string { return "" }, &stdOut)
+		if !errors.Is(err, flag.ErrHelp) {
+			t.Fatalf("Expected %v but got %v", flag.ErrHelp, err)
+		}
+
+		expected := "overrides TARGET_ADDRESS"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	envGRPCReadyEndpoint = "GRPC_READY_ENDPOINT"
+	envGRPCReadyMethod   = "GRPC_READY_METHOD"
+)
+
+// grpcReadyTimeout bounds the call reporting readiness, so an unresponsive control plane does not delay exiting.
+const grpcReadyTimeout = 5 * time.Second
+
+// newGRPCClient returns a client speaking HTTP/2, which gRPC requires.
+func newGRPCClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2: true,
+		},
+		Timeout: grpcReadyTimeout,
+	}
+}
+
+// reportReady calls the configured gRPC method to report the ready targets.
+// A failing call is logged as a warning, as the targets are ready regardless.
+func reportReady(ctx context.Context, cfg Config, client *http.Client, logger *slog.Logger) {
+	var names []string
+	for _, targetCfg := range targetConfigs(cfg) {
+		names = append(names, targetCfg.TargetName)
+	}
+
+	if err := callGRPC(ctx, client, cfg.GRPCReadyEndpoint, cfg.GRPCReadyMethod, encodeReadyRequest(names)); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to report readiness via %s", cfg.GRPCReadyMethod), slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Reported readiness via %s", cfg.GRPCReadyMethod))
+}
+
+// encodeReadyRequest encodes the protobuf message `message ReportReadyRequest { string targets = 1; }`
+// with the comma-separated names of the ready targets.
+func encodeReadyRequest(names []string) []byte {
+	return appendProtoString(nil, 1, strings.Join(names, ","))
+}
+
+// callGRPC performs a unary gRPC call and returns an error unless the call succeeded.
+// The response message is ignored.
+func callGRPC(ctx context.Context, client *http.Client, endpoint, method string, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+method, bytes.NewReader(grpcMessage(msg)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		return fmt.Errorf("endpoint responded with %s instead of HTTP/2", resp.Proto)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	// the status is sent in the trailers, which are only available after reading the body
+	if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize)); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status") // trailers-only response
+	}
+
+	switch status {
+	case "0":
+		return nil
+	case "":
+		return errors.New("response has no grpc-status")
+	default:
+		message, _ := url.PathUnescape(resp.Trailer.Get("Grpc-Message") + resp.Header.Get("Grpc-Message"))
+		return fmt.Errorf("grpc-status %s: %s", status, message)
+	}
+}
+
+// validateGRPCReady checks if the gRPC readiness report settings are valid.
+func validateGRPCReady(cfg Config) error {
+	if cfg.GRPCReadyEndpoint == "" && cfg.GRPCReadyMethod == "" {
+		return nil
+	}
+
+	if cfg.GRPCReadyEndpoint == "" || cfg.GRPCReadyMethod == "" {
+		return fmt.Errorf("%s and %s must be set together", envGRPCReadyEndpoint, envGRPCReadyMethod)
+	}
+
+	u, err := url.Parse(cfg.GRPCReadyEndpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		// plaintext HTTP/2 (h2c) is not supported by net/http, so the endpoint must use TLS
+		return fmt.Errorf("invalid %s value: must be an https URL", envGRPCReadyEndpoint)
+	}
+
+	parts := strings.Split(cfg.GRPCReadyMethod, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid %s value: must be in the format /package.Service/Method", envGRPCReadyMethod)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newGRPCServer starts an HTTP/2 server answering every call with the given gRPC status
+// and returns its URL, a client trusting it, and the channel receiving the request messages.
+func newGRPCServer(t *testing.T, status, message string) (string, *http.Client, <-chan []byte) {
+	t.Helper()
+
+	requests := make(chan []byte, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) >= 5 && int(binary.BigEndian.Uint32(body[1:5])) == len(body)-5 {
+			requests <- body[5:]
+		}
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0}) // empty response message
+		w.Header().Set("Grpc-Status", status)
+		w.Header().Set("Grpc-Message", message)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.URL, server.Client(), requests
+}
+
+func TestReportReady(t *testing.T) {
+	t.Run("Successful report", func(t *testing.T) {
+		t.Parallel()
+
+		endpoint, client, requests := newGRPCServer(t, "0", "")
+		cfg := Config{
+			Targets:           []Target{{Name: "postgres"}, {Name: "redis"}},
+			GRPCReadyEndpoint: endpoint,
+			GRPCReadyMethod:   "/controlplane.v1.Readiness/ReportReady",
+		}
+
+		var output bytes.Buffer
+		reportReady(context.Background(), cfg, client, slog.New(slog.NewTextHandler(&output, nil)))
+
+		expected := "Reported readiness via /controlplane.v1.Readiness/ReportReady"
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+
+		if msg := <-requests; !bytes.Equal(msg, encodeReadyRequest([]string{"postgres", "redis"})) {
+			t.Errorf("Unexpected request message %q", msg)
+		}
+	})
+
+	t.Run("Failed call", func(t *testing.T) {
+		t.Parallel()
+
+		endpoint, client, _ := newGRPCServer(t, "12", "unknown method ReportReady")
+		cfg := Config{
+			TargetName:        "postgres",
+			GRPCReadyEndpoint: endpoint,
+			GRPCReadyMethod:   "/controlplane.v1.Readiness/ReportReady",
+		}
+
+		var output bytes.Buffer
+		reportReady(context.Background(), cfg, client, slog.New(slog.NewTextHandler(&output, nil)))
+
+		expected := `level=WARN msg="Failed to report readiness via /controlplane.v1.Readiness/ReportReady" error="grpc-status 12: unknown method ReportReady"`
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+}
+
+func TestEncodeReadyRequest(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte("\x0a\x0epostgres,redis")
+	if msg := encodeReadyRequest([]string{"postgres", "redis"}); !bytes.Equal(msg, expected) {
+		t.Errorf("Expected message %q but got %q", expected, msg)
+	}
+}
+
+func TestValidateGRPCReady(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{}},
+		{name: "Valid", cfg: Config{GRPCReadyEndpoint: "https://controller:8443", GRPCReadyMethod: "/controlplane.v1.Readiness/ReportReady"}},
+		{name: "Missing method", cfg: Config{GRPCReadyEndpoint: "https://controller:8443"}, err: "GRPC_READY_ENDPOINT and GRPC_READY_METHOD must be set together"},
+		{name: "Plaintext endpoint", cfg: Config{GRPCReadyEndpoint: "http://controller:8080", GRPCReadyMethod: "/controlplane.v1.Readiness/ReportReady"}, err: "invalid GRPC_READY_ENDPOINT value: must be an https URL"},
+		{name: "Invalid method", cfg: Config{GRPCReadyEndpoint: "https://controller:8443", GRPCReadyMethod: "ReportReady"}, err: "invalid GRPC_READY_METHOD value: must be in the format /package.Service/Method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateGRPCReady(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
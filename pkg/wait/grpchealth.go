@@ -0,0 +1,286 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	envGRPCService = "GRPC_SERVICE"
+	envGRPCTLS     = "GRPC_TLS"
+)
+
+const grpcHealthCheckMethod = "/grpc.health.v1.Health/Check"
+
+// grpcServingStatuses maps the statuses of grpc.health.v1.HealthCheckResponse to their names.
+var grpcServingStatuses = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+const grpcServing = 1
+
+// grpcStatusCodes maps the gRPC status codes to their names.
+var grpcStatusCodes = map[uint64]string{
+	0:  "OK",
+	1:  "CANCELLED",
+	2:  "UNKNOWN",
+	3:  "INVALID_ARGUMENT",
+	4:  "DEADLINE_EXCEEDED",
+	5:  "NOT_FOUND",
+	6:  "ALREADY_EXISTS",
+	7:  "PERMISSION_DENIED",
+	8:  "RESOURCE_EXHAUSTED",
+	9:  "FAILED_PRECONDITION",
+	10: "ABORTED",
+	11: "OUT_OF_RANGE",
+	12: "UNIMPLEMENTED",
+	13: "INTERNAL",
+	14: "UNAVAILABLE",
+	15: "DATA_LOSS",
+	16: "UNAUTHENTICATED",
+}
+
+const grpcUnimplemented = 12
+
+// grpcStatusError is returned for calls answered with an error status.
+type grpcStatusError struct {
+	code    uint64
+	message string
+}
+
+func (e *grpcStatusError) Error() string {
+	name, ok := grpcStatusCodes[e.code]
+	if !ok {
+		name = fmt.Sprintf("status %d", e.code)
+	}
+	if e.message == "" {
+		return fmt.Sprintf("server responded with %s", name)
+	}
+	return fmt.Sprintf("server responded with %s: %s", name, e.message)
+}
+
+// isGRPCUnimplemented reports whether the call was answered with UNIMPLEMENTED, i.e. the method is not registered.
+func isGRPCUnimplemented(err error) bool {
+	var statusErr *grpcStatusError
+	return errors.As(err, &statusErr) && statusErr.code == grpcUnimplemented
+}
+
+// checkGRPCHealth calls grpc.health.v1.Health/Check for the service and returns an error unless it is SERVING.
+func checkGRPCHealth(ctx context.Context, dialer Dialer, address, service string, tlsConfig *tls.Config) error {
+	response, err := callGRPCMethod(ctx, dialer, address, tlsConfig, grpcHealthCheckMethod, appendProtoString(nil, 1, service))
+	if isGRPCUnimplemented(err) {
+		return fmt.Errorf("%w, grpc.health.v1.Health is not implemented", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := parseHealthCheckResponse(response)
+	if err != nil {
+		return err
+	}
+	if status != grpcServing {
+		name, ok := grpcServingStatuses[status]
+		if !ok {
+			name = fmt.Sprintf("status %d", status)
+		}
+		return fmt.Errorf("health status is %s", name)
+	}
+
+	return nil
+}
+
+// callGRPCMethod performs a unary gRPC call on a new connection and returns the gRPC framed response message.
+// The connection is dialed through the dialer, so plaintext servers (h2c) are reached via proxies like any other target.
+func callGRPCMethod(ctx context.Context, dialer Dialer, address string, tlsConfig *tls.Config, method string, msg []byte) ([]byte, error) {
+	scheme := "http"
+	if tlsConfig != nil {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.NextProtos = []string{"h2"}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		scheme = "https"
+	}
+
+	// DIAL_TIMEOUT bounds the whole call, so an unresponsive server cannot block it
+	if timeout := dialTimeout(dialer); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	transport := &http2.Transport{
+		AllowHTTP: true, // the http scheme speaks HTTP/2 without TLS
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			if tlsConfig == nil {
+				return conn, nil
+			}
+
+			tlsConn := tls.Client(conn, tlsConfig)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			if protocol := tlsConn.ConnectionState().NegotiatedProtocol; protocol != "h2" {
+				tlsConn.Close()
+				return nil, fmt.Errorf("server does not support HTTP/2, negotiated protocol %q", protocol)
+			}
+			return tlsConn, nil
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+"://"+address+method, bytes.NewReader(grpcMessage(msg)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of %s: %w", method, err)
+	}
+	if len(response) > maxBodySize {
+		return nil, fmt.Errorf("response of %s too large", method)
+	}
+
+	// a trailers-only response carries the gRPC status in its headers
+	headers := map[string]string{":status": strconv.Itoa(resp.StatusCode)}
+	for _, header := range []http.Header{resp.Header, resp.Trailer} {
+		for _, name := range []string{"grpc-status", "grpc-message"} {
+			if value := header.Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+	}
+
+	return grpcResponse(method, headers, response)
+}
+
+// grpcResponse returns the response body of a completed call, or the error status of its headers or trailers.
+func grpcResponse(path string, headers map[string]string, response []byte) ([]byte, error) {
+	value, ok := headers["grpc-status"]
+	if !ok {
+		if status := headers[":status"]; status != "200" {
+			return nil, fmt.Errorf("server responded to %s with HTTP status %s", path, status)
+		}
+		return nil, fmt.Errorf("server responded to %s without a gRPC status", path)
+	}
+
+	code, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("server responded to %s with invalid gRPC status %q", path, value)
+	}
+	if code != 0 {
+		message, err := url.PathUnescape(headers["grpc-message"])
+		if err != nil {
+			message = headers["grpc-message"]
+		}
+		return nil, &grpcStatusError{code: code, message: message}
+	}
+	if len(response) == 0 {
+		return nil, fmt.Errorf("server responded to %s without a message", path)
+	}
+
+	return response, nil
+}
+
+// grpcMessage prefixes a message with its compression flag and length.
+func grpcMessage(msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// appendProtoString appends a protobuf string field.
+func appendProtoString(b []byte, field int, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2) // wire type length-delimited
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// parseHealthCheckResponse returns the status of a gRPC framed grpc.health.v1.HealthCheckResponse.
+func parseHealthCheckResponse(response []byte) (uint64, error) {
+	if len(response) < 5 {
+		return 0, errors.New("truncated health check response")
+	}
+	if response[0] != 0 {
+		return 0, errors.New("compressed health check responses are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(response[1:5])
+	msg := response[5:]
+	if uint64(len(msg)) < uint64(length) {
+		return 0, errors.New("truncated health check response")
+	}
+	msg = msg[:length]
+
+	// the status defaults to UNKNOWN if the field is missing
+	var status uint64
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return 0, errors.New("invalid health check response")
+		}
+		msg = msg[n:]
+
+		switch key & 7 {
+		case 0: // varint
+			value, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return 0, errors.New("invalid health check response")
+			}
+			msg = msg[n:]
+			if key>>3 == 1 {
+				status = value
+			}
+		case 2: // length-delimited
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return 0, errors.New("invalid health check response")
+			}
+			msg = msg[n+int(size):]
+		default:
+			return 0, errors.New("invalid health check response")
+		}
+	}
+
+	return status, nil
+}
+
+// validateGRPCCheck checks if the gRPC health check settings are valid.
+func validateGRPCCheck(cfg Config) error {
+	if (cfg.GRPCService != "" || cfg.GRPCTLS) && !usesCheckType(cfg, checkTypeGRPC) {
+		return fmt.Errorf("%s and %s can only be used with check type %q", envGRPCService, envGRPCTLS, checkTypeGRPC)
+	}
+	if cfg.GRPCReflectionFallback && !usesCheckType(cfg, checkTypeGRPC) {
+		return fmt.Errorf("%s can only be used with check type %q", envGRPCReflectionFallback, checkTypeGRPC)
+	}
+	return nil
+}
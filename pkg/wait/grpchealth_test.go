@@ -0,0 +1,284 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// healthResponse returns a gRPC framed grpc.health.v1.HealthCheckResponse with the given status.
+func healthResponse(status byte) []byte {
+	return grpcMessage([]byte{1 << 3, status})
+}
+
+// newH2CServer starts a plaintext HTTP/2 server answering the first call with the given response body,
+// or with a trailers-only UNIMPLEMENTED response if the body is nil. It returns the address and the received request body.
+func newH2CServer(t *testing.T, body []byte) (string, <-chan []byte) {
+	t.Helper()
+
+	return newH2CStatusServer(t, body, "12")
+}
+
+// newH2CStatusServer starts a plaintext HTTP/2 server like newH2CServer,
+// answering with a trailers-only response with the given gRPC status if the body is nil.
+func newH2CStatusServer(t *testing.T, body []byte, status string) (string, <-chan []byte) {
+	t.Helper()
+
+	lis := newListener(t)
+	requests := make(chan []byte, 1)
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serveH2C(conn, body, status, requests)
+	}()
+
+	return lis.Addr().String(), requests
+}
+
+// serveH2C answers the first call on the connection with the given response body,
+// or with a trailers-only response with the given gRPC status if the body is nil, and sends the received request body to requests.
+func serveH2C(conn net.Conn, body []byte, status string, requests chan<- []byte) {
+	var once sync.Once
+	server := &http2.Server{}
+	server.ServeConn(conn, &http2.ServeConnOpts{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, _ := io.ReadAll(r.Body)
+		once.Do(func() { requests <- request })
+
+		w.Header().Set("Content-Type", "application/grpc")
+		if body == nil {
+			w.Header().Set("Grpc-Status", status)
+			w.Header().Set("Grpc-Message", "status%20"+status)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write(body)
+		w.Header().Set("Grpc-Status", "0")
+	})})
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	dialer := &net.Dialer{Timeout: time.Second}
+
+	t.Run("Serving", func(t *testing.T) {
+		t.Parallel()
+
+		address, requests := newH2CServer(t, healthResponse(1))
+
+		if err := checkGRPCHealth(context.Background(), dialer, address, "payments", nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if request := <-requests; !bytes.Equal(request, grpcMessage([]byte("\x0a\x08payments"))) {
+			t.Errorf("Unexpected request %q", request)
+		}
+	})
+
+	t.Run("Not serving", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newH2CServer(t, healthResponse(2))
+
+		err := checkGRPCHealth(context.Background(), dialer, address, "", nil)
+		expected := "health status is NOT_SERVING"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Default status", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newH2CServer(t, grpcMessage(nil))
+
+		err := checkGRPCHealth(context.Background(), dialer, address, "", nil)
+		expected := "health status is UNKNOWN"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Health service not implemented", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newH2CServer(t, nil)
+
+		err := checkGRPCHealth(context.Background(), dialer, address, "", nil)
+		expected := "server responded with UNIMPLEMENTED: status 12, grpc.health.v1.Health is not implemented"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Unknown service", func(t *testing.T) {
+		t.Parallel()
+
+		address, _ := newH2CStatusServer(t, nil, "5")
+
+		err := checkGRPCHealth(context.Background(), dialer, address, "payments", nil)
+		expected := "server responded with NOT_FOUND: status 5"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Unresponsive server", func(t *testing.T) {
+		t.Parallel()
+
+		start := time.Now()
+		err := checkGRPCHealth(context.Background(), &net.Dialer{Timeout: 100 * time.Millisecond}, newHungServer(t), "", nil)
+		if err == nil {
+			t.Error("Expected error but got nil")
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the check to time out but it took %s", elapsed)
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != grpcHealthCheckMethod || r.ProtoMajor != 2 {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Trailer", "Grpc-Status")
+			_, _ = w.Write(healthResponse(1))
+			w.Header().Set("Grpc-Status", "0")
+		}))
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		t.Cleanup(server.Close)
+
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		if err := checkGRPCHealth(context.Background(), dialer, server.Listener.Addr().String(), "", &tls.Config{RootCAs: pool}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("TLS trailers-only", func(t *testing.T) {
+		t.Parallel()
+
+		// net/http encodes the headers with Huffman coding and the dynamic table, like gRPC servers do
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", "14")
+			w.Header().Set("Grpc-Message", "shutting%20down")
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		t.Cleanup(server.Close)
+
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		err := checkGRPCHealth(context.Background(), dialer, server.Listener.Addr().String(), "", &tls.Config{RootCAs: pool})
+		expected := "server responded with UNAVAILABLE: shutting down"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestGRPCResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		response []byte
+		err      string
+	}{
+		{name: "OK", headers: map[string]string{":status": "200", "grpc-status": "0"}, response: healthResponse(1)},
+		{name: "Error status", headers: map[string]string{":status": "200", "grpc-status": "7"}, err: "server responded with PERMISSION_DENIED"},
+		{name: "Unknown status", headers: map[string]string{":status": "200", "grpc-status": "42"}, err: "server responded with status 42"},
+		{name: "Invalid status", headers: map[string]string{":status": "200", "grpc-status": "ok"}, err: `server responded to /svc/Method with invalid gRPC status "ok"`},
+		{name: "Missing status", headers: map[string]string{":status": "200"}, response: healthResponse(1), err: "server responded to /svc/Method without a gRPC status"},
+		{name: "HTTP error", headers: map[string]string{":status": "404"}, err: "server responded to /svc/Method with HTTP status 404"},
+		{name: "No message", headers: map[string]string{":status": "200", "grpc-status": "0"}, err: "server responded to /svc/Method without a message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response, err := grpcResponse("/svc/Method", tt.headers, tt.response)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(response, tt.response) {
+				t.Errorf("Expected response %q but got %q", tt.response, response)
+			}
+		})
+	}
+}
+
+func TestParseHealthCheckResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response []byte
+		status   uint64
+		err      string
+	}{
+		{name: "Serving", response: healthResponse(1), status: 1},
+		{name: "Unknown fields", response: grpcMessage([]byte("\x12\x02ok\x08\x02")), status: 2},
+		{name: "Truncated", response: []byte{0, 0, 0}, err: "truncated health check response"},
+		{name: "Compressed", response: []byte{1, 0, 0, 0, 0}, err: "compressed health check responses are not supported"},
+		{name: "Invalid", response: grpcMessage([]byte{1<<3 | 5}), err: "invalid health check response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := parseHealthCheckResponse(tt.response)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if status != tt.status {
+				t.Errorf("Expected status %d but got %d", tt.status, status)
+			}
+		})
+	}
+}
+
+func TestValidateGRPCCheck(t *testing.T) {
+	t.Parallel()
+
+	err := validateGRPCCheck(Config{CheckType: checkTypeTCP, GRPCService: "payments"})
+
+	expected := `GRPC_SERVICE and GRPC_TLS can only be used with check type "grpc"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
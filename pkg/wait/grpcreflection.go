@@ -0,0 +1,77 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+)
+
+const envGRPCReflectionFallback = "GRPC_REFLECTION_FALLBACK"
+
+// grpcReflectionMethods are the versions of the server reflection service, tried in order.
+// Many servers still only register the v1alpha version.
+var grpcReflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// grpcListServicesField is the list_services field of grpc.reflection.v1.ServerReflectionRequest.
+const grpcListServicesField = 7
+
+func init() {
+	registerCheck(checkTypeGRPC, func(cfg Config, dialer Dialer, logger *slog.Logger, _ captureFunc) checkFunc {
+		var tlsConfig *tls.Config
+		if cfg.GRPCTLS {
+			tlsConfig = newTLSConfig(cfg)
+		}
+		return newGRPCCheck(cfg, dialer, tlsConfig, logger)
+	})
+}
+
+// newGRPCCheck returns the gRPC health check of the target.
+// With GRPC_REFLECTION_FALLBACK set, a server answering the health check with UNIMPLEMENTED is ready once it answers a reflection request,
+// and the method that determined readiness is logged. Any other error status fails the check.
+func newGRPCCheck(cfg Config, dialer Dialer, tlsConfig *tls.Config, logger *slog.Logger) checkFunc {
+	if !cfg.GRPCReflectionFallback {
+		return func(ctx context.Context) error {
+			return checkGRPCHealth(ctx, dialer, cfg.TargetAddress, cfg.GRPCService, tlsConfig)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		err := checkGRPCHealth(ctx, dialer, cfg.TargetAddress, cfg.GRPCService, tlsConfig)
+		if err == nil {
+			logger.Info(fmt.Sprintf("%s readiness determined via %s", cfg.TargetName, grpcHealthCheckMethod))
+			return nil
+		}
+		if !isGRPCUnimplemented(err) {
+			return err // e.g. NOT_FOUND for an unknown GRPC_SERVICE, the server does not serve it
+		}
+
+		method, err := checkGRPCReflection(ctx, dialer, cfg.TargetAddress, tlsConfig)
+		if err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("%s readiness determined via %s, as grpc.health.v1.Health is not implemented", cfg.TargetName, method))
+		return nil
+	}
+}
+
+// checkGRPCReflection asks the server to list its services via server reflection and returns the method that answered.
+// Any answer counts, as it shows that the server handles calls.
+func checkGRPCReflection(ctx context.Context, dialer Dialer, address string, tlsConfig *tls.Config) (string, error) {
+	request := appendProtoString(nil, grpcListServicesField, "")
+	var err error
+	for _, method := range grpcReflectionMethods {
+		_, err = callGRPCMethod(ctx, dialer, address, tlsConfig, method, request)
+		if isGRPCUnimplemented(err) {
+			continue // try the next version
+		}
+		if err != nil {
+			return "", err
+		}
+		return method, nil
+	}
+	return "", fmt.Errorf("%w, neither grpc.health.v1.Health nor server reflection are implemented", err)
+}
@@ -0,0 +1,153 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newH2CServerSequence starts a plaintext HTTP/2 server answering the call on each new connection with the next body,
+// or with a trailers-only UNIMPLEMENTED response if the body is nil. It returns the address and the received request bodies.
+func newH2CServerSequence(t *testing.T, bodies ...[]byte) (string, <-chan []byte) {
+	t.Helper()
+
+	lis := newListener(t)
+	requests := make(chan []byte, len(bodies))
+
+	go func() {
+		for _, body := range bodies {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serveH2C(conn, body, "12", requests)
+			}()
+		}
+	}()
+
+	return lis.Addr().String(), requests
+}
+
+func TestGRPCReflectionFallback(t *testing.T) {
+	reflectionResponse := grpcMessage([]byte("\x32\x00")) // an empty list_services_response
+
+	tests := []struct {
+		name     string
+		fallback bool
+		status   string
+		bodies   [][]byte
+		expected string
+		err      string
+	}{
+		{
+			name:     "Health service",
+			fallback: true,
+			bodies:   [][]byte{healthResponse(1)},
+			expected: "payments readiness determined via /grpc.health.v1.Health/Check",
+		},
+		{
+			name:     "Reflection",
+			fallback: true,
+			bodies:   [][]byte{nil, reflectionResponse},
+			expected: "payments readiness determined via /grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+		},
+		{
+			name:     "Reflection v1alpha",
+			fallback: true,
+			bodies:   [][]byte{nil, nil, reflectionResponse},
+			expected: "payments readiness determined via /grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+		},
+		{
+			name:     "Nothing implemented",
+			fallback: true,
+			bodies:   [][]byte{nil, nil, nil},
+			err:      "server responded with UNIMPLEMENTED: status 12, neither grpc.health.v1.Health nor server reflection are implemented",
+		},
+		{
+			name:     "Unknown service",
+			fallback: true,
+			status:   "5",
+			bodies:   [][]byte{nil},
+			err:      "server responded with NOT_FOUND: status 5",
+		},
+		{
+			name:     "Unavailable",
+			fallback: true,
+			status:   "14",
+			bodies:   [][]byte{nil},
+			err:      "server responded with UNAVAILABLE: status 14",
+		},
+		{
+			name:     "Permission denied",
+			fallback: true,
+			status:   "7",
+			bodies:   [][]byte{nil},
+			err:      "server responded with PERMISSION_DENIED: status 7",
+		},
+		{
+			name:     "Not serving",
+			fallback: true,
+			bodies:   [][]byte{healthResponse(2)},
+			err:      "health status is NOT_SERVING",
+		},
+		{
+			name:   "Disabled",
+			bodies: [][]byte{nil},
+			err:    "server responded with UNIMPLEMENTED: status 12, grpc.health.v1.Health is not implemented",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			address, requests := newH2CServerSequence(t, tt.bodies...)
+			if tt.status != "" {
+				address, requests = newH2CStatusServer(t, nil, tt.status)
+			}
+			cfg := Config{TargetName: "payments", TargetAddress: address, GRPCReflectionFallback: tt.fallback}
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+			err := newGRPCCheck(cfg, &net.Dialer{Timeout: time.Second}, nil, logger)(context.Background())
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.Contains(stdOut.String(), tt.expected) {
+				t.Errorf("Expected output to contain %q but got %q", tt.expected, stdOut.String())
+			}
+
+			<-requests // the health check
+			for range len(tt.bodies) - 1 {
+				if request := <-requests; !bytes.Equal(request, grpcMessage([]byte{grpcListServicesField<<3 | 2, 0})) {
+					t.Errorf("Unexpected reflection request %q", request)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGRPCReflectionFallback(t *testing.T) {
+	t.Parallel()
+
+	err := validateGRPCCheck(Config{CheckType: checkTypeTCP, GRPCReflectionFallback: true})
+
+	expected := `GRPC_REFLECTION_FALLBACK can only be used with check type "grpc"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
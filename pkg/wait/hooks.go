@@ -0,0 +1,144 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"time"
+)
+
+const (
+	envOnReadyExec              = "ON_READY_EXEC"
+	envOnReadyExecRetries       = "ON_READY_EXEC_RETRIES"
+	envOnReadyExecRetryInterval = "ON_READY_EXEC_RETRY_INTERVAL"
+	envOnReadyExecRequired      = "ON_READY_EXEC_REQUIRED"
+	envOnReadyWebhook           = "ON_READY_WEBHOOK"
+)
+
+// onReadyWebhookTimeout bounds the request to the on-ready webhook.
+const onReadyWebhookTimeout = 10 * time.Second
+
+// runOnReadyExec runs the on-ready command with a shell once all targets are ready.
+// A failing command is retried up to the configured number of retries.
+func runOnReadyExec(ctx context.Context, cfg Config, logger *slog.Logger, output io.Writer) error {
+	attempts := cfg.OnReadyExecRetries + 1
+
+	for attempt := 1; ; attempt++ {
+		logger.Info(fmt.Sprintf("Running on-ready command (attempt %d/%d)...", attempt, attempts))
+
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cfg.OnReadyExec)
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		err := cmd.Run()
+		if err == nil {
+			logger.Info("On-ready command succeeded", slog.Int("exit_code", 0))
+			return nil
+		}
+
+		code := -1 // the command could not be started or was killed
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+
+		if attempt >= attempts {
+			logger.Error(fmt.Sprintf("On-ready command failed after %d attempts", attempt), slog.Int("exit_code", code), slog.String("error", err.Error()))
+			return fmt.Errorf("on-ready command failed: %w", err)
+		}
+
+		logger.Warn("On-ready command failed", slog.Int("exit_code", code), slog.String("error", err.Error()))
+
+		select {
+		case <-time.After(cfg.OnReadyExecRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// onReadyWebhookPayload is the body posted to the on-ready webhook.
+type onReadyWebhookPayload struct {
+	Status  string   `json:"status"`
+	Targets []string `json:"targets"`
+}
+
+// callOnReadyWebhook posts the ready targets as JSON to the on-ready webhook once all targets are ready.
+// Any status code other than 2xx is treated as a failure.
+func callOnReadyWebhook(ctx context.Context, cfg Config, client *http.Client, logger *slog.Logger) error {
+	logger.Info("Calling on-ready webhook...")
+
+	payload := onReadyWebhookPayload{Status: "ready"}
+	for _, targetCfg := range targetConfigs(cfg) {
+		payload.Targets = append(payload.Targets, targetCfg.TargetName)
+	}
+
+	err := postWebhook(ctx, client, cfg.OnReadyWebhook, payload)
+	if err != nil {
+		logger.Error("On-ready webhook failed", slog.String("error", err.Error()))
+		return fmt.Errorf("on-ready webhook failed: %w", err)
+	}
+
+	logger.Info("On-ready webhook succeeded")
+	return nil
+}
+
+// postWebhook posts the payload as JSON to the URL.
+func postWebhook(ctx context.Context, client *http.Client, webhookURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize)) // drain, so the connection can be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateOnReadyHooks checks the settings of the on-ready command and webhook.
+func validateOnReadyHooks(cfg Config) error {
+	if cfg.OnReadyExecRetries < 0 {
+		return fmt.Errorf("invalid %s value: retries cannot be negative", envOnReadyExecRetries)
+	}
+
+	if cfg.OnReadyExecRetryInterval < 0 {
+		return fmt.Errorf("invalid %s value: retry interval cannot be negative", envOnReadyExecRetryInterval)
+	}
+
+	if cfg.OnReadyWebhook != "" {
+		u, err := url.Parse(cfg.OnReadyWebhook)
+		if err != nil {
+			return fmt.Errorf("invalid %s value: %s", envOnReadyWebhook, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid %s format, must be a http or https URL", envOnReadyWebhook)
+		}
+	}
+
+	if cfg.OnReadyExecRequired && cfg.OnReadyExec == "" && cfg.OnReadyWebhook == "" {
+		return fmt.Errorf("%s requires %s or %s", envOnReadyExecRequired, envOnReadyExec, envOnReadyWebhook)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunOnReadyExec(t *testing.T) {
+	t.Run("Successful command", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{OnReadyExec: "echo warmed up"}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := runOnReadyExec(context.Background(), cfg, logger, &stdOut); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		for _, expected := range []string{"Running on-ready command (attempt 1/1)...", "warmed up", "On-ready command succeeded"} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+
+	t.Run("Succeed after retry", func(t *testing.T) {
+		t.Parallel()
+
+		// the command fails until it ran twice
+		marker := filepath.Join(t.TempDir(), "marker")
+		cfg := Config{
+			OnReadyExec:              fmt.Sprintf("test -f %[1]s || { touch %[1]s; exit 3; }", marker),
+			OnReadyExecRetries:       2,
+			OnReadyExecRetryInterval: 10 * time.Millisecond,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := runOnReadyExec(context.Background(), cfg, logger, &stdOut); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		for _, expected := range []string{"On-ready command failed\" exit_code=3", "Running on-ready command (attempt 2/3)...", "On-ready command succeeded"} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			OnReadyExec:              "exit 7",
+			OnReadyExecRetries:       1,
+			OnReadyExecRetryInterval: 10 * time.Millisecond,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		err := runOnReadyExec(context.Background(), cfg, logger, &stdOut)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "On-ready command failed after 2 attempts\" exit_code=7"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Context cancel between retries", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			OnReadyExec:              "exit 1",
+			OnReadyExecRetries:       5,
+			OnReadyExecRetryInterval: time.Minute,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		if err := runOnReadyExec(ctx, cfg, logger, &stdOut); err != context.DeadlineExceeded {
+			t.Errorf("Expected %v but got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestCallOnReadyWebhook(t *testing.T) {
+	t.Run("Webhook succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		var payload onReadyWebhookPayload
+		var contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := Config{
+			OnReadyWebhook: server.URL,
+			Targets:        []Target{{Name: "database"}, {Name: "cache"}},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := callOnReadyWebhook(context.Background(), cfg, server.Client(), logger); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := onReadyWebhookPayload{Status: "ready", Targets: []string{"database", "cache"}}
+		if !reflect.DeepEqual(payload, expected) {
+			t.Errorf("Expected payload %+v but got %+v", expected, payload)
+		}
+		if contentType != "application/json" {
+			t.Errorf("Expected JSON content type but got %q", contentType)
+		}
+		if !strings.Contains(stdOut.String(), "On-ready webhook succeeded") {
+			t.Errorf("Expected output to contain success message but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Webhook fails", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := Config{OnReadyWebhook: server.URL, TargetName: "database"}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		err := callOnReadyWebhook(context.Background(), cfg, server.Client(), logger)
+
+		expected := "on-ready webhook failed: unexpected status code 502"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+		if !strings.Contains(stdOut.String(), `level=ERROR msg="On-ready webhook failed"`) {
+			t.Errorf("Expected output to contain failure message but got %q", stdOut.String())
+		}
+	})
+}
+
+func TestRunOnReadyHooksRequired(t *testing.T) {
+	t.Run("Failing command is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"ON_READY_EXEC":  "exit 3",
+		}
+
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Failing command is required", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_ADDRESS":         newListener(t).Addr().String(),
+			"ON_READY_EXEC":          "exit 3",
+			"ON_READY_EXEC_REQUIRED": "true",
+		}
+
+		err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard)
+
+		expected := "on-ready command failed: exit status 3"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestValidateOnReadyHooks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		errorMsg string
+	}{
+		{name: "No hooks", cfg: Config{}},
+		{name: "Required webhook", cfg: Config{OnReadyWebhook: "https://ci.example.com/hooks/ready", OnReadyExecRequired: true}},
+		{name: "Webhook without scheme", cfg: Config{OnReadyWebhook: "ci.example.com/hooks/ready"}, errorMsg: "invalid ON_READY_WEBHOOK format, must be a http or https URL"},
+		{name: "Required without hook", cfg: Config{OnReadyExecRequired: true}, errorMsg: "ON_READY_EXEC_REQUIRED requires ON_READY_EXEC or ON_READY_WEBHOOK"},
+		{name: "Negative retries", cfg: Config{OnReadyExec: "true", OnReadyExecRetries: -1}, errorMsg: "invalid ON_READY_EXEC_RETRIES value: retries cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateOnReadyHooks(tt.cfg)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error %q but got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
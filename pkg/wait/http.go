@@ -0,0 +1,291 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	envStableBodyAttempts = "STABLE_BODY_ATTEMPTS"
+	envFatalStatus        = "FATAL_STATUS"
+	envExpectedStatus     = "EXPECTED_STATUS"
+	envExpectedBody       = "EXPECTED_BODY"
+	envHTTPMethod         = "HTTP_METHOD"
+	envHTTPHeaders        = "HTTP_HEADERS"
+)
+
+// httpMethods lists the request methods supported by HTTP checks.
+var httpMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+
+// maxBodySize bounds how much of a response body is read.
+const maxBodySize = 64 << 10
+
+// readBody reads at most maxBodySize bytes of the response body.
+func readBody(body io.Reader) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(body, maxBodySize))
+}
+
+// parseStatusCodes parses a comma-separated list of HTTP status codes.
+func parseStatusCodes(value string) ([]int, error) {
+	var codes []int
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, err := strconv.Atoi(entry)
+		if err != nil {
+			return nil, err
+		}
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("status code %d out of range 100-599", code)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// parseHTTPHeaders parses semicolon-separated headers in the format 'Key:Value', e.g. 'Authorization:Bearer token;X-Env:prod'.
+// Values may contain colons, but not semicolons.
+func parseHTTPHeaders(value string) (http.Header, error) {
+	headers := http.Header{}
+	for i, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(entry, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("entry %d %q must be in the format Key:Value", i+1, entry)
+		}
+		if !isHeaderName(key) {
+			return nil, fmt.Errorf("entry %d has an invalid header name %q", i+1, key)
+		}
+		headers.Add(key, strings.TrimSpace(val))
+	}
+
+	return headers, nil
+}
+
+// isHeaderName reports whether the name only consists of the token characters allowed in header names (RFC 9110).
+func isHeaderName(name string) bool {
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHTTP issues a request to the target URL and returns the bounded response body.
+// The request uses HTTP_METHOD, GET by default, and carries the HTTP_HEADERS, where a Host header overrides the host sent.
+// The target is ready if it responds with an expected status code, or any 2xx status code if none are configured.
+// Any other status code is a failed attempt, so waiting continues.
+// With EXPECTED_BODY set, the bounded body must also contain the substring, e.g. while a 200 response still reports warming up.
+// A fatal status code aborts waiting, as it points to a misconfiguration that will not resolve by waiting.
+func checkHTTP(ctx context.Context, client *http.Client, cfg Config) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, cfg.HTTPMethod, cfg.TargetAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range cfg.HTTPHeaders {
+		if key == "Host" {
+			req.Host = values[0] // the Host header is ignored by the client
+			continue
+		}
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if slices.Contains(cfg.FatalStatus, resp.StatusCode) {
+		return nil, &abortError{err: fmt.Errorf("received fatal status: %s", resp.Status)}
+	}
+
+	if !isExpectedStatus(cfg, resp.StatusCode) {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	body, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if cfg.ExpectedBody != "" && !bytes.Contains(body, []byte(cfg.ExpectedBody)) {
+		return nil, fmt.Errorf("response body does not contain %q", cfg.ExpectedBody)
+	}
+
+	return body, nil
+}
+
+// isExpectedStatus reports whether the status code indicates a ready target.
+func isExpectedStatus(cfg Config, code int) bool {
+	if len(cfg.ExpectedStatus) == 0 {
+		return code >= 200 && code < 300
+	}
+	return slices.Contains(cfg.ExpectedStatus, code)
+}
+
+// bodyTracker counts the consecutive attempts returning an identical response body.
+type bodyTracker struct {
+	required  int               // The number of consecutive identical bodies required.
+	last      [sha256.Size]byte // The hash of the last body.
+	identical int               // The number of consecutive attempts returning the last body.
+}
+
+// observe records the body of a successful attempt and returns an error until the body is stable.
+// Failed attempts must reset the tracker, since only consecutive attempts count.
+func (b *bodyTracker) observe(body []byte, name string, logger *slog.Logger) error {
+	sum := sha256.Sum256(body)
+	switch {
+	case b.identical == 0:
+		b.identical = 1
+	case sum == b.last:
+		b.identical++
+	default:
+		logger.Info(fmt.Sprintf("%s response body changed", name), slog.Int("size", len(body)))
+		b.identical = 1
+	}
+	b.last = sum
+
+	if b.identical < b.required {
+		return fmt.Errorf("response body not yet stable (%d/%d identical responses)", b.identical, b.required)
+	}
+	return nil
+}
+
+// reset forgets the last body after a failed attempt.
+func (b *bodyTracker) reset() {
+	b.identical = 0
+}
+
+func init() {
+	registerCheck(checkTypeHTTP, func(cfg Config, dialer Dialer, logger *slog.Logger, capture captureFunc) checkFunc {
+		return newHTTPCheck(cfg, newHTTPClient(cfg, dialer, newHandshakeRetrier(cfg, logger)), logger, capture)
+	})
+}
+
+// newHTTPCheck returns the check for HTTP targets.
+// With STABLE_BODY_ATTEMPTS set, the response body must also be identical across consecutive attempts.
+// The body of a successful check is passed to capture, if set.
+func newHTTPCheck(cfg Config, client *http.Client, logger *slog.Logger, capture captureFunc) checkFunc {
+	var tracker *bodyTracker
+	if cfg.StableBodyAttempts > 1 {
+		tracker = &bodyTracker{required: cfg.StableBodyAttempts}
+	}
+
+	return func(ctx context.Context) error {
+		body, err := checkHTTP(ctx, client, cfg)
+		if err != nil {
+			if tracker != nil {
+				tracker.reset()
+			}
+			return err
+		}
+
+		if tracker != nil {
+			if err := tracker.observe(body, cfg.TargetName, logger); err != nil {
+				return err
+			}
+		}
+
+		if capture != nil {
+			return capture(body)
+		}
+		return nil
+	}
+}
+
+// validateStableBody checks if the body stability setting is valid.
+func validateStableBody(cfg Config) error {
+	if cfg.StableBodyAttempts < 0 {
+		return fmt.Errorf("invalid %s value: attempts cannot be negative", envStableBodyAttempts)
+	}
+
+	if cfg.StableBodyAttempts > 0 && !usesCheckType(cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envStableBodyAttempts, checkTypeHTTP)
+	}
+
+	return nil
+}
+
+// validateStatus checks if expected and fatal status codes are only set for HTTP checks and do not overlap.
+func validateStatus(cfg Config) error {
+	if len(cfg.ExpectedStatus) > 0 && !usesCheckType(cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envExpectedStatus, checkTypeHTTP)
+	}
+
+	if len(cfg.FatalStatus) > 0 && !usesCheckType(cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envFatalStatus, checkTypeHTTP)
+	}
+
+	for _, code := range cfg.FatalStatus {
+		if slices.Contains(cfg.ExpectedStatus, code) {
+			return fmt.Errorf("status code %d cannot be both in %s and %s", code, envExpectedStatus, envFatalStatus)
+		}
+	}
+
+	return nil
+}
+
+// validateExpectedBody checks if the expected body is only set for HTTP checks.
+func validateExpectedBody(cfg Config) error {
+	if cfg.ExpectedBody != "" && !usesCheckType(cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envExpectedBody, checkTypeHTTP)
+	}
+
+	return nil
+}
+
+// validateHTTPRequest checks if the request method is supported and the request settings are only set for HTTP checks.
+func validateHTTPRequest(cfg *Config) error {
+	if cfg.HTTPMethod == "" {
+		cfg.HTTPMethod = http.MethodGet
+	}
+
+	if !slices.Contains(httpMethods, cfg.HTTPMethod) {
+		return fmt.Errorf("invalid %s value: must be one of %s", envHTTPMethod, strings.Join(httpMethods, ", "))
+	}
+
+	if cfg.HTTPMethod != http.MethodGet && !usesCheckType(*cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envHTTPMethod, checkTypeHTTP)
+	}
+
+	if len(cfg.HTTPHeaders) > 0 && !usesCheckType(*cfg, checkTypeHTTP) {
+		return fmt.Errorf("%s requires check type %q", envHTTPHeaders, checkTypeHTTP)
+	}
+
+	// a response to a HEAD request has no body
+	if cfg.HTTPMethod == http.MethodHead {
+		conflicts := []struct {
+			env string
+			set bool
+		}{
+			{envExpectedBody, cfg.ExpectedBody != ""},
+			{envStableBodyAttempts, cfg.StableBodyAttempts > 1},
+			{envCaptureResponseFile, cfg.CaptureResponseFile != ""},
+		}
+		for _, conflict := range conflicts {
+			if conflict.set {
+				return fmt.Errorf("%s=%s cannot be combined with %s", envHTTPMethod, http.MethodHead, conflict.env)
+			}
+		}
+	}
+
+	return nil
+}
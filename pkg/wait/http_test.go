@@ -0,0 +1,410 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP(t *testing.T) {
+	t.Run("Successful response", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		body, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(body) != `{"status":"ok"}` {
+			t.Errorf("Expected body %q but got %q", `{"status":"ok"}`, body)
+		}
+	})
+
+	t.Run("Unexpected status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL})
+
+		expected := "unexpected status: 503 Service Unavailable"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Expected status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		t.Cleanup(server.Close)
+
+		if _, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL, ExpectedStatus: []int{200, 401}}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Success status not expected", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL, ExpectedStatus: []int{200}})
+
+		expected := "unexpected status: 202 Accepted"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Fatal status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL, FatalStatus: []int{401, 403}})
+
+		var abortErr *abortError
+		if !errors.As(err, &abortErr) {
+			t.Fatalf("Expected abort error but got %v", err)
+		}
+
+		expected := "received fatal status: 401 Unauthorized"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Expected body", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		if _, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL, ExpectedBody: `"status":"ok"`}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Body still warming up", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"starting"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL, ExpectedBody: `"status":"ok"`})
+
+		expected := `response body does not contain "\"status\":\"ok\""`
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Bounded body", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(bytes.Repeat([]byte("a"), 2*maxBodySize))
+		}))
+		t.Cleanup(server.Close)
+
+		body, err := checkHTTP(context.Background(), server.Client(), Config{TargetAddress: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(body) != maxBodySize {
+			t.Errorf("Expected body of %d bytes but got %d", maxBodySize, len(body))
+		}
+	})
+
+	t.Run("Method and headers", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead || r.Header.Get("Authorization") != "Bearer secret" || r.Host != "api.internal" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := Config{
+			TargetAddress: server.URL,
+			HTTPMethod:    http.MethodHead,
+			HTTPHeaders:   http.Header{"Authorization": {"Bearer secret"}, "Host": {"api.internal"}},
+		}
+		if _, err := checkHTTP(context.Background(), server.Client(), cfg); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestNewHTTPCheckStableBody(t *testing.T) {
+	t.Parallel()
+
+	bodies := []string{"starting", "warming", "ok", "ok", "ok"}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bodies[requests.Add(1)-1]))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := Config{TargetName: "api", TargetAddress: server.URL, StableBodyAttempts: 3}
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+	check := newHTTPCheck(cfg, server.Client(), logger, nil)
+
+	expected := []string{
+		"response body not yet stable (1/3 identical responses)",
+		"response body not yet stable (1/3 identical responses)",
+		"response body not yet stable (1/3 identical responses)",
+		"response body not yet stable (2/3 identical responses)",
+		"",
+	}
+
+	for i, want := range expected {
+		err := check(context.Background())
+		if want == "" {
+			if err != nil {
+				t.Errorf("Expected attempt %d to succeed but got %v", i+1, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != want {
+			t.Errorf("Expected error %q on attempt %d but got %v", want, i+1, err)
+		}
+	}
+
+	if changes := strings.Count(output.String(), "api response body changed"); changes != 2 {
+		t.Errorf("Expected %d body changes to be logged but got %d: %q", 2, changes, output.String())
+	}
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []int
+		err      string
+	}{
+		{name: "Single code", value: "401", expected: []int{401}},
+		{name: "Multiple codes", value: "401, 403,", expected: []int{401, 403}},
+		{name: "Invalid code", value: "40x", err: `strconv.Atoi: parsing "40x": invalid syntax`},
+		{name: "Out of range", value: "600", err: "status code 600 out of range 100-599"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			codes, err := parseStatusCodes(tt.value)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("Expected status codes %v but got %v", tt.expected, codes)
+			}
+		})
+	}
+}
+
+func TestValidateStableBody(t *testing.T) {
+	t.Run("Negative attempts", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateStableBody(Config{CheckType: checkTypeHTTP, TargetAddress: "http://api", StableBodyAttempts: -1})
+
+		expected := "invalid STABLE_BODY_ATTEMPTS value: attempts cannot be negative"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Requires HTTP check", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateStableBody(Config{CheckType: checkTypeTCP, TargetAddress: "api:80", StableBodyAttempts: 2})
+
+		expected := `STABLE_BODY_ATTEMPTS requires check type "http"`
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestParseHTTPHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected http.Header
+		err      string
+	}{
+		{name: "Single header", value: "Authorization:Bearer secret", expected: http.Header{"Authorization": {"Bearer secret"}}},
+		{name: "Multiple headers", value: "x-env: prod ; Accept:application/json;", expected: http.Header{"X-Env": {"prod"}, "Accept": {"application/json"}}},
+		{name: "Colon in value", value: "X-Forwarded-For:[::1]:80", expected: http.Header{"X-Forwarded-For": {"[::1]:80"}}},
+		{name: "Repeated header", value: "Accept:text/plain;Accept:application/json", expected: http.Header{"Accept": {"text/plain", "application/json"}}},
+		{name: "Missing colon", value: "Authorization:Bearer secret;X-Env", err: `entry 2 "X-Env" must be in the format Key:Value`},
+		{name: "Missing name", value: ":prod", err: `entry 1 ":prod" must be in the format Key:Value`},
+		{name: "Invalid name", value: "X Env:prod", err: `entry 1 has an invalid header name "X Env"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			headers, err := parseHTTPHeaders(tt.value)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(headers, tt.expected) {
+				t.Errorf("Expected headers %v but got %v", tt.expected, headers)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Default", cfg: Config{CheckType: checkTypeTCP}},
+		{name: "HEAD", cfg: Config{CheckType: checkTypeHTTP, HTTPMethod: http.MethodHead}},
+		{name: "Headers", cfg: Config{CheckType: checkTypeHTTP, HTTPHeaders: http.Header{"Authorization": {"Bearer secret"}}}},
+		{name: "Unsupported method", cfg: Config{CheckType: checkTypeHTTP, HTTPMethod: "FETCH"}, err: "invalid HTTP_METHOD value: must be one of GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"},
+		{name: "Method without HTTP check", cfg: Config{CheckType: checkTypeTCP, HTTPMethod: http.MethodHead}, err: `HTTP_METHOD requires check type "http"`},
+		{name: "Headers without HTTP check", cfg: Config{CheckType: checkTypeS3, HTTPHeaders: http.Header{"X-Env": {"prod"}}}, err: `HTTP_HEADERS requires check type "http"`},
+		{name: "HEAD with expected body", cfg: Config{CheckType: checkTypeHTTP, HTTPMethod: http.MethodHead, ExpectedBody: "ok"}, err: "HTTP_METHOD=HEAD cannot be combined with EXPECTED_BODY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateHTTPRequest(&tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateExpectedBody(t *testing.T) {
+	t.Parallel()
+
+	err := validateExpectedBody(Config{CheckType: checkTypeTCP, ExpectedBody: "ok"})
+
+	expected := `EXPECTED_BODY requires check type "http"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestValidateStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "HTTP check", cfg: Config{CheckType: checkTypeHTTP, TargetAddress: "http://api", ExpectedStatus: []int{200}, FatalStatus: []int{401}}},
+		{name: "Expected status requires HTTP check", cfg: Config{CheckType: checkTypeTCP, ExpectedStatus: []int{200}}, err: `EXPECTED_STATUS requires check type "http"`},
+		{name: "Fatal status requires HTTP check", cfg: Config{CheckType: checkTypeTCP, FatalStatus: []int{401}}, err: `FATAL_STATUS requires check type "http"`},
+		{name: "Overlapping status", cfg: Config{CheckType: checkTypeHTTP, ExpectedStatus: []int{200, 401}, FatalStatus: []int{401}}, err: "status code 401 cannot be both in EXPECTED_STATUS and FATAL_STATUS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateStatus(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestWaitForHTTPTarget(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := Config{
+		TargetName:    "api",
+		TargetAddress: server.URL,
+		CheckType:     checkTypeHTTP,
+		Interval:      10 * time.Millisecond,
+		DialTimeout:   time.Second,
+	}
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
+	if _, err := WaitForTarget(context.Background(), cfg, logger); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if count := strings.Count(output.String(), "api is not ready ✗"); count != 2 {
+		t.Errorf("Expected %d not ready messages but got %d: %q", 2, count, output.String())
+	}
+}
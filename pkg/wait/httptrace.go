@@ -0,0 +1,73 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+const envHTTPTrace = "HTTP_TRACE"
+
+// httpTiming records when the phases of a HTTP request started and finished.
+type httpTiming struct {
+	mu                       sync.Mutex
+	start                    time.Time
+	dnsStart, dnsDone        time.Time
+	connectStart, connectEnd time.Time
+	tlsStart, tlsDone        time.Time
+	firstByte                time.Time
+}
+
+// clientTrace returns the hooks recording the phases of the request.
+func (t *httpTiming) clientTrace() *httptrace.ClientTrace {
+	record := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if at.IsZero() { // keep the first occurrence, e.g. when dialing multiple addresses
+			*at = time.Now()
+		}
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectEnd) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+}
+
+// attrs returns the duration of each completed phase as log attributes.
+func (t *httpTiming) attrs() []any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var attrs []any
+	phase := func(key string, start, end time.Time) {
+		if !start.IsZero() && !end.IsZero() {
+			attrs = append(attrs, slog.Duration(key, end.Sub(start)))
+		}
+	}
+	phase("dns", t.dnsStart, t.dnsDone)
+	phase("connect", t.connectStart, t.connectEnd)
+	phase("tls", t.tlsStart, t.tlsDone)
+	phase("first_byte", t.start, t.firstByte)
+	return attrs
+}
+
+// traceHTTPCheck wraps the HTTP check to log the timing breakdown of each attempt.
+// HTTP_TRACE is its own gate, so the breakdown is logged at LOG_LEVEL without enabling the other debug records.
+func traceHTTPCheck(cfg Config, logger *slog.Logger, check checkFunc) checkFunc {
+	return func(ctx context.Context) error {
+		timing := &httpTiming{start: time.Now()}
+		err := check(httptrace.WithClientTrace(ctx, timing.clientTrace()))
+		logger.Log(ctx, cfg.LogLevel, fmt.Sprintf("%s HTTP timing", cfg.TargetName), timing.attrs()...)
+		return err
+	}
+}
@@ -0,0 +1,118 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceHTTPCheck(t *testing.T) {
+	t.Run("Log timing breakdown", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := Config{
+			TargetName:    "minio",
+			TargetAddress: server.URL,
+			DialTimeout:   time.Second,
+			CheckType:     checkTypeS3,
+			S3Bucket:      "artifacts",
+			HTTPTrace:     true,
+		}
+
+		var output bytes.Buffer
+		logger := setupLogger(cfg, &output, nil)
+
+		client := server.Client()
+		check := traceHTTPCheck(cfg, logger, func(ctx context.Context) error {
+			return checkS3(ctx, client, cfg)
+		})
+		if err := check(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, field := range []string{"level=INFO", "msg=\"minio HTTP timing\"", "connect=", "tls=", "first_byte="} {
+			if !strings.Contains(output.String(), field) {
+				t.Errorf("Expected output to contain %q but got %q", field, output.String())
+			}
+		}
+
+		// the target is an IP address, so there is no DNS lookup
+		if strings.Contains(output.String(), "dns=") {
+			t.Errorf("Expected no DNS timing but got %q", output.String())
+		}
+	})
+
+	t.Run("Log level unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := Config{
+			TargetName:    "web",
+			TargetAddress: server.URL,
+			DialTimeout:   time.Second,
+			CheckType:     checkTypeHTTP,
+			HTTPMethod:    http.MethodGet,
+			LogLevel:      slog.LevelWarn,
+			HTTPTrace:     true,
+		}
+
+		var output bytes.Buffer
+		logger := setupLogger(cfg, &output, nil)
+		logger.Debug("debug record")
+
+		check := traceHTTPCheck(cfg, logger, func(ctx context.Context) error {
+			_, err := checkHTTP(ctx, server.Client(), cfg)
+			return err
+		})
+		if err := check(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !strings.Contains(output.String(), "level=WARN msg=\"web HTTP timing\"") {
+			t.Errorf("Expected the timing at the configured level but got %q", output.String())
+		}
+		if strings.Contains(output.String(), "debug record") {
+			t.Errorf("Expected HTTP_TRACE not to enable debug records but got %q", output.String())
+		}
+	})
+
+	t.Run("No trace for non-HTTP checks", func(t *testing.T) {
+		t.Parallel()
+
+		lis := newListener(t)
+
+		cfg := Config{
+			TargetName:    "postgres",
+			TargetAddress: lis.Addr().String(),
+			Interval:      50 * time.Millisecond,
+			CheckType:     checkTypeTCP,
+			HTTPTrace:     true,
+		}
+
+		var output bytes.Buffer
+		logger := setupLogger(cfg, &output, nil)
+
+		if err := pollTarget(context.Background(), cfg, logger, newCheck(cfg, &net.Dialer{}, logger)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if strings.Contains(output.String(), "HTTP timing") {
+			t.Errorf("Expected no HTTP timing but got %q", output.String())
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package wait
+
+import (
+	"log/slog"
+	"math"
+	"time"
+)
+
+// maxLatencySamples bounds how many recent latencies are kept to compute the jitter.
+const maxLatencySamples = 256
+
+// latencyStats tracks the latencies of successful checks.
+// Minimum, maximum and average cover all checks, while the jitter
+// (standard deviation) covers the most recent maxLatencySamples checks.
+type latencyStats struct {
+	count int
+	min   time.Duration
+	max   time.Duration
+	sum   time.Duration
+
+	samples []time.Duration // Ring buffer of the most recent latencies.
+	next    int             // The position of the next sample in the ring buffer.
+}
+
+// record adds the latency of a successful check.
+func (s *latencyStats) record(latency time.Duration) {
+	if s.count == 0 || latency < s.min {
+		s.min = latency
+	}
+	if latency > s.max {
+		s.max = latency
+	}
+	s.count++
+	s.sum += latency
+
+	if len(s.samples) < maxLatencySamples {
+		s.samples = append(s.samples, latency)
+		return
+	}
+	s.samples[s.next] = latency
+	s.next = (s.next + 1) % maxLatencySamples
+}
+
+// avg returns the average latency.
+func (s *latencyStats) avg() time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / time.Duration(s.count)
+}
+
+// hasJitter reports whether enough latencies were recorded for the jitter to be meaningful.
+func (s *latencyStats) hasJitter() bool {
+	return len(s.samples) >= 2
+}
+
+// jitter returns the standard deviation of the recent latencies, 0 with fewer than two samples.
+func (s *latencyStats) jitter() time.Duration {
+	if !s.hasJitter() {
+		return 0
+	}
+
+	var mean float64
+	for _, sample := range s.samples {
+		mean += float64(sample)
+	}
+	mean /= float64(len(s.samples))
+
+	var variance float64
+	for _, sample := range s.samples {
+		d := float64(sample) - mean
+		variance += d * d
+	}
+	variance /= float64(len(s.samples))
+
+	return time.Duration(math.Sqrt(variance))
+}
+
+// attr returns the statistics as log attribute group with the given key.
+// The jitter is left out with fewer than two samples, as it would always be 0.
+func (s *latencyStats) attr(key string) slog.Attr {
+	attrs := []any{
+		slog.String("min", s.min.String()),
+		slog.String("avg", s.avg().String()),
+		slog.String("max", s.max.String()),
+	}
+	if s.hasJitter() {
+		attrs = append(attrs, slog.String("jitter", s.jitter().String()))
+	}
+	return slog.Group(key, attrs...)
+}
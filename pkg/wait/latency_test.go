@@ -0,0 +1,85 @@
+package wait
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	t.Run("No samples", func(t *testing.T) {
+		t.Parallel()
+
+		var stats latencyStats
+		if stats.avg() != 0 || stats.jitter() != 0 {
+			t.Errorf("Expected zero statistics but got avg %s and jitter %s", stats.avg(), stats.jitter())
+		}
+	})
+
+	t.Run("Min, max, avg and jitter", func(t *testing.T) {
+		t.Parallel()
+
+		var stats latencyStats
+		for _, latency := range []time.Duration{2, 4, 4, 4, 5, 5, 7, 9} {
+			stats.record(latency * time.Millisecond)
+		}
+
+		if stats.min != 2*time.Millisecond {
+			t.Errorf("Expected min %s but got %s", 2*time.Millisecond, stats.min)
+		}
+		if stats.max != 9*time.Millisecond {
+			t.Errorf("Expected max %s but got %s", 9*time.Millisecond, stats.max)
+		}
+		if stats.avg() != 5*time.Millisecond {
+			t.Errorf("Expected avg %s but got %s", 5*time.Millisecond, stats.avg())
+		}
+		if stats.jitter() != 2*time.Millisecond {
+			t.Errorf("Expected jitter %s but got %s", 2*time.Millisecond, stats.jitter())
+		}
+	})
+
+	t.Run("Bounded samples", func(t *testing.T) {
+		t.Parallel()
+
+		var stats latencyStats
+		stats.record(time.Hour)
+		for i := 0; i < maxLatencySamples; i++ {
+			stats.record(time.Millisecond)
+		}
+
+		if len(stats.samples) != maxLatencySamples {
+			t.Errorf("Expected %d samples but got %d", maxLatencySamples, len(stats.samples))
+		}
+
+		// the outlier was evicted from the jitter window, but is still the maximum
+		if stats.jitter() != 0 {
+			t.Errorf("Expected jitter %s but got %s", time.Duration(0), stats.jitter())
+		}
+		if stats.max != time.Hour {
+			t.Errorf("Expected max %s but got %s", time.Hour, stats.max)
+		}
+	})
+
+	t.Run("Attribute", func(t *testing.T) {
+		t.Parallel()
+
+		var stats latencyStats
+		stats.record(2 * time.Millisecond)
+
+		var stdOut strings.Builder
+		slog.New(slog.NewTextHandler(&stdOut, nil)).Info("ready", stats.attr("latency"))
+		if strings.Contains(stdOut.String(), "jitter") {
+			t.Errorf("Expected no jitter with a single sample but got %q", stdOut.String())
+		}
+
+		stats.record(4 * time.Millisecond)
+
+		stdOut.Reset()
+		slog.New(slog.NewTextHandler(&stdOut, nil)).Info("ready", stats.attr("latency"))
+		expected := "latency.min=2ms latency.avg=3ms latency.max=4ms latency.jitter=1ms"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+)
+
+type localAddrKey struct{}
+
+// localAddr holds the local address of the most recent connection established during an attempt,
+// e.g. to tell which source port a failing attempt used when NAT or conntrack tables are exhausted.
+type localAddr struct {
+	mu   sync.Mutex
+	addr string
+}
+
+// withLocalAddr returns a context recording the local address of connections dialed with it by a localAddrDialer.
+func withLocalAddr(ctx context.Context) (context.Context, *localAddr) {
+	local := &localAddr{}
+	return context.WithValue(ctx, localAddrKey{}, local), local
+}
+
+// attrs returns the recorded local address as log attribute, or none if no connection was established.
+func (l *localAddr) attrs() []any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.addr == "" {
+		return nil
+	}
+	return []any{slog.String("local_addr", l.addr)}
+}
+
+// localAddrDialer records the local address of every established connection in the context of the dial.
+type localAddrDialer struct {
+	forward Dialer
+}
+
+// DialContext connects to the address and records the local address of the connection.
+func (d *localAddrDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if local, ok := ctx.Value(localAddrKey{}).(*localAddr); ok {
+		local.mu.Lock()
+		local.addr = conn.LocalAddr().String()
+		local.mu.Unlock()
+	}
+	return conn, nil
+}
@@ -0,0 +1,63 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalAddrLogged(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		cfg := Config{TargetName: "database", TargetAddress: newListener(t).Addr().String(), CheckType: checkTypeTCP, Interval: 10 * time.Millisecond, DialTimeout: time.Second, LogExtraFields: true}
+		if err := pollTarget(context.Background(), cfg, logger, newTargetCheck(cfg, logger)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !strings.Contains(stdOut.String(), "local_addr=127.0.0.1:") {
+			t.Errorf("Expected the local address to be logged but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Failed after connecting", func(t *testing.T) {
+		t.Parallel()
+
+		// the target accepts connections but closes them before the TLS handshake
+		address := newConnServer(t, func(conn *net.TCPConn) { conn.Close() })
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		cfg := Config{TargetName: "database", TargetAddress: address, CheckType: checkTypeTLS, Interval: 10 * time.Millisecond, DialTimeout: time.Second, MaxRetries: 1, LogAttempt: slog.LevelWarn, LogExtraFields: true}
+		if err := pollTarget(context.Background(), cfg, logger, newTargetCheck(cfg, logger)); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if !strings.Contains(stdOut.String(), "local_addr=127.0.0.1:") {
+			t.Errorf("Expected the local address to be logged but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Not connected", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		cfg := Config{TargetName: "database", TargetAddress: closedAddress(t), CheckType: checkTypeTCP, Interval: 10 * time.Millisecond, DialTimeout: time.Second, MaxRetries: 1, LogAttempt: slog.LevelWarn, LogExtraFields: true}
+		if err := pollTarget(context.Background(), cfg, logger, newTargetCheck(cfg, logger)); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if strings.Contains(stdOut.String(), "local_addr") {
+			t.Errorf("Expected no local address for a refused connection but got %q", stdOut.String())
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package wait
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const (
+	envLogLevel        = "LOG_LEVEL"
+	envLogAttemptLevel = "LOG_ATTEMPT_LEVEL"
+)
+
+// logLevels maps the supported log level names to their levels.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// parseLogLevel parses a log level name like 'warn', ignoring case.
+func parseLogLevel(name string) (slog.Level, error) {
+	level, ok := logLevels[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported log level %q, must be one of debug, info, warn, error", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,102 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected slog.Level
+		err      string
+	}{
+		{name: "Debug", input: "debug", expected: slog.LevelDebug},
+		{name: "Upper case", input: "WARN", expected: slog.LevelWarn},
+		{name: "Unsupported", input: "verbose", err: `unsupported log level "verbose", must be one of debug, info, warn, error`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			level, err := parseLogLevel(tt.input)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if level != tt.expected {
+				t.Errorf("Expected level %s but got %s", tt.expected, level)
+			}
+		})
+	}
+}
+
+func TestLogAttemptLevel(t *testing.T) {
+	t.Parallel()
+
+	getenv := func(env string) string {
+		return map[string]string{
+			"TARGET_NAME":       "database",
+			"TARGET_ADDRESS":    closedAddress(t),
+			"INTERVAL":          "10ms",
+			"DIAL_TIMEOUT":      "50ms",
+			"MAX_RETRIES":       "1",
+			"LOG_ATTEMPT_LEVEL": "debug",
+		}[env]
+	}
+
+	cfg, err := ParseConfig(getenv)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ValidateConfig(&cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var stdOut strings.Builder
+	logger := setupLogger(cfg, &stdOut, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := waitForTargets(ctx, cfg, logger, nil); err == nil {
+		t.Fatal("Expected error but got none")
+	}
+
+	if strings.Contains(stdOut.String(), "database is not ready ✗") {
+		t.Errorf("Expected failed attempts to be hidden at info level but got %q", stdOut.String())
+	}
+	if !strings.Contains(stdOut.String(), "Waiting for database to become ready...") {
+		t.Errorf("Expected waiting message at info level but got %q", stdOut.String())
+	}
+}
+
+func TestSetupLoggerLevel(t *testing.T) {
+	t.Parallel()
+
+	var stdOut strings.Builder
+	logger := setupLogger(Config{LogFormat: logFormatText, LogLevel: slog.LevelWarn}, &stdOut, nil)
+
+	logger.Info("database is ready ✓")
+	logger.Warn("database is not ready ✗")
+
+	if strings.Contains(stdOut.String(), "is ready") {
+		t.Errorf("Expected info records to be dropped but got %q", stdOut.String())
+	}
+	if !strings.Contains(stdOut.String(), "is not ready") {
+		t.Errorf("Expected warn records to be logged but got %q", stdOut.String())
+	}
+}
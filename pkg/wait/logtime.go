@@ -0,0 +1,82 @@
+package wait
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	envLogTimeFormat = "LOG_TIME_FORMAT"
+	envLogUTC        = "LOG_UTC"
+)
+
+const (
+	logTimeUnix      = "unix"      // Seconds since the Unix epoch.
+	logTimeUnixMilli = "unixmilli" // Milliseconds since the Unix epoch.
+)
+
+// logTimeLayouts maps the names of the supported time formats to their layouts.
+var logTimeLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    time.DateTime,
+}
+
+// validateLogTimeFormat checks if the time format is one of the named formats or a Go time layout.
+func validateLogTimeFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+
+	name := strings.ToLower(format)
+	if _, ok := logTimeLayouts[name]; ok || name == logTimeUnix || name == logTimeUnixMilli {
+		return nil
+	}
+
+	// a layout without any element formats to itself, e.g. a misspelled name
+	if time.Unix(0, 0).UTC().Format(format) == format {
+		return fmt.Errorf("invalid %s value: must be one of rfc3339, rfc3339nano, datetime, %s, %s or a Go time layout", envLogTimeFormat, logTimeUnix, logTimeUnixMilli)
+	}
+	return nil
+}
+
+// withLogTime returns a ReplaceAttr function formatting the time of each record with the configured format,
+// before passing the attributes on to replace. Without a time format or UTC, replace is returned as is.
+func withLogTime(cfg Config, replace func(groups []string, a slog.Attr) slog.Attr) func(groups []string, a slog.Attr) slog.Attr {
+	if cfg.LogTimeFormat == "" && !cfg.LogUTC {
+		return replace
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			a.Value = formatLogTime(a.Value.Time(), cfg.LogTimeFormat, cfg.LogUTC)
+		}
+		if replace == nil {
+			return a
+		}
+		return replace(groups, a)
+	}
+}
+
+// formatLogTime formats the time of a record, keeping slog's own format if no time format is set.
+func formatLogTime(t time.Time, format string, utc bool) slog.Value {
+	if utc {
+		t = t.UTC()
+	}
+
+	switch strings.ToLower(format) {
+	case "":
+		return slog.TimeValue(t)
+	case logTimeUnix:
+		return slog.Int64Value(t.Unix())
+	case logTimeUnixMilli:
+		return slog.Int64Value(t.UnixMilli())
+	}
+
+	if layout, ok := logTimeLayouts[strings.ToLower(format)]; ok {
+		return slog.StringValue(t.Format(layout))
+	}
+	return slog.StringValue(t.Format(format))
+}
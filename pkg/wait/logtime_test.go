@@ -0,0 +1,99 @@
+package wait
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatLogTime(t *testing.T) {
+	zone := time.FixedZone("CEST", 2*60*60)
+	recorded := time.Date(2024, 6, 1, 14, 30, 5, 123000000, zone)
+
+	tests := []struct {
+		name     string
+		format   string
+		utc      bool
+		expected string
+	}{
+		{name: "Default", format: "", expected: "2024-06-01 14:30:05.123 +0200 CEST"},
+		{name: "Default in UTC", format: "", utc: true, expected: "2024-06-01 12:30:05.123 +0000 UTC"},
+		{name: "RFC3339", format: "rfc3339", expected: "2024-06-01T14:30:05+02:00"},
+		{name: "RFC3339 in UTC", format: "RFC3339", utc: true, expected: "2024-06-01T12:30:05Z"},
+		{name: "RFC3339 nano", format: "rfc3339nano", utc: true, expected: "2024-06-01T12:30:05.123Z"},
+		{name: "Date time", format: "datetime", expected: "2024-06-01 14:30:05"},
+		{name: "Unix", format: "unix", expected: "1717245005"},
+		{name: "Unix milli", format: "unixmilli", expected: "1717245005123"},
+		{name: "Layout", format: "15:04:05.000", utc: true, expected: "12:30:05.123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if formatted := formatLogTime(recorded, tt.format, tt.utc).String(); formatted != tt.expected {
+				t.Errorf("Expected %q but got %q", tt.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestValidateLogTimeFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		err    string
+	}{
+		{name: "Default", format: ""},
+		{name: "Named", format: "rfc3339"},
+		{name: "Unix", format: "unixmilli"},
+		{name: "Layout", format: "2006-01-02T15:04:05.000Z07:00"},
+		{name: "Misspelled name", format: "iso", err: "invalid LOG_TIME_FORMAT value: must be one of rfc3339, rfc3339nano, datetime, unix, unixmilli or a Go time layout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateLogTimeFormat(tt.format)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLogTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{name: "Text", cfg: Config{LogFormat: logFormatText, LogTimeFormat: "rfc3339", LogUTC: true}, expected: "time=20"},
+		{name: "JSON", cfg: Config{LogFormat: logFormatJSON, LogTimeFormat: "rfc3339", LogUTC: true}, expected: `"time":"20`},
+		{name: "Extra fields", cfg: Config{LogFormat: logFormatText, LogTimeFormat: "unix", LogExtraFields: true}, expected: "time=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdOut strings.Builder
+			logger := setupLogger(tt.cfg, &stdOut, nil)
+			logger.Info("database is ready ✓")
+
+			if !strings.Contains(stdOut.String(), tt.expected) {
+				t.Errorf("Expected output to contain %q but got %q", tt.expected, stdOut.String())
+			}
+			if tt.cfg.LogUTC && !strings.Contains(stdOut.String(), "Z") {
+				t.Errorf("Expected the time in UTC but got %q", stdOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package wait
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+const envOnLogError = "ON_LOG_ERROR"
+
+const (
+	logErrorIgnore         = "ignore"          // Keep running without log output.
+	logErrorAbort          = "abort"           // Stop waiting and exit with an error.
+	logErrorFallbackStderr = "fallback-stderr" // Continue logging to stderr.
+)
+
+// maxLogWriteFailures is the number of consecutive failed writes after which the log output is considered broken.
+// A single failed write may be transient, e.g. a full pipe buffer.
+const maxLogWriteFailures = 3
+
+// logWriter wraps the log output and applies the ON_LOG_ERROR policy once writing consistently fails,
+// e.g. due to a broken pipe after the log consumer died.
+type logWriter struct {
+	mu       sync.Mutex
+	output   io.Writer
+	policy   string
+	fallback io.Writer // The writer used by the fallback-stderr policy.
+	onAbort  func()    // Called once when the abort policy applies.
+	failures int       // The number of consecutive failed writes.
+	err      error     // The error that aborted waiting, if any.
+}
+
+// newLogWriter returns a writer applying the policy to failed writes to output.
+func newLogWriter(output io.Writer, policy string, fallback io.Writer, onAbort func()) *logWriter {
+	return &logWriter{output: output, policy: policy, fallback: fallback, onAbort: onAbort}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.output.Write(p)
+	if err == nil {
+		w.failures = 0
+		return n, nil
+	}
+
+	w.failures++
+	if w.failures < maxLogWriteFailures {
+		return n, err
+	}
+
+	switch w.policy {
+	case logErrorAbort:
+		w.err = fmt.Errorf("failed to write log output: %w", err)
+		w.policy = logErrorIgnore // abort only once
+		w.onAbort()
+	case logErrorFallbackStderr:
+		_, _ = fmt.Fprintf(w.fallback, "failed to write log output, falling back to stderr: %s\n", err)
+		w.output = w.fallback
+		w.policy = logErrorIgnore // there is nothing left to fall back to
+		w.failures = 0
+		return w.output.Write(p)
+	}
+
+	return n, err
+}
+
+// abortErr returns the error that aborted waiting, if any.
+func (w *logWriter) abortErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// validateOnLogError checks if the log error policy is valid.
+func validateOnLogError(cfg *Config) error {
+	switch cfg.OnLogError {
+	case "":
+		cfg.OnLogError = logErrorIgnore
+	case logErrorIgnore, logErrorAbort, logErrorFallbackStderr:
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s, %s", envOnLogError, logErrorIgnore, logErrorAbort, logErrorFallbackStderr)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// brokenWriter fails every write like a pipe without a reader.
+type brokenWriter struct{}
+
+func (brokenWriter) Write(p []byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func TestLogWriter(t *testing.T) {
+	t.Run("Ignore", func(t *testing.T) {
+		t.Parallel()
+
+		var fallback strings.Builder
+		w := newLogWriter(brokenWriter{}, logErrorIgnore, &fallback, func() { t.Error("Unexpected abort") })
+
+		for range maxLogWriteFailures + 1 {
+			if _, err := w.Write([]byte("line\n")); !errors.Is(err, syscall.EPIPE) {
+				t.Errorf("Expected error %v but got %v", syscall.EPIPE, err)
+			}
+		}
+
+		if fallback.Len() != 0 {
+			t.Errorf("Expected no fallback output but got %q", fallback.String())
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		t.Parallel()
+
+		aborts := 0
+		w := newLogWriter(brokenWriter{}, logErrorAbort, nil, func() { aborts++ })
+
+		for range maxLogWriteFailures - 1 {
+			_, _ = w.Write([]byte("line\n"))
+		}
+		if aborts != 0 || w.abortErr() != nil {
+			t.Fatalf("Expected no abort before %d failed writes", maxLogWriteFailures)
+		}
+
+		for range 2 {
+			_, _ = w.Write([]byte("line\n"))
+		}
+
+		if aborts != 1 {
+			t.Errorf("Expected 1 abort but got %d", aborts)
+		}
+
+		expected := "failed to write log output: broken pipe"
+		if err := w.abortErr(); err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Fallback to stderr", func(t *testing.T) {
+		t.Parallel()
+
+		var fallback strings.Builder
+		w := newLogWriter(brokenWriter{}, logErrorFallbackStderr, &fallback, func() { t.Error("Unexpected abort") })
+
+		for i := range maxLogWriteFailures + 1 {
+			_, err := w.Write([]byte("line\n"))
+			if i+1 >= maxLogWriteFailures && err != nil {
+				t.Errorf("Unexpected error after falling back: %v", err)
+			}
+		}
+
+		expected := "failed to write log output, falling back to stderr: broken pipe\nline\nline\n"
+		if fallback.String() != expected {
+			t.Errorf("Expected fallback output %q but got %q", expected, fallback.String())
+		}
+	})
+}
+
+func TestRunOnLogErrorAbort(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"TARGET_NAME":    "database",
+		"TARGET_ADDRESS": closedAddress(t),
+		"INTERVAL":       "10ms",
+		"ON_LOG_ERROR":   "abort",
+	}
+
+	getenv := func(key string) string {
+		return env[key]
+	}
+
+	err := Run(context.Background(), nil, getenv, brokenWriter{}, io.Discard)
+
+	expected := "failed to write log output: broken pipe"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestRunOnLogErrorFallback(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"TARGET_NAME":    "database",
+		"TARGET_ADDRESS": newListener(t).Addr().String(),
+		"ON_LOG_ERROR":   "fallback-stderr",
+	}
+
+	getenv := func(key string) string {
+		return env[key]
+	}
+
+	var stdErr strings.Builder
+	if err := Run(context.Background(), nil, getenv, brokenWriter{}, &stdErr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(stdErr.String(), "database became ready") {
+		t.Errorf("Expected the log output on the error output but got %q", stdErr.String())
+	}
+}
+
+func TestValidateOnLogError(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{OnLogError: "panic"}
+
+	expected := "invalid ON_LOG_ERROR value: must be one of ignore, abort, fallback-stderr"
+	if err := validateOnLogError(&cfg); err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
@@ -0,0 +1,35 @@
+package wait
+
+import "fmt"
+
+const envMatch = "MATCH"
+
+const (
+	matchAll = "all" // Wait until every target is ready.
+	matchAny = "any" // Wait until the first target is ready.
+)
+
+// validateMatch checks if the match mode is valid and supported with the given configuration.
+func validateMatch(cfg *Config) error {
+	switch cfg.Match {
+	case "":
+		cfg.Match = matchAll
+	case matchAll, matchAny:
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envMatch, matchAll, matchAny)
+	}
+
+	if cfg.Match != matchAny {
+		return nil
+	}
+
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("%s=%s requires multiple targets", envMatch, matchAny)
+	}
+
+	if cfg.MaxSpread > 0 {
+		return fmt.Errorf("%s=%s cannot be combined with %s", envMatch, matchAny, envMaxSpread)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchAny(t *testing.T) {
+	t.Run("First ready address", func(t *testing.T) {
+		t.Parallel()
+
+		address := newListener(t).Addr().String()
+		env := map[string]string{
+			"TARGET_ADDRESS":       closedAddress(t) + "," + address,
+			"TARGET_NAME":          "replica-1,replica-2",
+			"MATCH":                "any",
+			"STARTUP_MESSAGE_MODE": "combined",
+			"INTERVAL":             "50ms",
+			"DIAL_TIMEOUT":         "50ms",
+			"MAX_WAIT":             "5s",
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, &stdOut, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, expected := range []string{
+			"Waiting for any of 2 targets to become ready: replica-1, replica-2",
+			"replica-2 is ready ✓",
+			"replica-2 at " + address + " satisfied MATCH=any, no longer waiting for the other targets",
+		} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+		if strings.Contains(stdOut.String(), "replica-1 is ready") {
+			t.Errorf("Expected replica-1 not to be ready but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Every target failed", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    10 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			MaxRetries:  1,
+			Match:       matchAny,
+			Targets: []Target{
+				{Name: "replica-1", Address: closedAddress(t)},
+				{Name: "replica-2", Address: closedAddress(t)},
+			},
+		}
+		logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+		err := waitForTargets(context.Background(), cfg, logger, nil)
+
+		var giveUpErr *giveUpError
+		if !errors.As(err, &giveUpErr) {
+			t.Fatalf("Expected both targets to give up, got %v", err)
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+			t.Errorf("Expected the errors of both targets, got %v", err)
+		}
+	})
+
+	t.Run("Interrupted wait", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    10 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			Match:       matchAny,
+			Targets: []Target{
+				{Name: "replica-1", Address: closedAddress(t)},
+				{Name: "replica-2", Address: closedAddress(t)},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		if err := waitForTargets(ctx, cfg, logger, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(stdOut.String(), "satisfied MATCH=any") {
+			t.Errorf("Expected no target to satisfy the match but got %q", stdOut.String())
+		}
+	})
+}
+
+func TestValidateMatch(t *testing.T) {
+	targets := []Target{{Name: "replica-1", Address: "replica-1:5432"}, {Name: "replica-2", Address: "replica-2:5432"}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+		err      string
+	}{
+		{name: "Default", cfg: Config{}, expected: matchAll},
+		{name: "All", cfg: Config{Match: matchAll}, expected: matchAll},
+		{name: "Any", cfg: Config{Match: matchAny, Targets: targets}, expected: matchAny},
+		{name: "Unsupported", cfg: Config{Match: "some"}, err: "invalid MATCH value: must be one of all, any"},
+		{name: "Any without multiple targets", cfg: Config{Match: matchAny}, err: "MATCH=any requires multiple targets"},
+		{name: "Any with spread", cfg: Config{Match: matchAny, Targets: targets, MaxSpread: time.Second}, err: "MATCH=any cannot be combined with MAX_SPREAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateMatch(&tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				if tt.cfg.Match != tt.expected {
+					t.Errorf("Expected match %q but got %q", tt.expected, tt.cfg.Match)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package wait
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	envMsgReady    = "MSG_READY"
+	envMsgNotReady = "MSG_NOT_READY"
+)
+
+const (
+	defaultMsgReady      = "{name} is ready ✓"
+	defaultMsgNotReady   = "{name} is not ready ✗"
+	defaultMsgUnresolved = "{name} is not ready ✗, cannot resolve host yet"
+)
+
+// messagePlaceholders lists the placeholders supported in message templates.
+var messagePlaceholders = []string{"{name}", "{address}"}
+
+// formatMessage replaces the placeholders of the template with the target, falling back to the default if unset.
+func formatMessage(template, fallback string, cfg Config) string {
+	if template == "" {
+		template = fallback
+	}
+	return strings.NewReplacer(
+		"{name}", cfg.TargetName,
+		"{address}", cfg.TargetAddress,
+	).Replace(template)
+}
+
+// notReadyMessage returns the message logged for a failed check.
+// By default, a host that does not resolve yet is told apart from a target that does not accept connections,
+// as a DNS propagation delay needs a different investigation than a service that is not listening.
+func notReadyMessage(cfg Config, reason failureReason) string {
+	if reason == reasonDNS {
+		return formatMessage(cfg.MsgNotReady, defaultMsgUnresolved, cfg)
+	}
+	return formatMessage(cfg.MsgNotReady, defaultMsgNotReady, cfg)
+}
+
+// validateMessages checks if the message templates reference the target.
+// A message without any placeholder cannot tell multiple targets apart, but is still a valid choice for a single one.
+func validateMessages(cfg Config) error {
+	for _, msg := range []struct {
+		env      string
+		template string
+	}{
+		{envMsgReady, cfg.MsgReady},
+		{envMsgNotReady, cfg.MsgNotReady},
+	} {
+		if msg.template == "" || len(cfg.Targets) == 0 {
+			continue
+		}
+		if !containsAny(msg.template, messagePlaceholders) {
+			return fmt.Errorf("invalid %s value: must contain %s with multiple targets", msg.env, strings.Join(messagePlaceholders, " or "))
+		}
+	}
+	return nil
+}
+
+// containsAny reports whether s contains any of the substrings.
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFormatMessage(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{TargetName: "database", TargetAddress: "localhost:5432"}
+
+	tests := []struct {
+		name     string
+		template string
+		fallback string
+		expected string
+	}{
+		{name: "Default ready", fallback: defaultMsgReady, expected: "database is ready ✓"},
+		{name: "Default not ready", fallback: defaultMsgNotReady, expected: "database is not ready ✗"},
+		{name: "Custom", template: "READY name={name} addr={address}", fallback: defaultMsgReady, expected: "READY name=database addr=localhost:5432"},
+		{name: "Without placeholders", template: "dependency up", fallback: defaultMsgReady, expected: "dependency up"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if msg := formatMessage(tt.template, tt.fallback, cfg); msg != tt.expected {
+				t.Errorf("Expected message %q but got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestCustomMessages(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		TargetName:    "database",
+		TargetAddress: newListener(t).Addr().String(),
+		Interval:      50 * time.Millisecond,
+		DialTimeout:   50 * time.Millisecond,
+		CheckType:     checkTypeTCP,
+		MsgReady:      "DEPENDENCY_UP {name}",
+	}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `msg="DEPENDENCY_UP database"`
+	if !strings.Contains(stdOut.String(), expected) {
+		t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+	}
+}
+
+func TestNotReadyMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		reason   failureReason
+		expected string
+	}{
+		{name: "Connection", reason: reasonConnection, expected: "database is not ready ✗"},
+		{name: "DNS", reason: reasonDNS, expected: "database is not ready ✗, cannot resolve host yet"},
+		{name: "Custom DNS", template: "{name} down", reason: reasonDNS, expected: "database down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{TargetName: "database", MsgNotReady: tt.template}
+			if msg := notReadyMessage(cfg, tt.reason); msg != tt.expected {
+				t.Errorf("Expected message %q but got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestUnresolvedHostMessage(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{TargetName: "database", CheckType: checkTypeTCP, Interval: 10 * time.Millisecond, LogAttempt: slog.LevelWarn}
+	errs := []error{
+		&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "database", IsNotFound: true}},
+		&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+	}
+	check := func(ctx context.Context) error {
+		if len(errs) == 0 {
+			return nil
+		}
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(stdOut.String(), "\n")
+	for i, expected := range []string{
+		`level=WARN msg="database is not ready ✗, cannot resolve host yet"`,
+		`level=WARN msg="database is not ready ✗"`,
+	} {
+		if !strings.Contains(lines[i+1], expected) {
+			t.Errorf("Expected line %d to contain %q but got %q", i+1, expected, lines[i+1])
+		}
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		MsgNotReady: "dependency down",
+		Targets:     []Target{{Name: "database"}, {Name: "cache"}},
+	}
+
+	err := validateMessages(cfg)
+	if err == nil {
+		t.Fatal("Expected error but got none")
+	}
+
+	expected := "invalid MSG_NOT_READY value: must contain {name} or {address} with multiple targets"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q but got %q", expected, err.Error())
+	}
+}
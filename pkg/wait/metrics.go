@@ -0,0 +1,238 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const envMetricsAddr = "METRICS_ADDR"
+
+// metricsShutdownTimeout bounds how long pending scrapes may delay exiting.
+const metricsShutdownTimeout = 2 * time.Second
+
+// metrics records the attempts and readiness of every target.
+type metrics struct {
+	mu      sync.Mutex
+	targets []*targetMetrics
+}
+
+// targetMetrics holds the metrics of a single target.
+type targetMetrics struct {
+	m        *metrics
+	name     string
+	attempts int64
+	failures int64
+	ready    bool
+	latency  latencyStats // The latencies of the successful attempts.
+}
+
+// newMetrics returns metrics for all configured targets, so every target is exposed before its first attempt.
+func newMetrics(cfg Config) *metrics {
+	m := &metrics{}
+	for _, targetCfg := range targetConfigs(cfg) {
+		m.targets = append(m.targets, &targetMetrics{m: m, name: targetCfg.TargetName})
+	}
+	return m
+}
+
+// target returns the metrics of the target with the given name.
+// Nil metrics return nil, which records nothing.
+func (m *metrics) target(name string) *targetMetrics {
+	if m == nil {
+		return nil
+	}
+	for _, t := range m.targets {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// attempts returns the number of attempts across all targets.
+func (m *metrics) attempts() int64 {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var attempts int64
+	for _, t := range m.targets {
+		attempts += t.attempts
+	}
+	return attempts
+}
+
+// latencyAttr returns the latency statistics of every target with a successful attempt as log attribute group,
+// and whether there was any.
+func (m *metrics) latencyAttr() (slog.Attr, bool) {
+	if m == nil {
+		return slog.Attr{}, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var attrs []any
+	for _, t := range m.targets {
+		if t.latency.count > 0 {
+			attrs = append(attrs, t.latency.attr(t.name))
+		}
+	}
+	return slog.Group("latency", attrs...), len(attrs) > 0
+}
+
+// observe wraps the check to record every attempt and failure, and the latency of successful attempts.
+func (t *targetMetrics) observe(check checkFunc) checkFunc {
+	if t == nil {
+		return check
+	}
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := check(ctx)
+		latency := time.Since(start)
+
+		t.m.mu.Lock()
+		defer t.m.mu.Unlock()
+
+		t.attempts++
+		if err != nil {
+			t.failures++
+		} else {
+			t.latency.record(latency)
+		}
+		return err
+	}
+}
+
+// setReady marks the target as ready.
+func (t *targetMetrics) setReady() {
+	if t == nil {
+		return
+	}
+
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+
+	t.ready = true
+}
+
+// setNotReady marks the target as no longer ready, e.g. once it went down while being monitored.
+func (t *targetMetrics) setNotReady() {
+	if t == nil {
+		return
+	}
+
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+
+	t.ready = false
+}
+
+// write writes the metrics in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP taco_connection_attempts_total The number of attempts to check the target.\n")
+	b.WriteString("# TYPE taco_connection_attempts_total counter\n")
+	for _, t := range m.targets {
+		fmt.Fprintf(&b, "taco_connection_attempts_total{target=%q} %d\n", t.name, t.attempts)
+	}
+
+	b.WriteString("# HELP taco_connection_failures_total The number of failed attempts to check the target.\n")
+	b.WriteString("# TYPE taco_connection_failures_total counter\n")
+	for _, t := range m.targets {
+		fmt.Fprintf(&b, "taco_connection_failures_total{target=%q} %d\n", t.name, t.failures)
+	}
+
+	b.WriteString("# HELP taco_target_ready Whether the target is ready.\n")
+	b.WriteString("# TYPE taco_target_ready gauge\n")
+	for _, t := range m.targets {
+		ready := 0
+		if t.ready {
+			ready = 1
+		}
+		fmt.Fprintf(&b, "taco_target_ready{target=%q} %d\n", t.name, ready)
+	}
+
+	// the latency is only exposed once the target passed a check, the jitter once it passed two
+	b.WriteString("# HELP taco_connection_latency_seconds The minimum, average and maximum latency of the successful attempts.\n")
+	b.WriteString("# TYPE taco_connection_latency_seconds gauge\n")
+	for _, t := range m.targets {
+		if t.latency.count == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "taco_connection_latency_seconds{target=%q,stat=\"min\"} %g\n", t.name, t.latency.min.Seconds())
+		fmt.Fprintf(&b, "taco_connection_latency_seconds{target=%q,stat=\"avg\"} %g\n", t.name, t.latency.avg().Seconds())
+		fmt.Fprintf(&b, "taco_connection_latency_seconds{target=%q,stat=\"max\"} %g\n", t.name, t.latency.max.Seconds())
+	}
+
+	b.WriteString("# HELP taco_connection_jitter_seconds The standard deviation of the latency of the recent successful attempts.\n")
+	b.WriteString("# TYPE taco_connection_jitter_seconds gauge\n")
+	for _, t := range m.targets {
+		if t.latency.hasJitter() {
+			fmt.Fprintf(&b, "taco_connection_jitter_seconds{target=%q} %g\n", t.name, t.latency.jitter().Seconds())
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// validateMetricsAddr checks if the metrics address is valid.
+func validateMetricsAddr(cfg Config) error {
+	if cfg.MetricsAddr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.MetricsAddr); err != nil {
+		return fmt.Errorf("invalid %s value: %s", envMetricsAddr, err)
+	}
+	return nil
+}
+
+// serveMetrics starts serving the metrics on /metrics until the context is canceled.
+// The returned function waits for the server to shut down.
+func serveMetrics(ctx context.Context, addr string, m *metrics, logger *slog.Logger) (func(), error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.write(w)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed", slog.String("error", err.Error()))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info(fmt.Sprintf("Serving metrics on %s", lis.Addr()))
+
+	return func() { <-done }, nil
+}
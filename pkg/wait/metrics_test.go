@@ -0,0 +1,166 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Records attempts and readiness", func(t *testing.T) {
+		t.Parallel()
+
+		m := newMetrics(Config{Targets: []Target{{Name: "postgres"}, {Name: "redis"}}})
+
+		postgres := m.target("postgres")
+		failing := postgres.observe(func(ctx context.Context) error { return errors.New("connection refused") })
+		succeeding := postgres.observe(func(ctx context.Context) error { return nil })
+
+		_ = failing(context.Background())
+		_ = failing(context.Background())
+		_ = succeeding(context.Background())
+		postgres.setReady()
+
+		var output strings.Builder
+		if err := m.write(&output); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, expected := range []string{
+			"# TYPE taco_connection_attempts_total counter\n",
+			`taco_connection_attempts_total{target="postgres"} 3` + "\n",
+			`taco_connection_attempts_total{target="redis"} 0` + "\n",
+			`taco_connection_failures_total{target="postgres"} 2` + "\n",
+			"# TYPE taco_target_ready gauge\n",
+			`taco_target_ready{target="postgres"} 1` + "\n",
+			`taco_target_ready{target="redis"} 0` + "\n",
+		} {
+			if !strings.Contains(output.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+			}
+		}
+
+		if attempts := m.attempts(); attempts != 3 {
+			t.Errorf("Expected 3 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("Records latency and jitter", func(t *testing.T) {
+		t.Parallel()
+
+		m := newMetrics(Config{Targets: []Target{{Name: "postgres"}, {Name: "redis"}}})
+
+		postgres := m.target("postgres")
+		_ = postgres.observe(func(ctx context.Context) error { return nil })(context.Background())
+
+		var output strings.Builder
+		if err := m.write(&output); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, pattern := range []string{
+			`taco_connection_latency_seconds\{target="postgres",stat="min"\} [0-9.e-]+\n`,
+			`taco_connection_latency_seconds\{target="postgres",stat="avg"\} [0-9.e-]+\n`,
+			`taco_connection_latency_seconds\{target="postgres",stat="max"\} [0-9.e-]+\n`,
+		} {
+			if !regexp.MustCompile(pattern).MatchString(output.String()) {
+				t.Errorf("Expected output to match %q but got %q", pattern, output.String())
+			}
+		}
+		// the target without a successful attempt has no latency, and a single attempt has no jitter
+		for _, unexpected := range []string{`{target="redis",stat=`, `taco_connection_jitter_seconds{`} {
+			if strings.Contains(output.String(), unexpected) {
+				t.Errorf("Expected output not to contain %q but got %q", unexpected, output.String())
+			}
+		}
+
+		_ = postgres.observe(func(ctx context.Context) error { return nil })(context.Background())
+
+		output.Reset()
+		if err := m.write(&output); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pattern := `taco_connection_jitter_seconds\{target="postgres"\} [0-9.e-]+\n`; !regexp.MustCompile(pattern).MatchString(output.String()) {
+			t.Errorf("Expected output to match %q but got %q", pattern, output.String())
+		}
+	})
+
+	t.Run("Nil metrics", func(t *testing.T) {
+		t.Parallel()
+
+		var m *metrics
+		target := m.target("postgres")
+		if err := target.observe(func(ctx context.Context) error { return nil })(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		target.setReady()
+
+		if attempts := m.attempts(); attempts != 0 {
+			t.Errorf("Expected 0 attempts but got %d", attempts)
+		}
+	})
+}
+
+func TestServeMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := newMetrics(Config{TargetName: "postgres"})
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wait, err := serveMetrics(ctx, "127.0.0.1:0", m, logger)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	addr := regexp.MustCompile(`Serving metrics on (\S+)"`).FindStringSubmatch(output.String())
+	if addr == nil {
+		t.Fatalf("Expected the metrics address to be logged but got %q", output.String())
+	}
+
+	resp, err := http.Get("http://" + addr[1] + "/metrics")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	expected := `taco_target_ready{target="postgres"} 0`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("Expected metrics to contain %q but got %q", expected, body)
+	}
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected the metrics server to shut down on context cancel")
+	}
+}
+
+func TestValidateMetricsAddr(t *testing.T) {
+	t.Parallel()
+
+	err := validateMetricsAddr(Config{MetricsAddr: "9090"})
+
+	expected := "invalid METRICS_ADDR value: address 9090: missing port in address"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
@@ -0,0 +1,99 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const envMonitor = "MONITOR"
+
+// monitorTargets keeps checking all targets on every interval once they are ready, until the context is canceled.
+// It returns an error if any target is down when monitoring stops, so the exit code reflects the final state.
+func monitorTargets(ctx context.Context, cfg Config, logger *slog.Logger, m *metrics) error {
+	targets := targetConfigs(cfg)
+	logger.Info(fmt.Sprintf("Monitoring %d targets every %s...", len(targets), cfg.Interval))
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(targets))
+
+	for i, targetCfg := range targets {
+		wg.Add(1)
+		go func(i int, targetCfg Config) {
+			defer wg.Done()
+			logger := targetLogger(cfg, targetCfg, logger)
+			target := m.target(targetCfg.TargetName)
+			errs[i] = monitorTarget(ctx, targetCfg, logger, target, target.observe(newTargetCheck(targetCfg, logger)))
+		}(i, targetCfg)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// monitorTarget runs the check on every interval and logs whenever the ready target goes down or becomes ready again.
+// It returns nil if the target is ready once the context is canceled.
+func monitorTarget(ctx context.Context, cfg Config, logger *slog.Logger, target *targetMetrics, check checkFunc) error {
+	var lastErr error // nil while the target is ready
+	for {
+		select {
+		case <-time.After(cfg.Interval):
+			// Continue to the next check after the interval
+		case <-ctx.Done():
+			if lastErr == nil {
+				return nil
+			}
+			reason := classifyError(lastErr)
+			return &giveUpError{
+				cause:    fmt.Errorf("%s was down when monitoring stopped", cfg.TargetName),
+				outcome:  outcomeFailed,
+				lastErr:  lastErr,
+				reason:   reason,
+				exitCode: exitCodeFor(cfg, reason),
+			}
+		}
+
+		err := check(ctx)
+		if ctx.Err() != nil {
+			continue // an interrupted check does not tell whether the target went down
+		}
+
+		switch {
+		case err != nil && lastErr == nil:
+			logger.Log(withLogTone(ctx, toneNotReady), slog.LevelWarn, fmt.Sprintf("%s went down ✗", cfg.TargetName), slog.String("error", err.Error()))
+			target.setNotReady()
+		case err == nil && lastErr != nil:
+			logger.InfoContext(withLogTone(ctx, toneReady), fmt.Sprintf("%s is ready again ✓", cfg.TargetName))
+			target.setReady()
+		}
+		lastErr = err
+	}
+}
+
+// validateMonitor checks if monitoring is combined with settings that stop checking the targets.
+func validateMonitor(cfg Config) error {
+	if !cfg.Monitor {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envOneShot, cfg.OneShot},
+		{envWaitFor + "=" + waitForDown, cfg.WaitFor == waitForDown},
+		{envMatch + "=" + matchAny, cfg.Match == matchAny},
+		{envQuorum, cfg.Quorum > 0 || cfg.QuorumPercent > 0},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envMonitor, conflict.env)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitorTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []error
+		expected []string
+		err      string
+	}{
+		{
+			name:    "Stays ready",
+			results: []error{nil, nil},
+		},
+		{
+			name:     "Goes down and recovers",
+			results:  []error{nil, errors.New("connection refused"), errors.New("connection refused"), nil},
+			expected: []string{"database went down ✗", "database is ready again ✓"},
+		},
+		{
+			name:     "Down when stopped",
+			results:  []error{errors.New("connection refused")},
+			expected: []string{"database went down ✗"},
+			err:      "database was down when monitoring stopped (mostly connection failures, last error: connection refused)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{TargetName: "database", Interval: 10 * time.Millisecond}
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// monitoring stops once all results are consumed, keeping the state of the last one
+			results := tt.results
+			check := func(ctx context.Context) error {
+				if len(results) == 0 {
+					cancel()
+					return ctx.Err()
+				}
+				err := results[0]
+				results = results[1:]
+				return err
+			}
+
+			err := monitorTarget(ctx, cfg, logger, nil, check)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			} else if err == nil || err.Error() != tt.err {
+				t.Fatalf("Expected error %q but got %v", tt.err, err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(stdOut.String(), expected) {
+					t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+				}
+			}
+			if count := strings.Count(stdOut.String(), "went down"); count > 1 {
+				t.Errorf("Expected a single down transition to be logged but got %d", count)
+			}
+			if len(tt.expected) == 0 && stdOut.Len() > 0 {
+				t.Errorf("Expected no output but got %q", stdOut.String())
+			}
+		})
+	}
+}
+
+func TestValidateMonitor(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{OneShot: true}},
+		{name: "Enabled", cfg: Config{Monitor: true, WaitFor: waitForUp}},
+		{name: "One shot", cfg: Config{Monitor: true, OneShot: true}, err: "MONITOR cannot be combined with ONE_SHOT"},
+		{name: "Wait for down", cfg: Config{Monitor: true, WaitFor: waitForDown}, err: "MONITOR cannot be combined with WAIT_FOR=down"},
+		{name: "Match any", cfg: Config{Monitor: true, Match: matchAny}, err: "MONITOR cannot be combined with MATCH=any"},
+		{name: "Quorum", cfg: Config{Monitor: true, QuorumPercent: 50}, err: "MONITOR cannot be combined with QUORUM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateMonitor(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	envNotifySocket = "NOTIFY_SOCKET"
+	envWatchdogUsec = "WATCHDOG_USEC"
+	envWatchdogPID  = "WATCHDOG_PID"
+)
+
+// notifyTimeout bounds sending the readiness notification, so a stuck supervisor does not delay exiting.
+const notifyTimeout = 5 * time.Second
+
+// notifyReady sends READY=1 to the supervisor listening on the notify socket, following the systemd notify protocol,
+// so TACO can be run as a unit of Type=notify. A failing notification is logged as a warning, as the targets are ready regardless.
+func notifyReady(ctx context.Context, socket string, logger *slog.Logger) {
+	if err := sdNotify(ctx, socket, "READY=1"); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to notify readiness via %s", socket), slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Notified readiness via %s", socket))
+}
+
+// runWatchdog sends WATCHDOG=1 to the notify socket every interval until the context is done,
+// so systemd does not consider a long wait or monitoring as hung and kill TACO after WatchdogSec.
+func runWatchdog(ctx context.Context, socket string, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := sdNotify(ctx, socket, "WATCHDOG=1"); err != nil && ctx.Err() == nil {
+			logger.Warn(fmt.Sprintf("Failed to ping the watchdog via %s", socket), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sdNotify sends the state as a single datagram to the unix socket.
+// A socket starting with @ is in the abstract namespace.
+func sdNotify(ctx context.Context, socket, state string) error {
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if strings.HasPrefix(socket, "@") {
+		addr.Name = "\x00" + socket[1:]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, addr.Net, addr.String())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// validateNotifySocket checks if the notify socket is an absolute path or an abstract socket,
+// and if the watchdog has a socket to ping.
+func validateNotifySocket(cfg Config) error {
+	if cfg.Watchdog < 0 {
+		return fmt.Errorf("invalid %s value: timeout cannot be negative", envWatchdogUsec)
+	}
+	if cfg.Watchdog > 0 && cfg.NotifySocket == "" {
+		return fmt.Errorf("%s requires %s to be set", envWatchdogUsec, envNotifySocket)
+	}
+
+	if cfg.NotifySocket == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(cfg.NotifySocket, "/") && !strings.HasPrefix(cfg.NotifySocket, "@") {
+		return fmt.Errorf("invalid %s value: must be an absolute path or start with @ for an abstract socket", envNotifySocket)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package wait
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRunNotifySocket(t *testing.T) {
+	t.Parallel()
+
+	socket, conn := newNotifySocket(t)
+
+	env := map[string]string{
+		"TARGET_NAME":    "database",
+		"TARGET_ADDRESS": newListener(t).Addr().String(),
+		"NOTIFY_SOCKET":  socket,
+	}
+	if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Expected a notification but got %v", err)
+	}
+	if state := string(buf[:n]); state != "READY=1" {
+		t.Errorf("Expected READY=1 but got %q", state)
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	t.Parallel()
+
+	socket, conn := newNotifySocket(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runWatchdog(ctx, socket, 10*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	buf := make([]byte, 64)
+	for range 3 {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Expected a watchdog ping but got %v", err)
+		}
+		if state := string(buf[:n]); state != "WATCHDOG=1" {
+			t.Errorf("Expected WATCHDOG=1 but got %q", state)
+		}
+	}
+}
+
+func TestParseWatchdog(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected time.Duration
+		err      string
+	}{
+		{name: "Unset", env: map[string]string{}},
+		{name: "Enabled", env: map[string]string{"WATCHDOG_USEC": "30000000"}, expected: 30 * time.Second},
+		{name: "Own PID", env: map[string]string{"WATCHDOG_USEC": "30000000", "WATCHDOG_PID": strconv.Itoa(os.Getpid())}, expected: 30 * time.Second},
+		{name: "Other PID", env: map[string]string{"WATCHDOG_USEC": "30000000", "WATCHDOG_PID": "1"}},
+		{name: "Invalid", env: map[string]string{"WATCHDOG_USEC": "30s"}, err: `invalid WATCHDOG_USEC value: strconv.ParseInt: parsing "30s": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := ParseConfig(func(key string) string { return tt.env[key] })
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Watchdog != tt.expected {
+				t.Errorf("Expected watchdog %s but got %s", tt.expected, cfg.Watchdog)
+			}
+		})
+	}
+}
+
+func TestSDNotifyWithoutListener(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "missing")
+	if err := sdNotify(context.Background(), socket, "READY=1"); err == nil {
+		t.Error("Expected error but got none")
+	}
+}
+
+func TestValidateNotifySocket(t *testing.T) {
+	tests := []struct {
+		name     string
+		socket   string
+		watchdog time.Duration
+		err      string
+	}{
+		{name: "Unset", socket: ""},
+		{name: "Path", socket: "/run/systemd/notify"},
+		{name: "Abstract", socket: "@/org/freedesktop/systemd1/notify/123"},
+		{name: "Watchdog", socket: "/run/systemd/notify", watchdog: 30 * time.Second},
+		{name: "Relative", socket: "notify.sock", err: "invalid NOTIFY_SOCKET value: must be an absolute path or start with @ for an abstract socket"},
+		{name: "Watchdog without socket", watchdog: 30 * time.Second, err: "WATCHDOG_USEC requires NOTIFY_SOCKET to be set"},
+		{name: "Negative watchdog", socket: "/run/systemd/notify", watchdog: -time.Second, err: "invalid WATCHDOG_USEC value: timeout cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateNotifySocket(Config{NotifySocket: tt.socket, Watchdog: tt.watchdog})
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+// newNotifySocket listens on a unix datagram socket like a supervisor and returns its path.
+func newNotifySocket(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+
+	// unix socket paths are limited to about 100 bytes, which the test temp directory may exceed
+	dir, err := os.MkdirTemp("", "taco")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return socket, conn
+}
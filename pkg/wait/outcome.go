@@ -0,0 +1,80 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const envLogOutcome = "LOG_OUTCOME"
+
+const (
+	outcomeReady     = "ready"     // All targets became ready.
+	outcomeTimeout   = "timeout"   // MAX_WAIT elapsed before all targets became ready.
+	outcomeExhausted = "exhausted" // A limit of failed attempts like MAX_RETRIES was exhausted.
+	outcomeSpread    = "spread"    // The targets did not become ready within MAX_SPREAD of each other.
+	outcomeFailed    = "failed"    // The run failed otherwise, e.g. the ONE_SHOT check or a required on-ready hook failed.
+	outcomeCancelled = "cancelled" // Waiting was canceled, e.g. by SIGTERM.
+	outcomeAborted   = "aborted"   // Waiting was aborted because a target cannot become ready.
+)
+
+// waitOutcome classifies how waiting for the targets ended.
+// When waiting was given up, the outcome follows why it was given up.
+func waitOutcome(ctx context.Context, err error) string {
+	var abortErr *abortError
+	var giveUpErr *giveUpError
+	switch {
+	case err == nil && ctx.Err() != nil:
+		return outcomeCancelled
+	case err == nil:
+		return outcomeReady
+	case errors.As(err, &abortErr):
+		return outcomeAborted
+	case errors.As(err, &giveUpErr) && giveUpErr.outcome != "":
+		return giveUpErr.outcome
+	default:
+		return outcomeFailed
+	}
+}
+
+// logOutcome logs the final structured record summarizing the run.
+// The latency of the successful checks is included per target, for targets with at least one successful check.
+func logOutcome(logger *slog.Logger, outcome string, m *metrics, elapsed time.Duration) {
+	attrs := []any{
+		slog.String("outcome", outcome),
+		slog.Int64("attempts", m.attempts()),
+		slog.Duration("elapsed", elapsed),
+	}
+	if latency, ok := m.latencyAttr(); ok {
+		attrs = append(attrs, latency)
+	}
+	logger.Info("Finished waiting", attrs...)
+}
+
+// logReadySummary logs how many attempts and how long it took until the target became ready.
+// With LOG_EXTRA_FIELDS set, both are logged as attributes instead of in the message.
+func logReadySummary(cfg Config, logger *slog.Logger, attempts int, elapsed time.Duration) {
+	if cfg.LogExtraFields {
+		logger.Info(fmt.Sprintf("%s became ready", cfg.TargetName),
+			slog.Int("attempts", attempts),
+			slog.Duration("elapsed", elapsed),
+		)
+		return
+	}
+
+	noun := "attempts"
+	if attempts == 1 {
+		noun = "attempt"
+	}
+	logger.Info(fmt.Sprintf("%s became ready after %d %s in %s", cfg.TargetName, attempts, noun, roundElapsed(elapsed)))
+}
+
+// roundElapsed rounds the elapsed time for humans, to tenths of a second from one second on.
+func roundElapsed(elapsed time.Duration) time.Duration {
+	if elapsed >= time.Second {
+		return elapsed.Round(100 * time.Millisecond)
+	}
+	return elapsed.Round(time.Millisecond)
+}
@@ -0,0 +1,223 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitOutcome(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected string
+	}{
+		{name: "Ready", ctx: context.Background(), err: nil, expected: outcomeReady},
+		{name: "Cancelled", ctx: canceled, err: nil, expected: outcomeCancelled},
+		{name: "Timeout", ctx: context.Background(), err: &giveUpError{cause: context.DeadlineExceeded, outcome: outcomeTimeout}, expected: outcomeTimeout},
+		{name: "Retries exhausted", ctx: context.Background(), err: errors.Join(&giveUpError{cause: errors.New("MAX_RETRIES exhausted after 3 failed attempts"), outcome: outcomeExhausted}), expected: outcomeExhausted},
+		{name: "Spread exceeded", ctx: context.Background(), err: &giveUpError{cause: errors.New("cache not ready within MAX_SPREAD of 1s after database"), outcome: outcomeSpread}, expected: outcomeSpread},
+		{name: "Other failure", ctx: context.Background(), err: errors.New("failed to listen for metrics"), expected: outcomeFailed},
+		{name: "Aborted", ctx: context.Background(), err: errors.Join(&abortError{err: errors.New("unexpected address")}), expected: outcomeAborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if outcome := waitOutcome(tt.ctx, tt.err); outcome != tt.expected {
+				t.Errorf("Expected outcome %q but got %q", tt.expected, outcome)
+			}
+		})
+	}
+}
+
+func TestRunLogOutcome(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"LOG_FORMAT":     "json",
+			"LOG_OUTCOME":    "true",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+
+		var record struct {
+			Msg      string                       `json:"msg"`
+			Outcome  string                       `json:"outcome"`
+			Attempts int                          `json:"attempts"`
+			Latency  map[string]map[string]string `json:"latency"`
+		}
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+			t.Fatalf("Expected last line to be a JSON record but got %q: %v", lines[len(lines)-1], err)
+		}
+
+		if record.Msg != "Finished waiting" || record.Outcome != outcomeReady || record.Attempts != 1 {
+			t.Errorf("Expected ready outcome after 1 attempt but got %+v", record)
+		}
+
+		// a single successful attempt has a latency, but no jitter
+		latency := record.Latency["database"]
+		if latency["min"] == "" || latency["avg"] == "" || latency["max"] == "" {
+			t.Errorf("Expected the latency of the target but got %+v", record.Latency)
+		}
+		if _, ok := latency["jitter"]; ok {
+			t.Errorf("Expected no jitter after a single attempt but got %+v", latency)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": closedAddress(t),
+			"INTERVAL":       "50ms",
+			"LOG_OUTCOME":    "true",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		var stdOut strings.Builder
+		if err := Run(ctx, nil, getenv, &stdOut, io.Discard); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		lines := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+		last := lines[len(lines)-1]
+
+		if !strings.Contains(last, "msg=\"Finished waiting\" outcome=timeout attempts=") {
+			t.Errorf("Expected last line to contain the timeout outcome but got %q", last)
+		}
+	})
+
+	t.Run("Required on-ready command fails", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":            "database",
+			"TARGET_ADDRESS":         newListener(t).Addr().String(),
+			"ON_READY_EXEC":          "exit 3",
+			"ON_READY_EXEC_REQUIRED": "true",
+			"LOG_OUTCOME":            "true",
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, &stdOut, io.Discard); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		lines := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+		last := lines[len(lines)-1]
+
+		if !strings.Contains(last, "msg=\"Finished waiting\" outcome=failed attempts=1") {
+			t.Errorf("Expected last line to contain the failed outcome but got %q", last)
+		}
+	})
+
+	t.Run("Target down when monitoring stopped", func(t *testing.T) {
+		t.Parallel()
+
+		lis := newListener(t)
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": lis.Addr().String(),
+			"INTERVAL":       "20ms",
+			"MONITOR":        "true",
+			"LOG_OUTCOME":    "true",
+		}
+
+		// the target goes down while being monitored and is still down once monitoring stops
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		time.AfterFunc(100*time.Millisecond, func() { lis.Close() })
+		time.AfterFunc(300*time.Millisecond, cancel)
+
+		var stdOut strings.Builder
+		if err := Run(ctx, nil, func(key string) string { return env[key] }, &stdOut, io.Discard); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		lines := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+		last := lines[len(lines)-1]
+
+		if !strings.Contains(last, "msg=\"Finished waiting\" outcome=failed attempts=") {
+			t.Errorf("Expected last line to contain the failed outcome but got %q", last)
+		}
+	})
+}
+
+func TestLogReadySummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		attempts int
+		elapsed  time.Duration
+		expected string
+	}{
+		{
+			name:     "Text summary",
+			cfg:      Config{TargetName: "database"},
+			attempts: 14,
+			elapsed:  28312 * time.Millisecond,
+			expected: `msg="database became ready after 14 attempts in 28.3s"`,
+		},
+		{
+			name:     "Single attempt",
+			cfg:      Config{TargetName: "database"},
+			attempts: 1,
+			elapsed:  3141592 * time.Nanosecond,
+			expected: `msg="database became ready after 1 attempt in 3ms"`,
+		},
+		{
+			name:     "Structured summary",
+			cfg:      Config{TargetName: "database", LogExtraFields: true},
+			attempts: 14,
+			elapsed:  28312 * time.Millisecond,
+			expected: `msg="database became ready" attempts=14 elapsed=28.312s`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+			logReadySummary(tt.cfg, logger, tt.attempts, tt.elapsed)
+
+			if !strings.Contains(stdOut.String(), tt.expected) {
+				t.Errorf("Expected output to contain %q but got %q", tt.expected, stdOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	envProbeSend     = "PROBE_SEND"
+	envProbeExpect   = "PROBE_EXPECT"
+	envProbeEncoding = "PROBE_ENCODING"
+	envSendData      = "SEND_DATA"
+	envExpectBanner  = "EXPECT_BANNER"
+)
+
+const (
+	probeEncodingText   = "text"
+	probeEncodingHex    = "hex"
+	probeEncodingBase64 = "base64"
+)
+
+// maxProbeResponseSize bounds how many bytes of a probe response are read.
+const maxProbeResponseSize = 4096
+
+// parseProbeConfig reads and decodes the probe payload and expected response into the configuration.
+func parseProbeConfig(getenv func(string) string, cfg *Config) error {
+	if getenv(envSendData) != "" || getenv(envExpectBanner) != "" {
+		return parseBannerConfig(getenv, cfg)
+	}
+
+	encoding := probeEncodingText
+	if encodingStr := getenv(envProbeEncoding); encodingStr != "" {
+		encoding = encodingStr
+	}
+
+	switch encoding {
+	case probeEncodingText, probeEncodingHex, probeEncodingBase64:
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s, %s", envProbeEncoding, probeEncodingText, probeEncodingHex, probeEncodingBase64)
+	}
+
+	var err error
+	cfg.ProbeSend, err = decodeProbeData(encoding, getenv(envProbeSend))
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", envProbeSend, err)
+	}
+
+	cfg.ProbeExpect, err = decodeProbeData(encoding, getenv(envProbeExpect))
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", envProbeExpect, err)
+	}
+
+	return nil
+}
+
+// parseBannerConfig reads the line-based shorthand of the probe into the configuration.
+// The payload is terminated with CRLF as line protocols expect, and only the first line of the response is read.
+func parseBannerConfig(getenv func(string) string, cfg *Config) error {
+	for _, env := range []string{envProbeSend, envProbeExpect, envProbeEncoding} {
+		if getenv(env) != "" {
+			return fmt.Errorf("%s and %s cannot be combined with %s", envSendData, envExpectBanner, env)
+		}
+	}
+
+	if send := getenv(envSendData); send != "" {
+		if !strings.HasSuffix(send, "\n") {
+			send += "\r\n"
+		}
+		cfg.ProbeSend = []byte(send)
+	}
+
+	if banner := getenv(envExpectBanner); banner != "" {
+		cfg.ProbeExpect = []byte(banner)
+	}
+
+	cfg.ProbeFirstLine = true
+
+	return nil
+}
+
+// decodeProbeData decodes probe data in the given encoding.
+func decodeProbeData(encoding, data string) ([]byte, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	switch encoding {
+	case probeEncodingHex:
+		return hex.DecodeString(data)
+	case probeEncodingBase64:
+		return base64.StdEncoding.DecodeString(data)
+	default:
+		return []byte(data), nil
+	}
+}
+
+// checkProbe connects to the given address, sends the payload (if any) and
+// waits for a response containing the expected data (if any).
+// Reading stops once the expected data was received, at the end of the stream, or when the timeout expires.
+// If firstLine is set, reading also stops at the end of the first line.
+func checkProbe(ctx context.Context, dialer Dialer, address string, send, expect []byte, firstLine bool, timeout time.Duration) error {
+	_, err := probe(ctx, dialer, address, send, expect, firstLine, timeout)
+	return err
+}
+
+// probe performs the probe like checkProbe and returns the response read while waiting for the expected data.
+func probe(ctx context.Context, dialer Dialer, address string, send, expect []byte, firstLine bool, timeout time.Duration) ([]byte, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	stop, err := bindConnDeadline(ctx, conn, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	if len(send) > 0 {
+		if _, err := conn.Write(send); err != nil {
+			return nil, fmt.Errorf("failed to send probe: %w", err)
+		}
+	}
+
+	if len(expect) == 0 {
+		return nil, nil
+	}
+
+	return expectResponse(conn, expect, firstLine)
+}
+
+// expectResponse reads from r until the response contains the expected data and returns the response.
+// If firstLine is set, it gives up once a complete line without the expected data was read.
+func expectResponse(r io.Reader, expect []byte, firstLine bool) ([]byte, error) {
+	response := make([]byte, 0, 512)
+	buf := make([]byte, 512)
+
+	for len(response) < maxProbeResponseSize {
+		n, err := r.Read(buf)
+		response = append(response, buf[:n]...)
+		if bytes.Contains(response, expect) {
+			return response, nil
+		}
+		if firstLine && bytes.IndexByte(response, '\n') >= 0 {
+			break
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read probe response: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected probe response %q, expected %q", truncate(response, 64), expect)
+}
+
+// isTimeout reports whether the error is a network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// truncate shortens data to at most n bytes.
+func truncate(data []byte, n int) []byte {
+	if len(data) > n {
+		return data[:n]
+	}
+	return data
+}
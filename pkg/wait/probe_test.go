@@ -0,0 +1,201 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEchoServer starts a TCP server answering each received line with the given response.
+func newEchoServer(t *testing.T, response string) string {
+	t.Helper()
+
+	lis := newListener(t)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(response))
+			}(conn)
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestParseProbeConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		send     []byte
+		expect   []byte
+		errorMsg string
+	}{
+		{
+			name:   "Text encoding",
+			env:    map[string]string{"PROBE_SEND": "PING", "PROBE_EXPECT": "PONG"},
+			send:   []byte("PING"),
+			expect: []byte("PONG"),
+		},
+		{
+			name:   "Hex encoding",
+			env:    map[string]string{"PROBE_ENCODING": "hex", "PROBE_SEND": "50494e470d0a", "PROBE_EXPECT": "2b504f4e47"},
+			send:   []byte("PING\r\n"),
+			expect: []byte("+PONG"),
+		},
+		{
+			name:   "Base64 encoding",
+			env:    map[string]string{"PROBE_ENCODING": "base64", "PROBE_SEND": "UElORw0K"},
+			send:   []byte("PING\r\n"),
+			expect: nil,
+		},
+		{
+			name:   "Send data and expect banner",
+			env:    map[string]string{"SEND_DATA": "PING", "EXPECT_BANNER": "+PONG"},
+			send:   []byte("PING\r\n"),
+			expect: []byte("+PONG"),
+		},
+		{
+			name:   "Send data with line ending",
+			env:    map[string]string{"SEND_DATA": "PING\n"},
+			send:   []byte("PING\n"),
+			expect: nil,
+		},
+		{
+			name:   "Expect banner only",
+			env:    map[string]string{"EXPECT_BANNER": "220"},
+			send:   nil,
+			expect: []byte("220"),
+		},
+		{
+			name:     "Banner combined with probe",
+			env:      map[string]string{"EXPECT_BANNER": "220", "PROBE_SEND": "EHLO"},
+			errorMsg: "SEND_DATA and EXPECT_BANNER cannot be combined with PROBE_SEND",
+		},
+		{
+			name:     "Invalid encoding",
+			env:      map[string]string{"PROBE_ENCODING": "rot13"},
+			errorMsg: "invalid PROBE_ENCODING value: must be one of text, hex, base64",
+		},
+		{
+			name:     "Undecodable payload",
+			env:      map[string]string{"PROBE_ENCODING": "hex", "PROBE_EXPECT": "zz"},
+			errorMsg: "invalid PROBE_EXPECT value: encoding/hex: invalid byte: U+007A 'z'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfg Config
+			err := parseProbeConfig(func(key string) string { return tt.env[key] }, &cfg)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("Expected error %q but got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(cfg.ProbeSend, tt.send) {
+				t.Errorf("Expected payload %q but got %q", tt.send, cfg.ProbeSend)
+			}
+			if !reflect.DeepEqual(cfg.ProbeExpect, tt.expect) {
+				t.Errorf("Expected response %q but got %q", tt.expect, cfg.ProbeExpect)
+			}
+		})
+	}
+}
+
+func TestCheckProbe(t *testing.T) {
+	t.Run("Expected response", func(t *testing.T) {
+		t.Parallel()
+
+		address := newEchoServer(t, "+PONG\r\n")
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		if err := checkProbe(context.Background(), dialer, address, []byte("PING\r\n"), []byte("+PONG"), false, time.Second); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Unexpected response", func(t *testing.T) {
+		t.Parallel()
+
+		address := newEchoServer(t, "-LOADING\r\n")
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		err := checkProbe(context.Background(), dialer, address, []byte("PING\r\n"), []byte("+PONG"), false, time.Second)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := `unexpected probe response "-LOADING\r\n", expected "+PONG"`
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("No response within timeout", func(t *testing.T) {
+		t.Parallel()
+
+		address := newEchoServer(t, "+PONG\r\n")
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		// without a payload, the server never answers
+		err := checkProbe(context.Background(), dialer, address, nil, []byte("+PONG"), false, 100*time.Millisecond)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if !strings.Contains(err.Error(), "unexpected probe response") {
+			t.Errorf("Expected unexpected probe response error but got %q", err.Error())
+		}
+	})
+	t.Run("First line without banner", func(t *testing.T) {
+		t.Parallel()
+
+		lis := newListener(t)
+		go func() {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			// keep the connection open so only the end of the line stops reading
+			_, _ = conn.Write([]byte("554 busy\r\n"))
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+		dialer := &net.Dialer{Timeout: time.Second}
+
+		start := time.Now()
+		err := checkProbe(context.Background(), dialer, lis.Addr().String(), nil, []byte("220"), true, time.Minute)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := `unexpected probe response "554 busy\r\n", expected "220"`
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected to stop reading after the first line but took %s", elapsed)
+		}
+	})
+}
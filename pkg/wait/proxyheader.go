@@ -0,0 +1,85 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const envSendProxyHeader = "SEND_PROXY_HEADER"
+
+// proxyHeaderDialer sends a PROXY protocol v1 header on every new TCP connection,
+// for targets behind a load balancer like HAProxy that expects it before the stream.
+type proxyHeaderDialer struct {
+	forward Dialer
+}
+
+// DialContext connects to the address and sends the PROXY protocol header with the addresses of the connection.
+// The connection is only returned once the header was written.
+func (d *proxyHeaderDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, address)
+	if err != nil || !strings.HasPrefix(network, "tcp") {
+		return conn, err
+	}
+
+	if timeout := dialTimeout(d.forward); timeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	if _, err := conn.Write([]byte(proxyHeaderV1(conn.LocalAddr(), conn.RemoteAddr()))); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send PROXY protocol header: %w", err)
+	}
+	_ = conn.SetWriteDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// proxyHeaderV1 returns the PROXY protocol v1 header for a connection from source to destination.
+// Addresses that are not TCP addresses of the same family are sent as UNKNOWN, which the receiver must accept.
+func proxyHeaderV1(source, destination net.Addr) string {
+	src, ok := source.(*net.TCPAddr)
+	if !ok {
+		return "PROXY UNKNOWN\r\n"
+	}
+	dst, ok := destination.(*net.TCPAddr)
+	if !ok {
+		return "PROXY UNKNOWN\r\n"
+	}
+
+	switch {
+	case src.IP.To4() != nil && dst.IP.To4() != nil:
+		return fmt.Sprintf("PROXY TCP4 %s %s %d %d\r\n", src.IP.To4(), dst.IP.To4(), src.Port, dst.Port)
+	case src.IP.To4() == nil && dst.IP.To4() == nil:
+		return fmt.Sprintf("PROXY TCP6 %s %s %d %d\r\n", src.IP, dst.IP, src.Port, dst.Port)
+	default:
+		return "PROXY UNKNOWN\r\n"
+	}
+}
+
+// validateSendProxyHeader checks if the PROXY protocol header can be sent with the given configuration.
+func validateSendProxyHeader(cfg Config) error {
+	if !cfg.SendProxyHeader {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envProxyAddress, cfg.ProxyAddress != ""},
+		{envHTTPProxyAddress, cfg.HTTPProxyAddress != ""},
+		{fmt.Sprintf("%s=%s", envProtocol, protocolUDP), cfg.Protocol == protocolUDP},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeFD), usesCheckType(cfg, checkTypeFD)},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeExec), usesCheckType(cfg, checkTypeExec)},
+		{fmt.Sprintf("%s=%s", envCheckType, checkTypeDNS), usesCheckType(cfg, checkTypeDNS)},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envSendProxyHeader, conflict.env)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProxyHeaderV1(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      net.Addr
+		destination net.Addr
+		expected    string
+	}{
+		{
+			name:        "IPv4",
+			source:      &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 51234},
+			destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.10"), Port: 443},
+			expected:    "PROXY TCP4 10.0.0.5 10.0.0.10 51234 443\r\n",
+		},
+		{
+			name:        "IPv6",
+			source:      &net.TCPAddr{IP: net.ParseIP("2001:db8::5"), Port: 51234},
+			destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::10"), Port: 443},
+			expected:    "PROXY TCP6 2001:db8::5 2001:db8::10 51234 443\r\n",
+		},
+		{
+			name:        "Mixed families",
+			source:      &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 51234},
+			destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::10"), Port: 443},
+			expected:    "PROXY UNKNOWN\r\n",
+		},
+		{
+			name:        "Not TCP",
+			source:      &net.UnixAddr{Name: "/run/taco.sock", Net: "unix"},
+			destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.10"), Port: 443},
+			expected:    "PROXY UNKNOWN\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if header := proxyHeaderV1(tt.source, tt.destination); header != tt.expected {
+				t.Errorf("Expected header %q but got %q", tt.expected, header)
+			}
+		})
+	}
+}
+
+func TestProxyHeaderDialer(t *testing.T) {
+	t.Parallel()
+
+	headers := make(chan string, 1)
+	address := newConnServer(t, func(conn *net.TCPConn) {
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		headers <- line
+	})
+
+	dialer := &proxyHeaderDialer{forward: &net.Dialer{Timeout: time.Second}}
+	conn, err := dialer.DialContext(context.Background(), "tcp", address)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	local, remote := conn.LocalAddr().(*net.TCPAddr), conn.RemoteAddr().(*net.TCPAddr)
+	expected := fmt.Sprintf("PROXY TCP4 127.0.0.1 127.0.0.1 %d %d\r\n", local.Port, remote.Port)
+
+	select {
+	case header := <-headers:
+		if header != expected {
+			t.Errorf("Expected header %q but got %q", expected, header)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the target to receive the PROXY protocol header")
+	}
+
+	if timeout := dialTimeout(dialer); timeout != time.Second {
+		t.Errorf("Expected the dial timeout of the forward dialer but got %s", timeout)
+	}
+}
+
+func TestValidateSendProxyHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{ProxyAddress: "bastion:1080"}},
+		{name: "TCP", cfg: Config{SendProxyHeader: true, CheckType: checkTypeTCP, Protocol: protocolTCP}},
+		{name: "HTTP", cfg: Config{SendProxyHeader: true, CheckType: checkTypeHTTP}},
+		{name: "SOCKS5 proxy", cfg: Config{SendProxyHeader: true, ProxyAddress: "bastion:1080"}, err: "SEND_PROXY_HEADER cannot be combined with PROXY_ADDRESS"},
+		{name: "UDP", cfg: Config{SendProxyHeader: true, Protocol: protocolUDP}, err: "SEND_PROXY_HEADER cannot be combined with PROTOCOL=udp"},
+		{name: "DNS check", cfg: Config{SendProxyHeader: true, CheckType: checkTypeDNS}, err: "SEND_PROXY_HEADER cannot be combined with CHECK_TYPE=dns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSendProxyHeader(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
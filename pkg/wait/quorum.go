@@ -0,0 +1,145 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const envQuorum = "QUORUM"
+
+// parseQuorum parses the quorum either as a number of targets, e.g. "3", or as a percentage of the targets, e.g. "80%".
+func parseQuorum(s string) (int, float64, error) {
+	if percentStr, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.ParseFloat(percentStr, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		if percent <= 0 || percent > 100 {
+			return 0, 0, errors.New("percentage must be above 0% and at most 100%")
+		}
+		return 0, percent, nil
+	}
+
+	quorum, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	if quorum < 1 {
+		return 0, 0, errors.New("quorum must be at least 1")
+	}
+	return quorum, 0, nil
+}
+
+// requiredTargets returns how many of the given number of targets must be ready.
+// A percentage is rounded up, so 80% of 3 targets requires all 3.
+func requiredTargets(cfg Config, targets int) int {
+	switch {
+	case cfg.Match == matchAny:
+		return 1
+	case cfg.Quorum > 0:
+		return cfg.Quorum
+	case cfg.QuorumPercent > 0:
+		// the percentage is applied before dividing to avoid rounding errors, e.g. 70% of 10 targets
+		required := int(math.Ceil(cfg.QuorumPercent * float64(targets) / 100))
+		return max(required, 1)
+	default:
+		return targets
+	}
+}
+
+// quorumResult is the result of waiting for one of the targets of a quorum.
+type quorumResult struct {
+	cfg   Config
+	ready bool
+	err   error
+}
+
+// waitForQuorum waits until the required number of targets is ready and stops checking the others.
+// A target that fails does not stop the others, so waiting only fails once the quorum cannot be reached anymore.
+// The number of ready targets is logged whenever a target becomes ready.
+func waitForQuorum(ctx context.Context, cfg Config, targets []Config, required int, limiter *concurrencyLimiter, logger *slog.Logger, m *metrics) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan quorumResult, len(targets))
+	for _, targetCfg := range targets {
+		go func(targetCfg Config) {
+			logger := targetLogger(cfg, targetCfg, logger)
+			target := m.target(targetCfg.TargetName)
+			check := target.observe(limiter.wrap(newTargetCheck(targetCfg, logger)))
+			err := pollTarget(ctx, targetCfg, logger, check)
+			// a canceled wait also returns without an error, either because the quorum was reached or waiting was interrupted
+			ready := err == nil && ctx.Err() == nil
+			if ready {
+				target.setReady()
+			}
+			results <- quorumResult{cfg: targetCfg, ready: ready, err: err}
+		}(targetCfg)
+	}
+
+	var ready int
+	var errs []error
+	for range targets {
+		result := <-results
+		switch {
+		case result.err != nil:
+			errs = append(errs, result.err)
+			if len(targets)-len(errs) < required {
+				cancel() // the quorum cannot be reached anymore
+			}
+		case result.ready && ready < required:
+			ready++
+			if ready < required {
+				logger.Info(fmt.Sprintf("%d/%d targets ready, waiting for %d", ready, len(targets), required))
+				continue
+			}
+
+			if cfg.Match == matchAny {
+				logger.Info(fmt.Sprintf("%s at %s satisfied %s=%s, no longer waiting for the other targets", result.cfg.TargetName, result.cfg.TargetAddress, envMatch, matchAny))
+			} else {
+				logger.Info(fmt.Sprintf("%d/%d targets ready, %s reached, no longer waiting for the other targets", ready, len(targets), envQuorum))
+			}
+			cancel()
+		}
+	}
+
+	if ready >= required {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// validateQuorum checks if the quorum can be reached by the configured targets.
+func validateQuorum(cfg Config) error {
+	if cfg.Quorum == 0 && cfg.QuorumPercent == 0 {
+		return nil
+	}
+
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("%s requires multiple targets", envQuorum)
+	}
+
+	if cfg.Quorum > len(cfg.Targets) {
+		return fmt.Errorf("invalid %s value: %d exceeds the number of targets (%d)", envQuorum, cfg.Quorum, len(cfg.Targets))
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envMatch + "=" + matchAny, cfg.Match == matchAny},
+		{envMaxSpread, cfg.MaxSpread > 0},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envQuorum, conflict.env)
+		}
+	}
+
+	return nil
+}
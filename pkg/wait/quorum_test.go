@@ -0,0 +1,177 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuorum(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		quorum  int
+		percent float64
+		err     string
+	}{
+		{name: "Count", value: "3", quorum: 3},
+		{name: "Percentage", value: "80%", percent: 80},
+		{name: "Zero count", value: "0", err: "quorum must be at least 1"},
+		{name: "Zero percentage", value: "0%", err: "percentage must be above 0% and at most 100%"},
+		{name: "Percentage above 100", value: "120%", err: "percentage must be above 0% and at most 100%"},
+		{name: "Invalid", value: "most", err: `strconv.Atoi: parsing "most": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			quorum, percent, err := parseQuorum(tt.value)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if quorum != tt.quorum || percent != tt.percent {
+				t.Errorf("Expected quorum %d and percentage %v but got %d and %v", tt.quorum, tt.percent, quorum, percent)
+			}
+		})
+	}
+}
+
+func TestRequiredTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		targets  int
+		expected int
+	}{
+		{name: "All", cfg: Config{Match: matchAll}, targets: 5, expected: 5},
+		{name: "Any", cfg: Config{Match: matchAny}, targets: 5, expected: 1},
+		{name: "Count", cfg: Config{Quorum: 3}, targets: 5, expected: 3},
+		{name: "Percentage", cfg: Config{QuorumPercent: 80}, targets: 5, expected: 4},
+		{name: "Percentage rounded up", cfg: Config{QuorumPercent: 80}, targets: 3, expected: 3},
+		{name: "Exact percentage", cfg: Config{QuorumPercent: 70}, targets: 10, expected: 7},
+		{name: "Small percentage", cfg: Config{QuorumPercent: 1}, targets: 3, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if required := requiredTargets(tt.cfg, tt.targets); required != tt.expected {
+				t.Errorf("Expected %d required targets but got %d", tt.expected, required)
+			}
+		})
+	}
+}
+
+func TestWaitForQuorum(t *testing.T) {
+	t.Run("Quorum reached", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    50 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			Quorum:      2,
+			Targets: []Target{
+				{Name: "replica-1", Address: newListener(t).Addr().String()},
+				{Name: "replica-2", Address: closedAddress(t)},
+				{Name: "replica-3", Address: newListener(t).Addr().String()},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := waitForTargets(ctx, cfg, logger, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if ctx.Err() != nil {
+			t.Fatal("Expected the quorum to be reached, but waiting timed out")
+		}
+
+		for _, expected := range []string{
+			"1/3 targets ready, waiting for 2",
+			"2/3 targets ready, QUORUM reached, no longer waiting for the other targets",
+		} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+		if strings.Contains(stdOut.String(), "replica-2 is ready") {
+			t.Errorf("Expected replica-2 not to be ready but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Quorum unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    10 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			MaxRetries:  1,
+			Quorum:      2,
+			Targets: []Target{
+				{Name: "replica-1", Address: newListener(t).Addr().String()},
+				{Name: "replica-2", Address: closedAddress(t)},
+				{Name: "replica-3", Address: closedAddress(t)},
+			},
+		}
+		logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+		err := waitForTargets(context.Background(), cfg, logger, nil)
+
+		var giveUpErr *giveUpError
+		if !errors.As(err, &giveUpErr) {
+			t.Fatalf("Expected the failed targets to give up, got %v", err)
+		}
+	})
+}
+
+func TestValidateQuorum(t *testing.T) {
+	targets := []Target{{Name: "replica-1", Address: "replica-1:5432"}, {Name: "replica-2", Address: "replica-2:5432"}}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{}},
+		{name: "Count", cfg: Config{Quorum: 2, Targets: targets}},
+		{name: "Percentage", cfg: Config{QuorumPercent: 50, Targets: targets}},
+		{name: "Single target", cfg: Config{Quorum: 1}, err: "QUORUM requires multiple targets"},
+		{name: "Count above targets", cfg: Config{Quorum: 3, Targets: targets}, err: "invalid QUORUM value: 3 exceeds the number of targets (2)"},
+		{name: "Match any", cfg: Config{Quorum: 1, Match: matchAny, Targets: targets}, err: "QUORUM cannot be combined with MATCH=any"},
+		{name: "Max spread", cfg: Config{QuorumPercent: 50, MaxSpread: time.Second, Targets: targets}, err: "QUORUM cannot be combined with MAX_SPREAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateQuorum(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// waitWithReload waits for the targets like waitForTargets, but restarts the wait with a new configuration
+// whenever a signal is received on reload, so a long-running sidecar can pick up new targets or intervals.
+// A configuration that fails to load is logged and the current one is kept.
+// Settings of the process itself, like the logger, the metrics server or MAX_WAIT, are not reloaded.
+// As the environment of the process cannot change, only changes to CONFIG_FILE and ENV_FILE are picked up,
+// while TARGET_ADDRESS_FILE is read before every check anyway.
+// It returns the configuration that was active when the wait ended, to be used for everything after it.
+func waitWithReload(ctx context.Context, cfg Config, logger *slog.Logger, m *metrics, reload <-chan os.Signal, load func() (Config, error)) (Config, error) {
+	for {
+		waitCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func(cfg Config) {
+			done <- waitForTargets(waitCtx, cfg, logger, m)
+		}(cfg)
+
+		reloaded := false
+		for !reloaded {
+			select {
+			case err := <-done:
+				cancel()
+				return cfg, err
+			case <-reload:
+				logger.Info("Reloading the configuration...")
+
+				newCfg, err := load()
+				if err != nil {
+					logger.Error("Failed to reload the configuration, keeping the current one", "error", err.Error())
+					continue
+				}
+
+				// the targets were already given time to initialize
+				newCfg.InitialDelay = 0
+				cfg, reloaded = newCfg, true
+			}
+		}
+
+		// the targets may have become ready in the meantime, which the new wait will notice on its first check
+		cancel()
+		<-done
+
+		logger.Info("Configuration reloaded, restarting the wait")
+	}
+}
@@ -0,0 +1,99 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWaitWithReload(t *testing.T) {
+	t.Run("Reload applies new target", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			CheckType:     checkTypeTCP,
+			Interval:      50 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+		}
+		reloadedCfg := cfg
+		reloadedCfg.TargetAddress = newListener(t).Addr().String()
+		reloadedCfg.InitialDelay = time.Hour
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		reload := make(chan os.Signal, 1)
+		reload <- syscall.SIGHUP
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		activeCfg, err := waitWithReload(ctx, cfg, logger, nil, reload, func() (Config, error) {
+			return reloadedCfg, nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if ctx.Err() != nil {
+			t.Fatal("Expected the reloaded target to become ready, but waiting timed out")
+		}
+		if activeCfg.TargetAddress != reloadedCfg.TargetAddress {
+			t.Errorf("Expected the reloaded configuration to be returned, got address %q", activeCfg.TargetAddress)
+		}
+
+		if !strings.Contains(stdOut.String(), "Configuration reloaded, restarting the wait") {
+			t.Errorf("Expected reload message in output, got %q", stdOut.String())
+		}
+		if strings.Contains(stdOut.String(), "Delaying the first check") {
+			t.Errorf("Expected INITIAL_DELAY to be skipped after reloading, got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Invalid reload keeps configuration", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			CheckType:     checkTypeTCP,
+			Interval:      50 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		reload := make(chan os.Signal, 1)
+		reload <- syscall.SIGHUP
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		activeCfg, err := waitWithReload(ctx, cfg, logger, nil, reload, func() (Config, error) {
+			return Config{}, errors.New("configuration error: invalid INTERVAL value: time: invalid duration")
+		})
+
+		var giveUpErr *giveUpError
+		if !errors.As(err, &giveUpErr) {
+			t.Fatalf("Expected the current wait to time out, got %v", err)
+		}
+		if activeCfg.TargetAddress != cfg.TargetAddress {
+			t.Errorf("Expected the current configuration to be returned, got address %q", activeCfg.TargetAddress)
+		}
+
+		expected := `level=ERROR msg="Failed to reload the configuration, keeping the current one"`
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q, got %q", expected, stdOut.String())
+		}
+		if strings.Contains(stdOut.String(), "Configuration reloaded") {
+			t.Errorf("Expected no reload, got %q", stdOut.String())
+		}
+	})
+}
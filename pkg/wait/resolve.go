@@ -0,0 +1,106 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const envExpectedIPs = "EXPECTED_IPS"
+
+// parseExpectedIPs parses a comma-separated list of IP addresses and CIDR prefixes.
+func parseExpectedIPs(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// targetHost returns the host part of the target address.
+func targetHost(cfg Config) string {
+	if isURLCheckType(cfg.CheckType) {
+		if u, err := url.Parse(cfg.TargetAddress); err == nil {
+			return u.Hostname()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(cfg.TargetAddress)
+	if err != nil {
+		return cfg.TargetAddress
+	}
+	return host
+}
+
+// verifyResolvedIPs resolves the host and checks that every resolved address is expected.
+// An unexpected address aborts waiting, as it points to DNS poisoning or stale records.
+func verifyResolvedIPs(ctx context.Context, resolver *net.Resolver, host string, expected []netip.Prefix) error {
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !containsAddr(expected, addr.Unmap()) {
+			return &abortError{err: fmt.Errorf("%s resolved to unexpected address %s", host, addr.Unmap())}
+		}
+	}
+
+	return nil
+}
+
+// containsAddr reports whether any of the prefixes contains the address.
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// logResolvedIPs wraps the check to resolve the target host before each attempt and log its addresses at debug level,
+// so a host flapping between addresses during a rollout can be told apart in the logs.
+// Resolution errors are not logged, as the check itself reports them.
+func logResolvedIPs(cfg Config, logger *slog.Logger, resolver *net.Resolver, check checkFunc) checkFunc {
+	host := targetHost(cfg)
+	if _, err := netip.ParseAddr(host); err == nil {
+		return check // an IP address needs no resolution
+	}
+
+	return func(ctx context.Context) error {
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			if addrs, err := resolver.LookupNetIP(ctx, "ip", host); err == nil {
+				ips := make([]string, 0, len(addrs))
+				for _, addr := range addrs {
+					ips = append(ips, addr.Unmap().String())
+				}
+				logger.Debug(fmt.Sprintf("%s resolved to %s", host, strings.Join(ips, ", ")))
+			}
+		}
+		return check(ctx)
+	}
+}
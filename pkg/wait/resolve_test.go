@@ -0,0 +1,149 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExpectedIPs(t *testing.T) {
+	t.Run("Addresses and prefixes", func(t *testing.T) {
+		t.Parallel()
+
+		prefixes, err := parseExpectedIPs("10.0.3.4, 10.1.0.0/16,::1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []netip.Prefix{
+			netip.MustParsePrefix("10.0.3.4/32"),
+			netip.MustParsePrefix("10.1.0.0/16"),
+			netip.MustParsePrefix("::1/128"),
+		}
+		if !reflect.DeepEqual(prefixes, expected) {
+			t.Errorf("Expected %v but got %v", expected, prefixes)
+		}
+	})
+
+	t.Run("Invalid EXPECTED_IPS", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseConfig(func(key string) string {
+			if key == "EXPECTED_IPS" {
+				return "10.0.3"
+			}
+			return ""
+		})
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := `invalid EXPECTED_IPS value: ParseAddr("10.0.3"): IPv4 address too short`
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestVerifyResolvedIPs(t *testing.T) {
+	t.Run("Expected address", func(t *testing.T) {
+		t.Parallel()
+
+		expected := []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+		if err := verifyResolvedIPs(context.Background(), net.DefaultResolver, "127.0.0.1", expected); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Unexpected address", func(t *testing.T) {
+		t.Parallel()
+
+		expected := []netip.Prefix{netip.MustParsePrefix("10.0.3.4/32")}
+		err := verifyResolvedIPs(context.Background(), net.DefaultResolver, "127.0.0.1", expected)
+
+		var abortErr *abortError
+		if !errors.As(err, &abortErr) {
+			t.Fatalf("Expected abort error but got %v", err)
+		}
+
+		expectedMsg := "127.0.0.1 resolved to unexpected address 127.0.0.1"
+		if err.Error() != expectedMsg {
+			t.Errorf("Expected error %q but got %q", expectedMsg, err.Error())
+		}
+	})
+
+	t.Run("Abort waiting", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: newListener(t).Addr().String(),
+			Interval:      time.Second,
+			DialTimeout:   time.Second,
+			ExpectedIPs:   []netip.Prefix{netip.MustParsePrefix("10.0.3.4/32")},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		_, err := WaitForTarget(context.Background(), cfg, logger)
+		var abortErr *abortError
+		if !errors.As(err, &abortErr) {
+			t.Fatalf("Expected abort error but got %v", err)
+		}
+
+		expected := "database cannot become ready ✗"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+}
+
+func TestLogResolvedIPs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		address  string
+		level    slog.Level
+		expected string
+	}{
+		{name: "Hostname", address: "localhost:5432", level: slog.LevelDebug, expected: "localhost resolved to 127.0.0.1"},
+		{name: "IP address", address: "127.0.0.1:5432", level: slog.LevelDebug},
+		{name: "Debug level disabled", address: "localhost:5432", level: slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdOut strings.Builder
+			logger := slog.New(slog.NewTextHandler(&stdOut, &slog.HandlerOptions{Level: tt.level}))
+
+			dialErr := errors.New("connection refused")
+			check := logResolvedIPs(Config{TargetAddress: tt.address}, logger, net.DefaultResolver, func(ctx context.Context) error {
+				return dialErr
+			})
+
+			if err := check(context.Background()); !errors.Is(err, dialErr) {
+				t.Errorf("Expected error %v but got %v", dialErr, err)
+			}
+
+			if tt.expected == "" {
+				if stdOut.Len() > 0 {
+					t.Errorf("Expected no output but got %q", stdOut.String())
+				}
+				return
+			}
+			if !strings.Contains(stdOut.String(), tt.expected) {
+				t.Errorf("Expected output to contain %q but got %q", tt.expected, stdOut.String())
+			}
+		})
+	}
+}
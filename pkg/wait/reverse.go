@@ -0,0 +1,99 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	envReverseCheckListen  = "REVERSE_CHECK_LISTEN"
+	envReverseCheckAddress = "REVERSE_CHECK_ADDRESS"
+	envReverseCheckTimeout = "REVERSE_CHECK_TIMEOUT"
+)
+
+// callbackPlaceholder is replaced with the callback address in the probe payload.
+const callbackPlaceholder = "{callback}"
+
+// checkReverse verifies that the target is reachable and can also reach back.
+// It listens for a callback, connects to the target sending the probe payload
+// with the callback address, and waits until the target connected back.
+func checkReverse(ctx context.Context, dialer Dialer, cfg Config) error {
+	lis, err := net.Listen("tcp", cfg.ReverseCheckListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen for callback: %w", err)
+	}
+	defer lis.Close()
+
+	callback := cfg.ReverseCheckAddress
+	if callback == "" {
+		callback = lis.Addr().String()
+	}
+
+	send := bytes.ReplaceAll(cfg.ProbeSend, []byte(callbackPlaceholder), []byte(callback))
+	if err := checkProbe(ctx, dialer, cfg.TargetAddress, send, cfg.ProbeExpect, cfg.ProbeFirstLine, cfg.DialTimeout); err != nil {
+		return err
+	}
+
+	return awaitCallback(ctx, lis, callback, cfg.ReverseCheckTimeout)
+}
+
+// awaitCallback waits until a connection is accepted on the listener.
+func awaitCallback(ctx context.Context, lis net.Listener, callback string, timeout time.Duration) error {
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			conn.Close()
+		}
+		accepted <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-accepted:
+		if err != nil {
+			return fmt.Errorf("failed to accept callback: %w", err)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("target did not connect back to %s within %s", callback, timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// validateReverseCheck checks if the reverse check settings are valid.
+func validateReverseCheck(cfg *Config) error {
+	if cfg.ReverseCheckListen == "" {
+		return nil
+	}
+
+	if len(cfg.Targets) > 0 {
+		return fmt.Errorf("%s cannot be used with multiple targets", envReverseCheckListen)
+	}
+
+	if cfg.CheckType != checkTypeTCP {
+		return fmt.Errorf("%s can only be used with check type %q", envReverseCheckListen, checkTypeTCP)
+	}
+
+	host, _, err := net.SplitHostPort(cfg.ReverseCheckListen)
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", envReverseCheckListen, err)
+	}
+
+	// a wildcard listen address cannot be advertised to the target
+	if cfg.ReverseCheckAddress == "" && (host == "" || net.ParseIP(host).IsUnspecified()) {
+		return fmt.Errorf("%s is required when %s has no specific host", envReverseCheckAddress, envReverseCheckListen)
+	}
+
+	if cfg.ReverseCheckTimeout < 0 {
+		return fmt.Errorf("invalid %s value: timeout cannot be negative", envReverseCheckTimeout)
+	}
+
+	return nil
+}
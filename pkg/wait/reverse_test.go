@@ -0,0 +1,103 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCallbackServer starts a TCP server that connects back to the address received as first line.
+func newCallbackServer(t *testing.T, connectBack bool) string {
+	t.Helper()
+
+	lis := newListener(t)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte("OK\n"))
+				if !connectBack {
+					return
+				}
+				callback, err := net.Dial("tcp", strings.TrimPrefix(strings.TrimSpace(line), "HELLO "))
+				if err == nil {
+					callback.Close()
+				}
+			}(conn)
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestCheckReverse(t *testing.T) {
+	t.Run("Target connects back", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetAddress:       newCallbackServer(t, true),
+			DialTimeout:         time.Second,
+			ReverseCheckListen:  "127.0.0.1:0",
+			ReverseCheckTimeout: time.Second,
+			ProbeSend:           []byte("HELLO {callback}\n"),
+			ProbeExpect:         []byte("OK"),
+		}
+
+		if err := checkReverse(context.Background(), &net.Dialer{}, cfg); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Target does not connect back", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetAddress:       newCallbackServer(t, false),
+			DialTimeout:         time.Second,
+			ReverseCheckListen:  "127.0.0.1:0",
+			ReverseCheckTimeout: 100 * time.Millisecond,
+			ProbeSend:           []byte("HELLO {callback}\n"),
+		}
+
+		err := checkReverse(context.Background(), &net.Dialer{}, cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if !strings.Contains(err.Error(), "target did not connect back to 127.0.0.1:") {
+			t.Errorf("Expected callback timeout error but got %q", err.Error())
+		}
+	})
+
+	t.Run("Wildcard listen address without callback address", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetAddress:      "peer:7000",
+			CheckType:          checkTypeTCP,
+			ReverseCheckListen: ":7070",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "REVERSE_CHECK_ADDRESS is required when REVERSE_CHECK_LISTEN has no specific host"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package wait
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	envS3Bucket          = "S3_BUCKET"
+	envS3Region          = "S3_REGION"
+	envS3AccessKeyID     = "S3_ACCESS_KEY_ID"
+	envS3SecretAccessKey = "S3_SECRET_ACCESS_KEY"
+	envS3SessionToken    = "S3_SESSION_TOKEN"
+)
+
+const defaultS3Region = "us-east-1"
+
+// emptyPayloadHash is the SHA-256 hash of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func init() {
+	registerCheck(checkTypeS3, func(cfg Config, dialer Dialer, logger *slog.Logger, _ captureFunc) checkFunc {
+		client := newHTTPClient(cfg, dialer, newHandshakeRetrier(cfg, logger))
+		return func(ctx context.Context) error {
+			return checkS3(ctx, client, cfg)
+		}
+	})
+}
+
+// s3Credentials holds the credentials used to sign S3 requests.
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// parseS3Config reads the S3 settings into the configuration.
+func parseS3Config(getenv func(string) string, cfg *Config) {
+	cfg.S3Bucket = getenv(envS3Bucket)
+	cfg.S3Region = defaultS3Region
+	if region := getenv(envS3Region); region != "" {
+		cfg.S3Region = region
+	}
+	cfg.S3AccessKeyID = getenv(envS3AccessKeyID)
+	cfg.S3SecretAccessKey = getenv(envS3SecretAccessKey)
+	cfg.S3SessionToken = getenv(envS3SessionToken)
+}
+
+// checkS3 checks if the configured bucket of the S3-compatible endpoint is accessible.
+// It issues a path-style HEAD request for the bucket, signed if credentials are configured.
+func checkS3(ctx context.Context, client *http.Client, cfg Config) error {
+	bucketURL := strings.TrimSuffix(cfg.TargetAddress, "/") + "/" + url.PathEscape(cfg.S3Bucket)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, bucketURL, nil)
+	if err != nil {
+		return err
+	}
+
+	if cfg.S3AccessKeyID != "" {
+		creds := s3Credentials{
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			SessionToken:    cfg.S3SessionToken,
+		}
+		signV4(req, creds, cfg.S3Region, time.Now())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w to bucket %q: %s", errAuth, cfg.S3Bucket, resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("bucket %q not found: %s", cfg.S3Bucket, resp.Status)
+	default:
+		return fmt.Errorf("unexpected response for bucket %q: %s", cfg.S3Bucket, resp.Status)
+	}
+}
+
+// signV4 signs the request with AWS Signature Version 4 for the S3 service.
+// The host and all headers set on the request are signed, and the payload must be empty.
+func signV4(req *http.Request, creds s3Credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
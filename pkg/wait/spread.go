@@ -0,0 +1,131 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const envMaxSpread = "MAX_SPREAD"
+
+// spreadGuard fails waiting for multiple targets if they do not become ready within a window of each other.
+// The window starts when the first target becomes ready, so a straggler is detected without waiting for it.
+type spreadGuard struct {
+	spread time.Duration
+	cancel context.CancelFunc // Stops waiting for the stragglers.
+	names  []string           // The names of all targets, in order.
+
+	mu      sync.Mutex
+	pending map[string]bool // The targets that are not ready yet.
+	first   string          // The first target that became ready.
+	timer   *time.Timer
+	err     error
+}
+
+// newSpreadGuard returns a spreadGuard for the given targets, or nil if spread is not enforced.
+func newSpreadGuard(spread time.Duration, targets []Config, cancel context.CancelFunc) *spreadGuard {
+	if spread <= 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(targets))
+	pending := make(map[string]bool, len(targets))
+	for _, targetCfg := range targets {
+		names = append(names, targetCfg.TargetName)
+		pending[targetCfg.TargetName] = true
+	}
+
+	return &spreadGuard{spread: spread, cancel: cancel, names: names, pending: pending}
+}
+
+// ready records that a target became ready and starts the window with the first one.
+func (g *spreadGuard) ready(name string) {
+	if g == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.err != nil {
+		return
+	}
+
+	delete(g.pending, name)
+	if len(g.pending) == 0 {
+		if g.timer != nil {
+			g.timer.Stop()
+		}
+		return
+	}
+
+	if g.timer == nil {
+		g.first = name
+		g.timer = time.AfterFunc(g.spread, g.expire)
+	}
+}
+
+// expire fails waiting if any target is still pending when the window closes.
+func (g *spreadGuard) expire() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.pending) == 0 {
+		return
+	}
+
+	var stragglers []string
+	for _, name := range g.names {
+		if g.pending[name] {
+			stragglers = append(stragglers, name)
+		}
+	}
+
+	g.err = &giveUpError{
+		cause:    fmt.Errorf("%s not ready within %s of %s after %s", strings.Join(stragglers, ", "), envMaxSpread, g.spread, g.first),
+		outcome:  outcomeSpread,
+		exitCode: 1,
+	}
+	g.cancel()
+}
+
+// stop releases the timer of the window.
+func (g *spreadGuard) stop() {
+	if g == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+}
+
+// result returns the error if the targets did not become ready within the window.
+func (g *spreadGuard) result() error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.err
+}
+
+// validateMaxSpread checks if the maximum spread is valid.
+func validateMaxSpread(cfg Config) error {
+	if cfg.MaxSpread < 0 {
+		return fmt.Errorf("invalid %s value: spread cannot be negative", envMaxSpread)
+	}
+
+	if cfg.MaxSpread > 0 && len(cfg.Targets) == 0 {
+		return fmt.Errorf("%s requires multiple targets", envMaxSpread)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxSpread(t *testing.T) {
+	t.Run("Targets ready within spread", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    50 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			MaxSpread:   time.Second,
+			Targets: []Target{
+				{Name: "database", Address: newListener(t).Addr().String()},
+				{Name: "cache", Address: newListener(t).Addr().String()},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Straggler exceeds spread", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:    50 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			MaxSpread:   100 * time.Millisecond,
+			Targets: []Target{
+				{Name: "database", Address: newListener(t).Addr().String()},
+				{Name: "cache", Address: closedAddress(t)},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := waitForTargets(ctx, cfg, logger, nil)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "cache not ready within MAX_SPREAD of 100ms after database"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+		if outcome := waitOutcome(ctx, err); outcome != outcomeSpread {
+			t.Errorf("Expected outcome %q but got %q", outcomeSpread, outcome)
+		}
+	})
+}
+
+func TestValidateMaxSpread(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "Disabled",
+			cfg:  Config{},
+		},
+		{
+			name: "Multiple targets",
+			cfg:  Config{MaxSpread: time.Second, Targets: []Target{{Name: "database"}, {Name: "cache"}}},
+		},
+		{
+			name: "Negative spread",
+			cfg:  Config{MaxSpread: -time.Second},
+			err:  "invalid MAX_SPREAD value: spread cannot be negative",
+		},
+		{
+			name: "Single target",
+			cfg:  Config{MaxSpread: time.Second},
+			err:  "MAX_SPREAD requires multiple targets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateMaxSpread(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %q", tt.err, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// srvResolver looks up SRV records, implemented by *net.Resolver.
+type srvResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+func init() {
+	registerCheck(checkTypeSRV, func(cfg Config, dialer Dialer, logger *slog.Logger, _ captureFunc) checkFunc {
+		return newSRVCheck(cfg, net.DefaultResolver, dialer, logger)
+	})
+}
+
+// newSRVCheck returns a check that resolves the SRV record of the target, e.g. _postgres._tcp.example.com,
+// and connects to the endpoint it points to. The record is resolved on every attempt,
+// so a service that moves to another host or port while waiting is followed.
+func newSRVCheck(cfg Config, resolver srvResolver, dialer Dialer, logger *slog.Logger) checkFunc {
+	return func(ctx context.Context) error {
+		endpoint, err := resolveSRV(ctx, resolver, cfg.TargetAddress)
+		if err != nil {
+			return err
+		}
+
+		logger.Debug(fmt.Sprintf("%s resolved to %s", cfg.TargetAddress, endpoint), slog.String("endpoint", endpoint))
+
+		return CheckConnection(ctx, dialer, endpoint)
+	}
+}
+
+// resolveSRV resolves the SRV record and returns the host:port of the endpoint to dial.
+// The records are ordered by priority and randomized by weight, so the first one is picked.
+func resolveSRV(ctx context.Context, resolver srvResolver, name string) (string, error) {
+	_, records, err := resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("%s resolved to no SRV records", name)
+	}
+
+	record := records[0]
+	return net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))), nil
+}
@@ -0,0 +1,117 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSRVResolver returns fixed SRV records for any name.
+type fakeSRVResolver struct {
+	records []*net.SRV
+	err     error
+}
+
+func (r fakeSRVResolver) LookupSRV(_ context.Context, _, _, _ string) (string, []*net.SRV, error) {
+	return "", r.records, r.err
+}
+
+func TestSRVCheck(t *testing.T) {
+	t.Run("Endpoint is reachable", func(t *testing.T) {
+		t.Parallel()
+
+		_, port, _ := net.SplitHostPort(newListener(t).Addr().String())
+		portNum, _ := strconv.Atoi(port)
+		resolver := fakeSRVResolver{records: []*net.SRV{
+			{Target: "localhost.", Port: uint16(portNum), Priority: 10},
+			{Target: "backup.invalid.", Port: 5432, Priority: 20},
+		}}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		cfg := Config{TargetAddress: "_postgres._tcp.example.com", CheckType: checkTypeSRV}
+		if err := newSRVCheck(cfg, resolver, &net.Dialer{Timeout: time.Second}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "_postgres._tcp.example.com resolved to localhost:" + port
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Endpoint is not reachable", func(t *testing.T) {
+		t.Parallel()
+
+		host, port, _ := net.SplitHostPort(closedAddress(t))
+		portNum, _ := strconv.Atoi(port)
+		resolver := fakeSRVResolver{records: []*net.SRV{{Target: host, Port: uint16(portNum)}}}
+
+		cfg := Config{TargetAddress: "_postgres._tcp.example.com", CheckType: checkTypeSRV}
+		err := newSRVCheck(cfg, resolver, &net.Dialer{Timeout: time.Second}, slog.New(slog.NewTextHandler(io.Discard, nil)))(context.Background())
+		if classifyDialError(err) != dialErrorRefused {
+			t.Errorf("Expected a refused connection but got %v", err)
+		}
+	})
+
+	t.Run("No records", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetAddress: "_postgres._tcp.example.com", CheckType: checkTypeSRV}
+		err := newSRVCheck(cfg, fakeSRVResolver{}, &net.Dialer{}, slog.New(slog.NewTextHandler(io.Discard, nil)))(context.Background())
+		if err == nil || err.Error() != "_postgres._tcp.example.com resolved to no SRV records" {
+			t.Errorf("Expected no SRV records error but got %v", err)
+		}
+	})
+
+	t.Run("Lookup fails", func(t *testing.T) {
+		t.Parallel()
+
+		lookupErr := &net.DNSError{Err: "no such host", Name: "_postgres._tcp.example.com", IsNotFound: true}
+		cfg := Config{TargetAddress: "_postgres._tcp.example.com", CheckType: checkTypeSRV}
+		err := newSRVCheck(cfg, fakeSRVResolver{err: lookupErr}, &net.Dialer{}, slog.New(slog.NewTextHandler(io.Discard, nil)))(context.Background())
+
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			t.Errorf("Expected DNS error but got %v", err)
+		}
+	})
+}
+
+func TestValidateSRVAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		address  string
+		errorMsg string
+	}{
+		{name: "Record name", address: "_postgres._tcp.example.com"},
+		{name: "With port", address: "_postgres._tcp.example.com:5432", errorMsg: "invalid TARGET_ADDRESS format, must be an SRV record name without a port, e.g. _postgres._tcp.example.com"},
+		{name: "Schema", address: "srv://_postgres._tcp.example.com", errorMsg: "TARGET_ADDRESS should not include a schema (srv)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateAddress(envTargetAddress, checkTypeSRV, tt.address)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error %q but got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
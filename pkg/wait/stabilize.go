@@ -0,0 +1,73 @@
+package wait
+
+import (
+	"fmt"
+	"time"
+)
+
+const envStabilizeFor = "STABILIZE_FOR"
+
+// stabilizer requires a target to pass every check for a duration after its first successful check,
+// so a service that briefly accepts connections during startup and then bounces is not reported as ready.
+// A nil stabilizer reports the target as ready after the first successful check.
+type stabilizer struct {
+	duration time.Duration // How long the target must keep passing the checks.
+	since    time.Time     // When the current run of successful checks started, zero if none.
+}
+
+// newStabilizer returns the stabilizer for the configured duration, or nil if it is disabled.
+func newStabilizer(duration time.Duration) *stabilizer {
+	if duration <= 0 {
+		return nil
+	}
+	return &stabilizer{duration: duration}
+}
+
+// succeeded records a successful check and returns how long the target must still pass the checks, 0 once it is stable.
+// started reports whether the check started a new run of successful checks.
+func (s *stabilizer) succeeded(now time.Time) (remaining time.Duration, started bool) {
+	if s == nil {
+		return 0, false
+	}
+
+	if s.since.IsZero() {
+		s.since = now
+		return s.duration, true
+	}
+	return max(s.duration-now.Sub(s.since), 0), false
+}
+
+// failed resets the run of successful checks and reports whether one was running.
+func (s *stabilizer) failed() bool {
+	if s == nil || s.since.IsZero() {
+		return false
+	}
+	s.since = time.Time{}
+	return true
+}
+
+// validateStabilizeFor checks if the stabilization duration is valid and supported with the given configuration.
+func validateStabilizeFor(cfg Config) error {
+	if cfg.StabilizeFor < 0 {
+		return fmt.Errorf("invalid %s value: duration cannot be negative", envStabilizeFor)
+	}
+	if cfg.StabilizeFor == 0 {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envWindowSize, cfg.WindowSize > 0},
+		{envOneShot, cfg.OneShot},
+		{envWaitFor + "=" + waitForDown, cfg.WaitFor == waitForDown},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envStabilizeFor, conflict.env)
+		}
+	}
+
+	return nil
+}
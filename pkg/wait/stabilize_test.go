@@ -0,0 +1,115 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStabilizer(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	s := newStabilizer(10 * time.Second)
+
+	if remaining, started := s.succeeded(start); remaining != 10*time.Second || !started {
+		t.Errorf("Expected the first success to start the window but got %s remaining, started %t", remaining, started)
+	}
+	if remaining, started := s.succeeded(start.Add(4 * time.Second)); remaining != 6*time.Second || started {
+		t.Errorf("Expected 6s remaining but got %s, started %t", remaining, started)
+	}
+	if !s.failed() {
+		t.Error("Expected the failure to reset the running window")
+	}
+	if s.failed() {
+		t.Error("Expected no window to be running after the reset")
+	}
+	if remaining, started := s.succeeded(start.Add(5 * time.Second)); remaining != 10*time.Second || !started {
+		t.Errorf("Expected the success after a failure to restart the window but got %s remaining, started %t", remaining, started)
+	}
+	if remaining, _ := s.succeeded(start.Add(15 * time.Second)); remaining != 0 {
+		t.Errorf("Expected the target to be stable but got %s remaining", remaining)
+	}
+
+	var disabled *stabilizer
+	if remaining, _ := disabled.succeeded(start); remaining != 0 {
+		t.Errorf("Expected a disabled stabilizer to be stable right away but got %s remaining", remaining)
+	}
+}
+
+func TestStabilizeFor(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{TargetName: "database", Interval: 10 * time.Millisecond, StabilizeFor: 50 * time.Millisecond}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	// the target accepts the first connection during startup, then bounces once
+	results := []error{nil, errors.New("connection refused")}
+	var bounced time.Time
+	check := func(ctx context.Context) error {
+		if len(results) == 0 {
+			return nil
+		}
+		err := results[0]
+		results = results[1:]
+		if err != nil {
+			bounced = time.Now()
+		}
+		return err
+	}
+
+	if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(bounced); elapsed < cfg.StabilizeFor {
+		t.Errorf("Expected the target to stay ready for %s after bouncing but it was ready after %s", cfg.StabilizeFor, elapsed)
+	}
+
+	for _, expected := range []string{
+		"database check succeeded, waiting for it to stay ready for 50ms",
+		"database failed within STABILIZE_FOR of 50ms, waiting for it to stay ready again",
+		"database is ready ✓",
+	} {
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	}
+}
+
+func TestValidateStabilizeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{OneShot: true}},
+		{name: "Enabled", cfg: Config{StabilizeFor: 10 * time.Second, WaitFor: waitForUp}},
+		{name: "Negative", cfg: Config{StabilizeFor: -time.Second}, err: "invalid STABILIZE_FOR value: duration cannot be negative"},
+		{name: "Window", cfg: Config{StabilizeFor: time.Second, WindowSize: 5}, err: "STABILIZE_FOR cannot be combined with WINDOW_SIZE"},
+		{name: "One shot", cfg: Config{StabilizeFor: time.Second, OneShot: true}, err: "STABILIZE_FOR cannot be combined with ONE_SHOT"},
+		{name: "Wait for down", cfg: Config{StabilizeFor: time.Second, WaitFor: waitForDown}, err: "STABILIZE_FOR cannot be combined with WAIT_FOR=down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateStabilizeFor(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package wait
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const envStatusFile = "STATUS_FILE"
+
+// runStatus is the document written to STATUS_FILE once waiting finished.
+// A single target is described at the top level, multiple targets are listed in Targets.
+type runStatus struct {
+	Target    string         `json:"target,omitempty"`
+	Address   string         `json:"address,omitempty"`
+	Ready     bool           `json:"ready"`
+	Outcome   string         `json:"outcome"`
+	Attempts  int64          `json:"attempts"`
+	ElapsedMS int64          `json:"elapsed_ms"`
+	Targets   []targetStatus `json:"targets,omitempty"`
+}
+
+// targetStatus describes one of multiple targets in the status document.
+type targetStatus struct {
+	Target   string `json:"target"`
+	Address  string `json:"address,omitempty"`
+	Ready    bool   `json:"ready"`
+	Attempts int64  `json:"attempts"`
+}
+
+// newRunStatus returns the status of the run from the recorded metrics.
+func newRunStatus(cfg Config, m *metrics, outcome string, elapsed time.Duration) runStatus {
+	status := runStatus{
+		Ready:     outcome == outcomeReady,
+		Outcome:   outcome,
+		Attempts:  m.attempts(),
+		ElapsedMS: elapsed.Milliseconds(),
+	}
+
+	if len(cfg.Targets) == 0 {
+		status.Target, status.Address = cfg.TargetName, cfg.TargetAddress
+		return status
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		target := targetStatus{Target: targetCfg.TargetName, Address: targetCfg.TargetAddress}
+		if t := m.target(targetCfg.TargetName); t != nil {
+			m.mu.Lock()
+			target.Ready, target.Attempts = t.ready, t.attempts
+			m.mu.Unlock()
+		}
+		status.Targets = append(status.Targets, target)
+	}
+	return status
+}
+
+// writeStatusFile writes the status of the run to STATUS_FILE.
+// A failed write is logged as a warning, as it does not change whether the targets are ready.
+func writeStatusFile(cfg Config, m *metrics, outcome string, elapsed time.Duration, logger *slog.Logger) {
+	data, err := json.Marshal(newRunStatus(cfg, m, outcome, elapsed))
+	if err == nil {
+		err = writeFileAtomic(cfg.StatusFile, append(data, '\n'))
+	}
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to write %s", envStatusFile), slog.String("error", err.Error()))
+	}
+}
+
+// writeFileAtomic writes the data to a temporary file next to path and renames it,
+// so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // a no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,131 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunStatusFile(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		t.Parallel()
+
+		address := newListener(t).Addr().String()
+		statusFile := filepath.Join(t.TempDir(), "status.json")
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": address,
+			"STATUS_FILE":    statusFile,
+		}
+
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		status := readStatusFile(t, statusFile)
+		status.ElapsedMS = 0
+		expected := runStatus{Target: "database", Address: address, Ready: true, Outcome: outcomeReady, Attempts: 1}
+		if !reflect.DeepEqual(status, expected) {
+			t.Errorf("Expected status %+v but got %+v", expected, status)
+		}
+	})
+
+	t.Run("MAX_WAIT during INITIAL_DELAY", func(t *testing.T) {
+		t.Parallel()
+
+		statusFile := filepath.Join(t.TempDir(), "status.json")
+		env := map[string]string{
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"INITIAL_DELAY":  "5s",
+			"MAX_WAIT":       "100ms",
+			"STATUS_FILE":    statusFile,
+		}
+
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if status := readStatusFile(t, statusFile); status.Ready || status.Outcome != outcomeTimeout || status.Attempts != 0 {
+			t.Errorf("Expected the status to time out without attempts but got %+v", status)
+		}
+	})
+
+	t.Run("Given up with multiple targets", func(t *testing.T) {
+		t.Parallel()
+
+		ready, closed := newListener(t).Addr().String(), closedAddress(t)
+		statusFile := filepath.Join(t.TempDir(), "status.json")
+		env := map[string]string{
+			"TARGET_ADDRESS": ready + "," + closed,
+			"TARGET_NAME":    "database,cache",
+			"INTERVAL":       "10ms",
+			"MAX_RETRIES":    "1",
+			"STATUS_FILE":    statusFile,
+		}
+
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		status := readStatusFile(t, statusFile)
+		if status.Ready || status.Outcome != outcomeExhausted {
+			t.Errorf("Expected the status not to be ready but got %+v", status)
+		}
+		expected := []targetStatus{
+			{Target: "database", Address: ready, Ready: true, Attempts: 1},
+			{Target: "cache", Address: closed, Ready: false, Attempts: 2},
+		}
+		if !reflect.DeepEqual(status.Targets, expected) {
+			t.Errorf("Expected targets %+v but got %+v", expected, status.Targets)
+		}
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.json")
+
+	for _, data := range []string{`{"ready":false}`, `{"ready":true}`} {
+		if err := writeFileAtomic(path, []byte(data)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read status file: %v", err)
+	}
+	if string(content) != `{"ready":true}` {
+		t.Errorf("Expected the last write but got %q", content)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind but got %d entries", len(entries))
+	}
+}
+
+// readStatusFile reads and decodes the status file.
+func readStatusFile(t *testing.T, path string) runStatus {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read status file: %v", err)
+	}
+
+	var status runStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("Failed to decode status file %q: %v", data, err)
+	}
+	return status
+}
@@ -0,0 +1,50 @@
+package wait
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+const envSplitStreams = "SPLIT_STREAMS"
+
+// splitHandler writes the records reporting a problem to a separate handler,
+// so scripts can consume the ready and startup messages on their own.
+type splitHandler struct {
+	out slog.Handler // Receives the ready, startup and other informational records.
+	err slog.Handler // Receives the not ready records, warnings and errors.
+}
+
+// newStreamsHandler returns the log handler writing to output.
+// With SPLIT_STREAMS set, the not ready records, warnings and errors are written to errOutput instead.
+func newStreamsHandler(cfg Config, output, errOutput io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if !cfg.SplitStreams {
+		return newHandler(cfg, output, opts)
+	}
+	return &splitHandler{out: newHandler(cfg, output, opts), err: newHandler(cfg, errOutput, opts)}
+}
+
+// Enabled reports whether either stream handles records of the given level.
+func (h *splitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.out.Enabled(ctx, level) || h.err.Enabled(ctx, level)
+}
+
+// Handle writes the record to the stream matching its tone or level.
+// Not ready records go to the error stream even if LOG_ATTEMPT logs them at info level.
+func (h *splitHandler) Handle(ctx context.Context, r slog.Record) error {
+	tone, _ := ctx.Value(logToneKey{}).(logTone)
+	if r.Level >= slog.LevelWarn || tone == toneNotReady {
+		return h.err.Handle(ctx, r)
+	}
+	return h.out.Handle(ctx, r)
+}
+
+// WithAttrs returns a split handler with the given attributes on both streams.
+func (h *splitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &splitHandler{out: h.out.WithAttrs(attrs), err: h.err.WithAttrs(attrs)}
+}
+
+// WithGroup returns a split handler with the given group on both streams.
+func (h *splitHandler) WithGroup(name string) slog.Handler {
+	return &splitHandler{out: h.out.WithGroup(name), err: h.err.WithGroup(name)}
+}
@@ -0,0 +1,82 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitStreams(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		stdOutLogs []string
+		stdErrLogs []string
+	}{
+		{
+			name:       "Single stream",
+			cfg:        Config{LogAttempt: slog.LevelWarn},
+			stdOutLogs: []string{"Waiting for database to become ready...", "database is not ready ✗", "database is ready ✓"},
+		},
+		{
+			name:       "Split streams",
+			cfg:        Config{LogAttempt: slog.LevelWarn, SplitStreams: true},
+			stdOutLogs: []string{"Waiting for database to become ready...", "database is ready ✓"},
+			stdErrLogs: []string{"database is not ready ✗"},
+		},
+		{
+			name:       "Not ready at info level",
+			cfg:        Config{LogAttempt: slog.LevelInfo, SplitStreams: true},
+			stdOutLogs: []string{"Waiting for database to become ready...", "database is ready ✓"},
+			stdErrLogs: []string{"database is not ready ✗"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg
+			cfg.TargetName = "database"
+			cfg.CheckType = checkTypeTCP
+			cfg.LogFormat = logFormatText
+			cfg.Interval = 10 * time.Millisecond
+
+			var stdOut, stdErr strings.Builder
+			logger := setupLogger(cfg, &stdOut, &stdErr)
+
+			failures := 1
+			check := func(ctx context.Context) error {
+				if failures > 0 {
+					failures--
+					return errors.New("connection refused")
+				}
+				return nil
+			}
+
+			if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.stdOutLogs {
+				if !strings.Contains(stdOut.String(), expected) {
+					t.Errorf("Expected stdout to contain %q but got %q", expected, stdOut.String())
+				}
+			}
+			for _, expected := range tt.stdErrLogs {
+				if !strings.Contains(stdErr.String(), expected) {
+					t.Errorf("Expected stderr to contain %q but got %q", expected, stdErr.String())
+				}
+				if strings.Contains(stdOut.String(), expected) {
+					t.Errorf("Expected stdout not to contain %q but got %q", expected, stdOut.String())
+				}
+			}
+			if len(tt.stdErrLogs) == 0 && stdErr.Len() > 0 {
+				t.Errorf("Expected no output on stderr but got %q", stdErr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envTargets = "TARGETS"
+
+	envIndexedTargetName    = "TARGET_%d_NAME"
+	envIndexedTargetAddress = "TARGET_%d_ADDRESS"
+	envIndexedTargetType    = "TARGET_%d_TYPE"
+	envIndexedTargetTimeout = "TARGET_%d_TIMEOUT"
+
+	envStartupMessageMode = "STARTUP_MESSAGE_MODE"
+	envQuietStartup       = "QUIET_STARTUP"
+)
+
+const (
+	startupMessagePerTarget = "per-target" // Log a waiting message for every target.
+	startupMessageCombined  = "combined"   // Log a single waiting message listing all targets.
+)
+
+// Target holds the settings of a single target when waiting for multiple targets.
+// Empty fields fall back to the global configuration.
+type Target struct {
+	Name      string        // The name of the target.
+	Address   string        // The address of the target in the format 'host:port'.
+	CheckType string        // The type of check to perform against the target.
+	Timeout   time.Duration // The dial timeout of the target.
+}
+
+// parseIndexedTargets discovers targets defined via indexed environment variables
+// (TARGET_1_ADDRESS, TARGET_2_ADDRESS, ...). Scanning stops at the first index without an address.
+func parseIndexedTargets(getenv func(string) string) ([]Target, error) {
+	var targets []Target
+	for i := 1; ; i++ {
+		address := getenv(fmt.Sprintf(envIndexedTargetAddress, i))
+		if address == "" {
+			return targets, nil
+		}
+
+		target := Target{
+			Name:      getenv(fmt.Sprintf(envIndexedTargetName, i)),
+			Address:   address,
+			CheckType: getenv(fmt.Sprintf(envIndexedTargetType, i)),
+		}
+		if timeoutStr := getenv(fmt.Sprintf(envIndexedTargetTimeout, i)); timeoutStr != "" {
+			var err error
+			target.Timeout, err = time.ParseDuration(timeoutStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %s", fmt.Sprintf(envIndexedTargetTimeout, i), err)
+			}
+		}
+		targets = append(targets, target)
+	}
+}
+
+// parseTargets parses the semicolon-separated targets of TARGETS, e.g. 'db=tcp://db:5432,timeout=5s;api=http://api:8080/healthz'.
+// Each target is an optional name, an address whose scheme selects the check type, and comma-separated options.
+// http and https URLs are http checks, addresses without a scheme use the default check type.
+func parseTargets(value, defaultCheckType string) ([]Target, error) {
+	var targets []Target
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, ",")
+		target, err := parseTarget(strings.TrimSpace(fields[0]), defaultCheckType)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, option := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+			switch key {
+			case "timeout":
+				target.Timeout, err = time.ParseDuration(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s value: timeout of %s: %s", envTargets, target.Address, err)
+				}
+			default:
+				return nil, fmt.Errorf("invalid %s value: unsupported option %q of %s", envTargets, key, target.Address)
+			}
+		}
+
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("invalid %s value: no targets", envTargets)
+	}
+	return targets, nil
+}
+
+// parseTarget parses a single target of TARGETS without its options, e.g. 'db=tcp://db:5432'.
+func parseTarget(entry, defaultCheckType string) (Target, error) {
+	var target Target
+
+	// an equal sign after the scheme belongs to the address, e.g. to the query of a URL
+	if name, address, found := strings.Cut(entry, "="); found && !strings.ContainsAny(name, ":/") {
+		target.Name, entry = strings.TrimSpace(name), strings.TrimSpace(address)
+	}
+	if entry == "" {
+		return Target{}, fmt.Errorf("invalid %s value: %s has no address", envTargets, target.Name)
+	}
+
+	target.Address = entry
+	if scheme, address, found := strings.Cut(entry, "://"); found {
+		switch scheme {
+		case "http", "https":
+			target.CheckType = checkTypeHTTP
+		default:
+			if err := validateCheckType(envTargets, scheme); err != nil {
+				return Target{}, err
+			}
+			target.CheckType, target.Address = scheme, address
+		}
+	}
+
+	checkType := defaultCheckType
+	if target.CheckType != "" {
+		checkType = target.CheckType
+	}
+	if err := validateAddress(envTargets, checkType, target.Address); err != nil {
+		return Target{}, err
+	}
+
+	return target, nil
+}
+
+// splitTargetAddress turns a comma-separated TARGET_ADDRESS into one target per address.
+// TARGET_NAME may list one name per address in the same order, otherwise the names are inferred.
+func splitTargetAddress(cfg Config) ([]Target, error) {
+	addresses := strings.Split(cfg.TargetAddress, ",")
+
+	var names []string
+	if cfg.TargetName != "" {
+		names = strings.Split(cfg.TargetName, ",")
+		if len(names) != len(addresses) {
+			return nil, fmt.Errorf("%s must list one name per address of %s", envTargetName, envTargetAddress)
+		}
+	}
+
+	targets := make([]Target, 0, len(addresses))
+	for i, address := range addresses {
+		address = strings.TrimSpace(address)
+		if err := validateAddress(envTargetAddress, cfg.CheckType, address); err != nil {
+			return nil, err
+		}
+
+		target := Target{Address: address, Name: inferTargetName(address)}
+		if names != nil {
+			target.Name = strings.TrimSpace(names[i])
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// validateTarget checks if the indexed target is valid and infers its name if not set.
+// Targets without a check type use the given default check type.
+func validateTarget(index int, target *Target, defaultCheckType string) error {
+	checkType := defaultCheckType
+	if target.CheckType != "" {
+		if err := validateCheckType(fmt.Sprintf(envIndexedTargetType, index), target.CheckType); err != nil {
+			return err
+		}
+		checkType = target.CheckType
+	}
+
+	if err := validateAddress(fmt.Sprintf(envIndexedTargetAddress, index), checkType, target.Address); err != nil {
+		return err
+	}
+
+	if target.Timeout < 0 {
+		return fmt.Errorf("invalid %s value: timeout cannot be negative", fmt.Sprintf(envIndexedTargetTimeout, index))
+	}
+
+	if target.Name == "" {
+		target.Name = inferTargetName(target.Address)
+	}
+
+	return nil
+}
+
+// forTarget returns a copy of the configuration for the given target,
+// falling back to the global settings for fields the target does not set.
+func (cfg Config) forTarget(target Target) Config {
+	cfg.TargetName = target.Name
+	cfg.TargetAddress = target.Address
+	if target.CheckType != "" {
+		cfg.CheckType = target.CheckType
+	}
+	if target.Timeout > 0 {
+		cfg.DialTimeout = target.Timeout
+	}
+	cfg.Targets = nil
+
+	return cfg
+}
+
+// targetConfigs returns the configuration of every target to wait for.
+// Without indexed targets, it returns the configuration itself.
+func targetConfigs(cfg Config) []Config {
+	if len(cfg.Targets) == 0 {
+		return []Config{cfg}
+	}
+
+	configs := make([]Config, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		configs = append(configs, cfg.forTarget(target))
+	}
+	return configs
+}
+
+// validateStartupMessageMode checks if the startup message mode is valid.
+func validateStartupMessageMode(cfg *Config) error {
+	switch cfg.StartupMessageMode {
+	case "":
+		cfg.StartupMessageMode = startupMessagePerTarget
+	case startupMessagePerTarget, startupMessageCombined:
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envStartupMessageMode, startupMessagePerTarget, startupMessageCombined)
+	}
+	return nil
+}
+
+// targetLogger returns the logger for the given target.
+// With multiple targets, each target logs its own address as an extra field.
+func targetLogger(cfg Config, targetCfg Config, logger *slog.Logger) *slog.Logger {
+	if len(cfg.Targets) == 0 || !cfg.LogExtraFields {
+		return logger
+	}
+	return logger.With(slog.String("target_address", targetCfg.TargetAddress))
+}
+
+// WaitForTargets waits until all targets of the validated configuration are ready.
+// It returns nil once they are ready or the context is canceled, and an error if waiting was abandoned.
+func WaitForTargets(ctx context.Context, cfg Config, logger *slog.Logger) error {
+	return waitForTargets(ctx, cfg, logger, nil)
+}
+
+// waitForTargets waits until all configured targets are ready.
+// Without indexed targets, it waits for the single target of the configuration.
+// The first check is delayed by INITIAL_DELAY.
+// If m is not nil, every check across all targets is recorded in the metrics.
+func waitForTargets(ctx context.Context, cfg Config, logger *slog.Logger, m *metrics) error {
+	if err := delayFirstCheck(ctx, cfg.InitialDelay, logger); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil // Treat context cancellation as expected behavior
+		}
+		// MAX_WAIT elapsed before the first check, so there is no failure reason to pick the exit code
+		return &giveUpError{
+			cause:    err,
+			outcome:  outcomeTimeout,
+			exitCode: timeoutExitCode(cfg, exitCodeFor(cfg, "")),
+		}
+	}
+
+	if len(cfg.Targets) == 0 {
+		cfg.StartupMessageMode = startupMessagePerTarget // a single target has nothing to combine
+		target := m.target(cfg.TargetName)
+		if err := pollTarget(ctx, cfg, logger, target.observe(newTargetCheck(cfg, logger))); err != nil {
+			return err
+		}
+		target.setReady()
+		return nil
+	}
+
+	targets := targetConfigs(cfg)
+	limiter := newConcurrencyLimiter(cfg, len(targets))
+
+	if cfg.StartupMessageMode == startupMessageCombined && !cfg.QuietStartup {
+		names := make([]string, 0, len(targets))
+		for _, targetCfg := range targets {
+			names = append(names, targetCfg.TargetName)
+		}
+		quantifier := fmt.Sprintf("%d", len(targets))
+		switch required := requiredTargets(cfg, len(targets)); {
+		case cfg.Match == matchAny:
+			quantifier = fmt.Sprintf("any of %d", len(targets))
+		case required < len(targets):
+			quantifier = fmt.Sprintf("%d of %d", required, len(targets))
+		}
+		logger.Info(fmt.Sprintf("Waiting for %s targets to become ready: %s", quantifier, strings.Join(names, ", ")))
+	}
+
+	if required := requiredTargets(cfg, len(targets)); required < len(targets) {
+		return waitForQuorum(ctx, cfg, targets, required, limiter, logger, m)
+	}
+
+	// stop waiting for the other targets as soon as one target fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	spread := newSpreadGuard(cfg.MaxSpread, targets, cancel)
+	defer spread.stop()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(targets))
+
+	for i, targetCfg := range targets {
+		wg.Add(1)
+		go func(i int, targetCfg Config) {
+			defer wg.Done()
+			logger := targetLogger(cfg, targetCfg, logger)
+			target := m.target(targetCfg.TargetName)
+			check := target.observe(limiter.wrap(newTargetCheck(targetCfg, logger)))
+			errs[i] = pollTarget(ctx, targetCfg, logger, check)
+			if errs[i] != nil {
+				cancel()
+				return
+			}
+			target.setReady()
+			spread.ready(targetCfg.TargetName)
+		}(i, targetCfg)
+	}
+
+	wg.Wait()
+
+	if err := spread.result(); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// logStartupMatrix checks every target exactly once and logs which targets are already up.
+func logStartupMatrix(ctx context.Context, cfg Config, logger *slog.Logger) {
+	targets := targetConfigs(cfg)
+	limiter := newConcurrencyLimiter(cfg, len(targets))
+
+	var wg sync.WaitGroup
+	states := make([]string, len(targets))
+
+	for i, targetCfg := range targets {
+		wg.Add(1)
+		go func(i int, targetCfg Config) {
+			defer wg.Done()
+
+			states[i] = "up"
+			if err := limiter.wrap(newTargetCheck(targetCfg, logger))(ctx); err != nil {
+				states[i] = "down"
+			}
+		}(i, targetCfg)
+	}
+
+	wg.Wait()
+
+	attrs := make([]any, 0, len(targets))
+	for i, targetCfg := range targets {
+		attrs = append(attrs, slog.String(targetCfg.TargetName, states[i]))
+	}
+
+	logger.Info("Startup connectivity matrix", attrs...)
+}
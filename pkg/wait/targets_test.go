@@ -0,0 +1,571 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseIndexedTargets(t *testing.T) {
+	t.Run("Stop at first missing index", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_1_NAME":    "database",
+			"TARGET_1_ADDRESS": "postgres:5432",
+			"TARGET_2_ADDRESS": "valkey:6379",
+			"TARGET_2_TYPE":    "tcp",
+			"TARGET_2_TIMEOUT": "5s",
+			"TARGET_4_ADDRESS": "kafka:9092",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(getenv)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []Target{
+			{Name: "database", Address: "postgres:5432"},
+			{Address: "valkey:6379", CheckType: "tcp", Timeout: 5 * time.Second},
+		}
+		if !reflect.DeepEqual(cfg.Targets, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, cfg.Targets)
+		}
+	})
+
+	t.Run("No indexed targets", func(t *testing.T) {
+		t.Parallel()
+
+		targets, err := parseIndexedTargets(func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if targets != nil {
+			t.Errorf("Expected no targets but got %+v", targets)
+		}
+	})
+
+	t.Run("Invalid timeout", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{"TARGET_1_ADDRESS": "postgres:5432", "TARGET_1_TIMEOUT": "5"}
+		_, err := parseIndexedTargets(func(key string) string { return env[key] })
+
+		expected := `invalid TARGET_1_TIMEOUT value: time: missing unit in duration "5"`
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []Target
+		err      string
+	}{
+		{
+			name:  "Check types and timeouts",
+			value: "db=tcp://db:5432,timeout=5s; api=http://api:8080/healthz?verbose=true;cert=tls://api:8443",
+			expected: []Target{
+				{Name: "db", Address: "db:5432", CheckType: checkTypeTCP, Timeout: 5 * time.Second},
+				{Name: "api", Address: "http://api:8080/healthz?verbose=true", CheckType: checkTypeHTTP},
+				{Name: "cert", Address: "api:8443", CheckType: checkTypeTLS},
+			},
+		},
+		{
+			name:     "Without name and scheme",
+			value:    "valkey:6379;",
+			expected: []Target{{Address: "valkey:6379"}},
+		},
+		{
+			name:     "URL without name",
+			value:    "https://api:8443/healthz?a=b",
+			expected: []Target{{Address: "https://api:8443/healthz?a=b", CheckType: checkTypeHTTP}},
+		},
+		{name: "Empty", value: " ; ", err: "invalid TARGETS value: no targets"},
+		{name: "Unsupported scheme", value: "mail=smtp://mail:25", err: `invalid TARGETS value: unsupported check type "smtp"`},
+		{name: "Missing port", value: "db=tcp://db", err: "invalid TARGETS format, must be host:port"},
+		{name: "Missing address", value: "db=", err: "invalid TARGETS value: db has no address"},
+		{name: "Invalid timeout", value: "db=db:5432,timeout=fast", err: `invalid TARGETS value: timeout of db:5432: time: invalid duration "fast"`},
+		{name: "Unsupported option", value: "db=db:5432,retries=3", err: `invalid TARGETS value: unsupported option "retries" of db:5432`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			targets, err := parseTargets(tt.value, checkTypeTCP)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(targets, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, targets)
+			}
+		})
+	}
+}
+
+func TestParseConfigTargets(t *testing.T) {
+	t.Run("Per-target settings", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGETS":      "db=tcp://db:5432,timeout=5s;api=http://api:8080/healthz",
+			"DIAL_TIMEOUT": "2s",
+		}
+		cfg, err := ParseConfig(func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := ValidateConfig(&cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		configs := targetConfigs(cfg)
+		if len(configs) != 2 {
+			t.Fatalf("Expected 2 targets but got %d", len(configs))
+		}
+		if configs[0].CheckType != checkTypeTCP || configs[0].DialTimeout != 5*time.Second {
+			t.Errorf("Expected db to be a tcp check with a 5s timeout but got %s with %s", configs[0].CheckType, configs[0].DialTimeout)
+		}
+		if configs[1].CheckType != checkTypeHTTP || configs[1].DialTimeout != 2*time.Second {
+			t.Errorf("Expected api to be a http check with the global timeout but got %s with %s", configs[1].CheckType, configs[1].DialTimeout)
+		}
+	})
+
+	t.Run("Combined with TARGET_ADDRESS", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{"TARGETS": "db=tcp://db:5432", "TARGET_ADDRESS": "valkey:6379"}
+		_, err := ParseConfig(func(key string) string { return env[key] })
+
+		expected := "TARGETS cannot be combined with TARGET_ADDRESS"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Combined with indexed targets", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{"TARGETS": "db=tcp://db:5432", "TARGET_1_ADDRESS": "valkey:6379"}
+		_, err := ParseConfig(func(key string) string { return env[key] })
+
+		expected := "TARGETS cannot be combined with TARGET_1_ADDRESS"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestValidateIndexedTargets(t *testing.T) {
+	t.Run("Infer target names", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Targets: []Target{
+				{Address: "postgres.default.svc.cluster.local:5432"},
+				{Name: "cache", Address: "valkey:6379"},
+			},
+		}
+
+		if err := ValidateConfig(&cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.Targets[0].Name != "postgres" {
+			t.Errorf("Expected target name %q but got %q", "postgres", cfg.Targets[0].Name)
+		}
+
+		if cfg.Targets[1].Name != "cache" {
+			t.Errorf("Expected target name %q but got %q", "cache", cfg.Targets[1].Name)
+		}
+	})
+
+	t.Run("Invalid indexed address", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Targets: []Target{
+				{Address: "postgres:5432"},
+				{Address: "valkey"},
+			},
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid TARGET_2_ADDRESS format, must be host:port"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid indexed type", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Targets: []Target{
+				{Address: "postgres:5432", CheckType: "smtp"},
+			},
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid TARGET_1_TYPE value: unsupported check type \"smtp\""
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Combined with TARGET_ADDRESS", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetAddress: "postgres:5432",
+			Targets:       []Target{{Address: "valkey:6379"}},
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "TARGET_ADDRESS cannot be combined with TARGET_1_ADDRESS"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid startup message mode", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Targets:            []Target{{Address: "valkey:6379"}},
+			StartupMessageMode: "all",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid STARTUP_MESSAGE_MODE value: must be one of per-target, combined"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestSplitTargetAddress(t *testing.T) {
+	t.Run("Infer target names", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetAddress: "db.default.svc:5432, cache:6379,broker:9092", CheckType: checkTypeTCP}
+		if err := ValidateConfig(&cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []Target{
+			{Name: "db", Address: "db.default.svc:5432"},
+			{Name: "cache", Address: "cache:6379"},
+			{Name: "broker", Address: "broker:9092"},
+		}
+		if !reflect.DeepEqual(cfg.Targets, expected) {
+			t.Errorf("Expected targets %+v but got %+v", expected, cfg.Targets)
+		}
+
+		if cfg.TargetAddress != "" || cfg.TargetName != "" {
+			t.Errorf("Expected TARGET_ADDRESS to be replaced by targets but got %q (%q)", cfg.TargetAddress, cfg.TargetName)
+		}
+	})
+
+	t.Run("Names per address", func(t *testing.T) {
+		t.Parallel()
+
+		targets, err := splitTargetAddress(Config{TargetName: "Postgres,Valkey", TargetAddress: "db:5432,cache:6379", CheckType: checkTypeTCP})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if targets[0].Name != "Postgres" || targets[1].Name != "Valkey" {
+			t.Errorf("Expected names %q and %q but got %+v", "Postgres", "Valkey", targets)
+		}
+	})
+
+	t.Run("Name count mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := splitTargetAddress(Config{TargetName: "Postgres", TargetAddress: "db:5432,cache:6379", CheckType: checkTypeTCP})
+
+		expected := "TARGET_NAME must list one name per address of TARGET_ADDRESS"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := splitTargetAddress(Config{TargetAddress: "db:5432,cache", CheckType: checkTypeTCP})
+
+		expected := "invalid TARGET_ADDRESS format, must be host:port"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestWaitForTargets(t *testing.T) {
+	t.Run("All targets are ready", func(t *testing.T) {
+		t.Parallel()
+
+		var targets []Target
+		for _, name := range []string{"database", "cache"} {
+			targets = append(targets, Target{Name: name, Address: newListener(t).Addr().String()})
+		}
+
+		cfg := Config{
+			Interval:    50 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			Targets:     targets,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		for _, target := range targets {
+			expected := fmt.Sprintf("%s is ready ✓", target.Name)
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+
+	t.Run("Combined startup message", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:           50 * time.Millisecond,
+			DialTimeout:        50 * time.Millisecond,
+			CheckType:          checkTypeTCP,
+			StartupMessageMode: startupMessageCombined,
+			Targets: []Target{
+				{Name: "database", Address: newListener(t).Addr().String()},
+				{Name: "cache", Address: newListener(t).Addr().String()},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := `msg="Waiting for 2 targets to become ready: database, cache"`
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		if strings.Contains(stdOut.String(), "Waiting for database") {
+			t.Errorf("Expected no per-target waiting message but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("One target is not ready", func(t *testing.T) {
+		t.Parallel()
+
+		lis := newListener(t)
+
+		cfg := Config{
+			Interval:    50 * time.Millisecond,
+			DialTimeout: 50 * time.Millisecond,
+			CheckType:   checkTypeTCP,
+			Targets: []Target{
+				{Name: "database", Address: lis.Addr().String()},
+				{Name: "cache", Address: closedAddress(t)},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		err := waitForTargets(ctx, cfg, logger, nil)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if code := ExitCode(err); code != defaultExitCodeConnection {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+		}
+
+		expected := "cache is not ready ✗"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+}
+
+func TestWaitForTargetsConcurrently(t *testing.T) {
+	const targets = 5
+	const delay = 200 * time.Millisecond
+
+	tests := []struct {
+		name           string
+		maxConcurrency int
+		atLeast        time.Duration
+		atMost         time.Duration
+	}{
+		{name: "All targets in parallel", atLeast: delay, atMost: 3 * delay},
+		{name: "Bounded by MAX_CONCURRENCY", maxConcurrency: 1, atLeast: targets * delay, atMost: time.Duration(targets+2) * delay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfgTargets []Target
+			for i := range targets {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(delay)
+				}))
+				t.Cleanup(server.Close)
+				cfgTargets = append(cfgTargets, Target{Name: fmt.Sprintf("api-%d", i), Address: server.URL})
+			}
+
+			cfg := Config{
+				Interval:       time.Second,
+				DialTimeout:    time.Second,
+				CheckType:      checkTypeHTTP,
+				HTTPMethod:     http.MethodGet,
+				MaxConcurrency: tt.maxConcurrency,
+				Targets:        cfgTargets,
+			}
+			logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+			start := time.Now()
+			if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.atLeast || elapsed > tt.atMost {
+				t.Errorf("Expected %d targets delayed by %s each to be ready within %s to %s but took %s", targets, delay, tt.atLeast, tt.atMost, elapsed)
+			}
+		})
+	}
+}
+
+func TestQuietStartup(t *testing.T) {
+	t.Run("Single target", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"QUIET_STARTUP":  "true",
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, &stdOut, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		stdOutEntries := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+		if !strings.Contains(stdOutEntries[0], "database is ready ✓") {
+			t.Errorf("Expected the ready line first but got %q", stdOutEntries[0])
+		}
+		if strings.Contains(stdOut.String(), "Waiting for") {
+			t.Errorf("Expected no waiting message but got %q", stdOut.String())
+		}
+	})
+
+	t.Run("Multiple targets combined", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			Interval:           50 * time.Millisecond,
+			DialTimeout:        50 * time.Millisecond,
+			CheckType:          checkTypeTCP,
+			StartupMessageMode: startupMessageCombined,
+			QuietStartup:       true,
+			Targets: []Target{
+				{Name: "database", Address: newListener(t).Addr().String()},
+				{Name: "cache", Address: newListener(t).Addr().String()},
+			},
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if strings.Contains(stdOut.String(), "Waiting for") {
+			t.Errorf("Expected no waiting message but got %q", stdOut.String())
+		}
+		for _, expected := range []string{"database is ready ✓", "cache is ready ✓"} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+}
+
+func TestLogStartupMatrix(t *testing.T) {
+	t.Parallel()
+
+	lis := newListener(t)
+
+	cfg := Config{
+		DialTimeout: 50 * time.Millisecond,
+		CheckType:   checkTypeTCP,
+		Targets: []Target{
+			{Name: "database", Address: lis.Addr().String()},
+			{Name: "cache", Address: closedAddress(t)},
+		},
+	}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	logStartupMatrix(context.Background(), cfg, logger)
+
+	expected := `msg="Startup connectivity matrix" database=up cache=down`
+	if !strings.Contains(stdOut.String(), expected) {
+		t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+	}
+}
@@ -0,0 +1,77 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime"
+)
+
+const envLogTCPMSS = "LOG_TCP_MSS"
+
+// minTCPMSS is the default MSS every IPv4 host must accept (RFC 1122).
+// A smaller negotiated MSS hints at a broken MTU or path MTU discovery on the way to the target.
+const minTCPMSS = 536
+
+// newTCPMSSCheck returns a tcp check logging the MSS negotiated for each successful connection.
+// The MSS is only logged, it does not affect whether the target is ready.
+func newTCPMSSCheck(cfg Config, dialer Dialer, logger *slog.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		conn, err := dialer.DialContext(ctx, "tcp", cfg.TargetAddress)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		mss, err := tcpMSS(conn.(*net.TCPConn))
+		if err != nil {
+			logger.Warn(fmt.Sprintf("failed to read TCP MSS of %s", cfg.TargetName), slog.String("error", err.Error()))
+			return nil
+		}
+
+		if mss < minTCPMSS {
+			logger.Warn(fmt.Sprintf("%s negotiated an unusually small TCP MSS of %d, check the MTU on the path", cfg.TargetName, mss), slog.Int("mss", mss))
+			return nil
+		}
+
+		logger.Info(fmt.Sprintf("%s negotiated a TCP MSS of %d", cfg.TargetName, mss), slog.Int("mss", mss))
+		return nil
+	}
+}
+
+// validateTCPMSS checks if logging the TCP MSS is supported with the given configuration.
+func validateTCPMSS(cfg Config) error {
+	if !cfg.LogTCPMSS {
+		return nil
+	}
+
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("%s is only supported on Linux", envLogTCPMSS)
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType != checkTypeTCP {
+			return fmt.Errorf("%s can only be used with check type %q", envLogTCPMSS, checkTypeTCP)
+		}
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envProtocol, cfg.Protocol == protocolUDP},
+		{envConcurrentConns, cfg.ConcurrentConns > 0},
+		{envReverseCheckListen, cfg.ReverseCheckListen != ""},
+		{envProbeSend, len(cfg.ProbeSend) > 0},
+		{envProbeExpect, len(cfg.ProbeExpect) > 0},
+		{envCaptureResponseFile, cfg.CaptureResponseFile != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envLogTCPMSS, conflict.env)
+		}
+	}
+
+	return nil
+}
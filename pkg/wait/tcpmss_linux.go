@@ -0,0 +1,24 @@
+package wait
+
+import (
+	"net"
+	"syscall"
+)
+
+// tcpMSS returns the maximum segment size negotiated for the connection.
+func tcpMSS(conn *net.TCPConn) (int, error) {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var mss int
+	var sockErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		mss, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_MAXSEG)
+	}); err != nil {
+		return 0, err
+	}
+
+	return mss, sockErr
+}
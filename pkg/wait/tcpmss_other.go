@@ -0,0 +1,13 @@
+//go:build !linux
+
+package wait
+
+import (
+	"errors"
+	"net"
+)
+
+// tcpMSS is not supported outside of Linux.
+func tcpMSS(conn *net.TCPConn) (int, error) {
+	return 0, errors.New("reading the TCP MSS is only supported on Linux")
+}
@@ -0,0 +1,82 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTCPMSSCheck(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reading the TCP MSS is only supported on Linux")
+	}
+
+	t.Run("Logs MSS", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "postgres", TargetAddress: newListener(t).Addr().String(), LogTCPMSS: true}
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		if err := newTCPMSSCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "postgres negotiated a TCP MSS of "
+		if !strings.Contains(output.String(), expected) || !strings.Contains(output.String(), "mss=") {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+
+	t.Run("Connection fails", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetName: "postgres", TargetAddress: closedAddress(t), LogTCPMSS: true}
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+		if err := newTCPMSSCheck(cfg, &net.Dialer{Timeout: time.Second}, logger)(context.Background()); err == nil {
+			t.Error("Expected error but got nil")
+		}
+	})
+}
+
+func TestValidateTCPMSS(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reading the TCP MSS is only supported on Linux")
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{CheckType: checkTypeTLS}},
+		{name: "Plain TCP", cfg: Config{CheckType: checkTypeTCP, LogTCPMSS: true}},
+		{name: "TLS check", cfg: Config{CheckType: checkTypeTLS, LogTCPMSS: true}, err: `LOG_TCP_MSS can only be used with check type "tcp"`},
+		{name: "UDP", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, LogTCPMSS: true}, err: "LOG_TCP_MSS cannot be combined with PROTOCOL"},
+		{name: "Probe", cfg: Config{CheckType: checkTypeTCP, ProbeExpect: []byte("OK"), LogTCPMSS: true}, err: "LOG_TCP_MSS cannot be combined with PROBE_EXPECT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTCPMSS(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package wait
+
+import "fmt"
+
+const envSuccessThreshold = "SUCCESS_THRESHOLD"
+
+// successStreak counts consecutive successful checks, so a single success of a flapping target does not count as ready.
+// A nil streak reports the target as ready after the first successful check.
+type successStreak struct {
+	threshold int // How many consecutive checks must succeed.
+	count     int // The number of consecutive successful checks so far.
+}
+
+// newSuccessStreak returns the streak for the configured threshold, or nil if a single successful check is enough.
+func newSuccessStreak(threshold int) *successStreak {
+	if threshold <= 1 {
+		return nil
+	}
+	return &successStreak{threshold: threshold}
+}
+
+// succeeded records a successful check and reports whether the threshold was reached.
+func (s *successStreak) succeeded() bool {
+	if s == nil {
+		return true
+	}
+	s.count++
+	return s.count >= s.threshold
+}
+
+// failed resets the count of consecutive successful checks.
+func (s *successStreak) failed() {
+	if s != nil {
+		s.count = 0
+	}
+}
+
+// progress returns the number of consecutive successful checks and the threshold, e.g. "2/3".
+func (s *successStreak) progress() string {
+	return fmt.Sprintf("%d/%d", s.count, s.threshold)
+}
+
+// validateSuccessThreshold checks if the success threshold is valid and supported with the given configuration.
+func validateSuccessThreshold(cfg Config) error {
+	if cfg.SuccessThreshold < 0 {
+		return fmt.Errorf("invalid %s value: threshold cannot be negative", envSuccessThreshold)
+	}
+	if cfg.SuccessThreshold <= 1 {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envWindowSize, cfg.WindowSize > 0},
+		{envOneShot, cfg.OneShot},
+		{envWaitFor + "=" + waitForDown, cfg.WaitFor == waitForDown},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envSuccessThreshold, conflict.env)
+		}
+	}
+
+	return nil
+}
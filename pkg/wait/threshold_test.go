@@ -0,0 +1,110 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuccessStreak(t *testing.T) {
+	t.Parallel()
+
+	s := newSuccessStreak(3)
+
+	if s.succeeded() || s.succeeded() {
+		t.Fatal("Expected the threshold not to be reached after 2 successful checks")
+	}
+	if progress := s.progress(); progress != "2/3" {
+		t.Errorf("Expected progress 2/3 but got %s", progress)
+	}
+
+	s.failed()
+	if progress := s.progress(); progress != "0/3" {
+		t.Errorf("Expected the failure to reset the progress but got %s", progress)
+	}
+
+	if s.succeeded() || s.succeeded() || !s.succeeded() {
+		t.Error("Expected the threshold to be reached after 3 consecutive successful checks")
+	}
+
+	if newSuccessStreak(1) != nil {
+		t.Error("Expected a threshold of 1 to disable the streak")
+	}
+	var disabled *successStreak
+	if !disabled.succeeded() {
+		t.Error("Expected a disabled streak to be reached after the first successful check")
+	}
+}
+
+func TestSuccessThreshold(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{TargetName: "database", Interval: 10 * time.Millisecond, SuccessThreshold: 3}
+
+	var stdOut strings.Builder
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	// the target flaps once before passing three checks in a row
+	results := []error{nil, nil, errors.New("connection refused"), nil, nil, nil}
+	var attempts int
+	check := func(ctx context.Context) error {
+		err := results[attempts]
+		attempts++
+		return err
+	}
+
+	if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attempts != len(results) {
+		t.Errorf("Expected %d attempts but got %d", len(results), attempts)
+	}
+	for _, expected := range []string{
+		"database healthy check 1/3",
+		"database healthy check 2/3",
+		"database is ready ✓",
+	} {
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	}
+	if count := strings.Count(stdOut.String(), "database healthy check 2/3"); count != 2 {
+		t.Errorf("Expected the progress to restart after the failure but got %d times 2/3 in %q", count, stdOut.String())
+	}
+}
+
+func TestValidateSuccessThreshold(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Default", cfg: Config{SuccessThreshold: 1, OneShot: true}},
+		{name: "Enabled", cfg: Config{SuccessThreshold: 3, WaitFor: waitForUp}},
+		{name: "Negative", cfg: Config{SuccessThreshold: -1}, err: "invalid SUCCESS_THRESHOLD value: threshold cannot be negative"},
+		{name: "Window", cfg: Config{SuccessThreshold: 3, WindowSize: 5}, err: "SUCCESS_THRESHOLD cannot be combined with WINDOW_SIZE"},
+		{name: "One shot", cfg: Config{SuccessThreshold: 3, OneShot: true}, err: "SUCCESS_THRESHOLD cannot be combined with ONE_SHOT"},
+		{name: "Wait for down", cfg: Config{SuccessThreshold: 3, WaitFor: waitForDown}, err: "SUCCESS_THRESHOLD cannot be combined with WAIT_FOR=down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSuccessThreshold(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+)
+
+const envTLSHandshakeRetries = "TLS_HANDSHAKE_RETRIES"
+
+// handshakeRetrier bounds how often a failed TLS handshake is retried within a single attempt.
+type handshakeRetrier struct {
+	retries int                        // How often a failed handshake is retried.
+	onRetry func(retry int, err error) // Called before each retry, may be nil.
+}
+
+// newHandshakeRetrier returns the handshake retrier of the target, logging each retry.
+func newHandshakeRetrier(cfg Config, logger *slog.Logger) handshakeRetrier {
+	return handshakeRetrier{
+		retries: cfg.TLSHandshakeRetries,
+		onRetry: func(retry int, err error) {
+			logger.Warn(fmt.Sprintf("%s TLS handshake failed, retrying (%d/%d)", cfg.TargetName, retry, cfg.TLSHandshakeRetries), "error", err.Error())
+		},
+	}
+}
+
+// dialTLS establishes a connection to the given address and completes a TLS handshake.
+// A failed handshake is retried on a fresh connection, while a failed connect fails immediately.
+// The dial timeout bounds the connect and the handshake of each try.
+func dialTLS(ctx context.Context, dialer Dialer, network, address string, tlsConfig *tls.Config, retrier handshakeRetrier) (*tls.Conn, error) {
+	config := tlsConfig
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	for retry := 0; ; retry++ {
+		conn, connected, err := dialTLSOnce(ctx, dialer, network, address, config)
+		if err == nil {
+			return conn, nil
+		}
+
+		if !connected || retry >= retrier.retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		if retrier.onRetry != nil {
+			retrier.onRetry(retry+1, err)
+		}
+	}
+}
+
+// dialTLSOnce connects to the address and completes a TLS handshake.
+// It reports whether the connect succeeded, so only handshake failures are retried.
+func dialTLSOnce(ctx context.Context, dialer Dialer, network, address string, config *tls.Config) (*tls.Conn, bool, error) {
+	if timeout := dialTimeout(dialer); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	rawConn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn := tls.Client(rawConn, config)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		return nil, true, err
+	}
+
+	return conn, true, nil
+}
+
+// validateTLSHandshakeRetries checks if the handshake retry setting is valid.
+func validateTLSHandshakeRetries(cfg Config) error {
+	if cfg.TLSHandshakeRetries < 0 {
+		return fmt.Errorf("invalid %s value: retries cannot be negative", envTLSHandshakeRetries)
+	}
+	return nil
+}
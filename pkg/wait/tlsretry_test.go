@@ -0,0 +1,108 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// dropListener closes the first connections right after accepting them, failing their TLS handshakes.
+type dropListener struct {
+	net.Listener
+	drop atomic.Int32
+}
+
+func (l *dropListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.drop.Add(-1) < 0 {
+			return conn, nil
+		}
+		conn.Close()
+	}
+}
+
+// newFlakyTLSServer starts a TLS server failing the handshakes of the first connections.
+func newFlakyTLSServer(t *testing.T, drop int32) (string, *x509.CertPool) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	lis := &dropListener{Listener: server.Listener}
+	lis.drop.Store(drop)
+	server.Listener = lis
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	return lis.Addr().String(), pool
+}
+
+func TestDialTLS(t *testing.T) {
+	t.Run("Retry failed handshakes", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newFlakyTLSServer(t, 2)
+
+		var retries []int
+		retrier := handshakeRetrier{retries: 2, onRetry: func(retry int, err error) {
+			retries = append(retries, retry)
+		}}
+
+		conn, err := dialTLS(context.Background(), &net.Dialer{Timeout: time.Second}, "tcp", address, &tls.Config{RootCAs: pool}, retrier)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		conn.Close()
+
+		if len(retries) != 2 {
+			t.Errorf("Expected %d retries but got %v", 2, retries)
+		}
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		address, pool := newFlakyTLSServer(t, 2)
+
+		var retries int
+		retrier := handshakeRetrier{retries: 1, onRetry: func(retry int, err error) {
+			retries++
+		}}
+
+		if _, err := dialTLS(context.Background(), &net.Dialer{Timeout: time.Second}, "tcp", address, &tls.Config{RootCAs: pool}, retrier); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if retries != 1 {
+			t.Errorf("Expected %d retries but got %d", 1, retries)
+		}
+	})
+
+	t.Run("Failed connect is not retried", func(t *testing.T) {
+		t.Parallel()
+
+		var retries int
+		retrier := handshakeRetrier{retries: 3, onRetry: func(retry int, err error) {
+			retries++
+		}}
+
+		if _, err := dialTLS(context.Background(), &net.Dialer{Timeout: time.Second}, "tcp", closedAddress(t), &tls.Config{}, retrier); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if retries != 0 {
+			t.Errorf("Expected no retries but got %d", retries)
+		}
+	})
+}
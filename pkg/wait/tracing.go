@@ -0,0 +1,272 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const envOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// otlpExportTimeout bounds exporting the spans, so an unreachable collector does not delay exiting.
+const otlpExportTimeout = 5 * time.Second
+
+// otlpServiceName is the service name the spans are reported with.
+const otlpServiceName = "taco"
+
+// OTLP span kinds and status codes, see the OpenTelemetry protocol.
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusCodeOK     = 1
+	otlpStatusCodeError  = 2
+)
+
+// tracer collects the finished spans of a run, which are exported at once when it ends.
+type tracer struct {
+	endpoint string
+	client   *http.Client
+
+	mu    sync.Mutex
+	spans []otlpSpan
+}
+
+// span is a running span. A nil span records nothing.
+type span struct {
+	tracer   *tracer
+	name     string
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	start    time.Time
+	attrs    []otlpKeyValue
+}
+
+type tracerKey struct{}
+
+type spanKey struct{}
+
+// newTracer returns a tracer exporting the spans to the OTLP endpoint.
+func newTracer(endpoint string, client *http.Client) *tracer {
+	return &tracer{endpoint: endpoint, client: client}
+}
+
+// withTracer returns a context recording the spans started with it in the tracer.
+func withTracer(ctx context.Context, t *tracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// startSpan starts a span as a child of the span of the context.
+// Without a tracer in the context, it returns a nil span and the unchanged context.
+func startSpan(ctx context.Context, name string, attrs ...otlpKeyValue) (context.Context, *span) {
+	t, _ := ctx.Value(tracerKey{}).(*tracer)
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &span{tracer: t, name: name, start: time.Now(), attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(s.traceID[:])
+	}
+	_, _ = rand.Read(s.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// end ends the span. An error is recorded as an exception event and sets the status of the span to error.
+func (s *span) end(err error) {
+	if s == nil {
+		return
+	}
+
+	now := time.Now()
+	finished := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(now.UnixNano(), 10),
+		Attributes:        s.attrs,
+		Status:            otlpStatus{Code: otlpStatusCodeOK},
+	}
+	if s.parentID != ([8]byte{}) {
+		finished.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if err != nil {
+		finished.Status = otlpStatus{Code: otlpStatusCodeError, Message: err.Error()}
+		finished.Events = []otlpEvent{{
+			TimeUnixNano: finished.EndTimeUnixNano,
+			Name:         "exception",
+			Attributes:   []otlpKeyValue{otlpString("exception.message", err.Error())},
+		}}
+	}
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.spans = append(s.tracer.spans, finished)
+}
+
+// traceAttempts wraps the check to record every attempt as a child span of the span of the context.
+func traceAttempts(check checkFunc) checkFunc {
+	var attempt int64
+	return func(ctx context.Context) error {
+		attempt++
+		ctx, s := startSpan(ctx, "attempt", otlpInt("taco.attempt", attempt))
+		err := check(ctx)
+		s.end(err)
+		return err
+	}
+}
+
+// endSpan ends the span of a wait. A wait that returned without an error because its context was
+// canceled is recorded with the context error, so the span does not claim the target became ready.
+func endSpan(ctx context.Context, s *span, err error) {
+	if err == nil {
+		err = ctx.Err()
+	}
+	s.end(err)
+}
+
+// export sends the finished spans to the OTLP endpoint as JSON over HTTP.
+// A failed export is only logged, as tracing must not affect the outcome of the wait.
+func (t *tracer) export(ctx context.Context, logger *slog.Logger) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	if err := t.post(ctx, spans); err != nil {
+		logger.Warn("Failed to export traces", slog.String("endpoint", t.endpoint), slog.String("error", err.Error()))
+	}
+}
+
+// post sends the spans to the traces path of the OTLP endpoint.
+func (t *tracer) post(ctx context.Context, spans []otlpSpan) error {
+	body, err := json.Marshal(otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpKeyValue{otlpString("service.name", otlpServiceName)}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: otlpServiceName}, Spans: spans}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(t.endpoint, "/")+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateOTLPEndpoint checks if the OTLP endpoint is a valid URL.
+func validateOTLPEndpoint(cfg Config) error {
+	if cfg.OTLPEndpoint == "" {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.OTLPEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", envOTLPEndpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s format, must be a http or https URL", envOTLPEndpoint)
+	}
+
+	return nil
+}
+
+// The OTLP/HTTP JSON encoding of the exported spans.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+	}
+)
+
+// otlpString returns a string attribute.
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+// otlpInt returns an integer attribute, which OTLP/JSON encodes as a string.
+func otlpInt(key string, value int64) otlpKeyValue {
+	s := strconv.FormatInt(value, 10)
+	return otlpKeyValue{Key: key, Value: otlpValue{IntValue: &s}}
+}
@@ -0,0 +1,183 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCollector returns a server accepting OTLP/HTTP JSON traces and a channel receiving the exported spans.
+func newCollector(t *testing.T, status int) (*httptest.Server, <-chan []otlpSpan) {
+	t.Helper()
+
+	received := make(chan []otlpSpan, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		var traces otlpTraces
+		if err := json.NewDecoder(r.Body).Decode(&traces); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var spans []otlpSpan
+		for _, resourceSpans := range traces.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				spans = append(spans, scopeSpans.Spans...)
+			}
+		}
+		received <- spans
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("Wait and attempts", func(t *testing.T) {
+		t.Parallel()
+
+		server, received := newCollector(t, http.StatusOK)
+		tr := newTracer(server.URL, server.Client())
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+		cfg := Config{TargetName: "database", CheckType: checkTypeTCP, Interval: 10 * time.Millisecond}
+		failures := 1
+		check := func(ctx context.Context) error {
+			if failures > 0 {
+				failures--
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+
+		if err := pollTarget(withTracer(context.Background(), tr), cfg, logger, check); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tr.export(context.Background(), logger)
+
+		spans := <-received
+		if len(spans) != 3 {
+			t.Fatalf("Expected 3 spans but got %d", len(spans))
+		}
+
+		failed, succeeded, wait := spans[0], spans[1], spans[2]
+		if wait.Name != "wait database" || wait.Status.Code != otlpStatusCodeOK {
+			t.Errorf("Expected successful wait span but got %+v", wait)
+		}
+		for _, attempt := range []otlpSpan{failed, succeeded} {
+			if attempt.ParentSpanID != wait.SpanID || attempt.TraceID != wait.TraceID {
+				t.Errorf("Expected attempt span to be a child of the wait span but got %+v", attempt)
+			}
+		}
+		if failed.Status.Code != otlpStatusCodeError || failed.Status.Message != "connection refused" {
+			t.Errorf("Expected failed attempt span but got %+v", failed)
+		}
+		if len(failed.Events) != 1 || failed.Events[0].Name != "exception" {
+			t.Errorf("Expected exception event on the failed attempt but got %+v", failed.Events)
+		}
+		if succeeded.Status.Code != otlpStatusCodeOK || len(succeeded.Events) != 0 {
+			t.Errorf("Expected successful attempt span but got %+v", succeeded)
+		}
+	})
+
+	t.Run("Canceled wait", func(t *testing.T) {
+		t.Parallel()
+
+		server, received := newCollector(t, http.StatusOK)
+		tr := newTracer(server.URL, server.Client())
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+		ctx, cancel := context.WithCancel(withTracer(context.Background(), tr))
+		defer cancel()
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		cfg := Config{TargetName: "database", CheckType: checkTypeTCP, Interval: 10 * time.Millisecond}
+		check := func(ctx context.Context) error { return errors.New("connection refused") }
+
+		if err := pollTarget(ctx, cfg, logger, check); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tr.export(context.Background(), logger)
+
+		spans := <-received
+		wait := spans[len(spans)-1]
+		if wait.Status.Code != otlpStatusCodeError || wait.Status.Message != context.Canceled.Error() {
+			t.Errorf("Expected canceled wait span but got %+v", wait)
+		}
+	})
+
+	t.Run("Without tracer", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, span := startSpan(context.Background(), "wait database")
+		if span != nil || ctx != context.Background() {
+			t.Errorf("Expected no span without tracer but got %+v", span)
+		}
+		span.end(errors.New("connection refused"))
+	})
+
+	t.Run("Failed export", func(t *testing.T) {
+		t.Parallel()
+
+		server, received := newCollector(t, http.StatusServiceUnavailable)
+		tr := newTracer(server.URL, server.Client())
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		_, span := startSpan(withTracer(context.Background(), tr), "wait database")
+		span.end(nil)
+		tr.export(context.Background(), logger)
+		<-received
+
+		expected := `level=WARN msg="Failed to export traces"`
+		if !strings.Contains(output.String(), expected) || !strings.Contains(output.String(), "unexpected status code 503") {
+			t.Errorf("Expected output to contain %q but got %q", expected, output.String())
+		}
+	})
+}
+
+func TestValidateOTLPEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{}},
+		{name: "HTTP endpoint", cfg: Config{OTLPEndpoint: "http://otel-collector:4318"}},
+		{name: "Missing scheme", cfg: Config{OTLPEndpoint: "otel-collector:4318"}, err: "invalid OTEL_EXPORTER_OTLP_ENDPOINT format, must be a http or https URL"},
+		{name: "gRPC endpoint", cfg: Config{OTLPEndpoint: "grpc://otel-collector:4317"}, err: "invalid OTEL_EXPORTER_OTLP_ENDPOINT format, must be a http or https URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateOTLPEndpoint(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
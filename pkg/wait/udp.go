@@ -0,0 +1,123 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	envProtocol       = "PROTOCOL"
+	envUDPAllowEmpty  = "UDP_ALLOW_EMPTY"
+	envUDPAllowSilent = "UDP_ALLOW_SILENT"
+)
+
+const (
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
+)
+
+// maxDatagramSize is the largest UDP payload that can be received.
+const maxDatagramSize = 65507
+
+// errEmptyDatagram marks an empty response, which only counts as ready with UDP_ALLOW_EMPTY.
+var errEmptyDatagram = errors.New("received empty datagram")
+
+// probeUDP sends the payload (if any) to the given address and returns the response datagram.
+// Without a payload, the target only has to be resolvable, as UDP is connectionless.
+// With a payload, the target must respond within the timeout; an empty response is an error unless allowEmpty is set.
+// If expect is set, the response must contain it.
+func probeUDP(ctx context.Context, dialer Dialer, address string, send, expect []byte, allowEmpty bool, timeout time.Duration) ([]byte, error) {
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if len(send) == 0 {
+		return nil, nil
+	}
+
+	stop, err := bindConnDeadline(ctx, conn, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	if _, err := conn.Write(send); err != nil {
+		return nil, fmt.Errorf("failed to send probe: %w", err)
+	}
+
+	buf := make([]byte, maxDatagramSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		// an unreachable port is reported by the ICMP response as refused connection
+		return nil, fmt.Errorf("failed to read probe response: %w", err)
+	}
+
+	response := buf[:n]
+	if n == 0 && !allowEmpty {
+		return nil, errEmptyDatagram
+	}
+
+	if len(expect) > 0 && !bytes.Contains(response, expect) {
+		return nil, fmt.Errorf("unexpected probe response %q, expected %q", truncate(response, 64), expect)
+	}
+
+	return response, nil
+}
+
+// newUDPCheck returns a check probing the target via UDP.
+// With UDP_ALLOW_SILENT, a target not responding within the timeout is ready, as only a closed port is refused.
+func newUDPCheck(cfg Config, dialer Dialer, capture captureFunc) checkFunc {
+	return func(ctx context.Context) error {
+		response, err := probeUDP(ctx, dialer, cfg.TargetAddress, cfg.ProbeSend, cfg.ProbeExpect, cfg.UDPAllowEmpty, cfg.DialTimeout)
+		if cfg.UDPAllowSilent && isTimeout(err) {
+			err = nil
+		}
+		if err != nil || capture == nil {
+			return err
+		}
+		return capture(response)
+	}
+}
+
+// validateProtocol checks if the protocol is supported by the configured checks.
+func validateProtocol(cfg *Config) error {
+	if cfg.Protocol == "" {
+		cfg.Protocol = protocolTCP
+	}
+
+	if cfg.Protocol != protocolTCP && cfg.Protocol != protocolUDP {
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envProtocol, protocolTCP, protocolUDP)
+	}
+
+	if cfg.Protocol != protocolUDP {
+		if cfg.UDPAllowSilent {
+			return fmt.Errorf("%s requires %s=%s", envUDPAllowSilent, envProtocol, protocolUDP)
+		}
+		return nil
+	}
+
+	for _, targetCfg := range targetConfigs(*cfg) {
+		if targetCfg.CheckType != checkTypeTCP {
+			return fmt.Errorf("%s=%s can only be used with check type %q", envProtocol, protocolUDP, checkTypeTCP)
+		}
+	}
+
+	if cfg.ReverseCheckListen != "" {
+		return fmt.Errorf("%s=%s cannot be used with %s", envProtocol, protocolUDP, envReverseCheckListen)
+	}
+
+	if cfg.UDPAllowSilent && len(cfg.ProbeSend) == 0 {
+		return fmt.Errorf("%s requires %s", envUDPAllowSilent, envProbeSend)
+	}
+
+	if cfg.UDPAllowSilent && len(cfg.ProbeExpect) > 0 {
+		return fmt.Errorf("%s cannot be combined with %s", envUDPAllowSilent, envProbeExpect)
+	}
+
+	return nil
+}
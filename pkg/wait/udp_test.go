@@ -0,0 +1,176 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newUDPServer starts a UDP server answering every datagram with the given response.
+func newUDPServer(t *testing.T, response string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo([]byte(response), addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestProbeUDP(t *testing.T) {
+	t.Run("Response", func(t *testing.T) {
+		t.Parallel()
+
+		address := newUDPServer(t, "pong")
+
+		response, err := probeUDP(context.Background(), &net.Dialer{}, address, []byte("ping"), []byte("pong"), false, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(response) != "pong" {
+			t.Errorf("Expected response %q but got %q", "pong", response)
+		}
+	})
+
+	t.Run("Empty response not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		address := newUDPServer(t, "")
+
+		_, err := probeUDP(context.Background(), &net.Dialer{}, address, []byte("ping"), nil, false, time.Second)
+		if !errors.Is(err, errEmptyDatagram) {
+			t.Errorf("Expected empty datagram error but got %v", err)
+		}
+	})
+
+	t.Run("Empty response allowed", func(t *testing.T) {
+		t.Parallel()
+
+		address := newUDPServer(t, "")
+
+		if _, err := probeUDP(context.Background(), &net.Dialer{}, address, []byte("ping"), nil, true, time.Second); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Unexpected response", func(t *testing.T) {
+		t.Parallel()
+
+		address := newUDPServer(t, "busy")
+
+		_, err := probeUDP(context.Background(), &net.Dialer{}, address, []byte("ping"), []byte("pong"), false, time.Second)
+
+		expected := `unexpected probe response "busy", expected "pong"`
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+
+	t.Run("No response", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		_, err = probeUDP(context.Background(), &net.Dialer{}, conn.LocalAddr().String(), []byte("ping"), nil, true, 50*time.Millisecond)
+		if !isTimeout(err) {
+			t.Errorf("Expected timeout error but got %v", err)
+		}
+	})
+
+	t.Run("Without payload", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := probeUDP(context.Background(), &net.Dialer{}, "127.0.0.1:9", nil, nil, false, time.Second); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestUDPCheck(t *testing.T) {
+	t.Run("Silent target", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		cfg := Config{TargetAddress: conn.LocalAddr().String(), ProbeSend: []byte("metric:1|c"), UDPAllowSilent: true, DialTimeout: 50 * time.Millisecond}
+		if err := newUDPCheck(cfg, &net.Dialer{}, nil)(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Closed port", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		address := conn.LocalAddr().String()
+		conn.Close()
+
+		cfg := Config{TargetAddress: address, ProbeSend: []byte("metric:1|c"), UDPAllowSilent: true, DialTimeout: time.Second}
+		err = newUDPCheck(cfg, &net.Dialer{}, nil)(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Expected connection refused error but got %v", err)
+		}
+	})
+}
+
+func TestValidateProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Default", cfg: Config{CheckType: checkTypeTCP}},
+		{name: "UDP", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP}},
+		{name: "Unsupported protocol", cfg: Config{CheckType: checkTypeTCP, Protocol: "sctp"}, err: "invalid PROTOCOL value: must be one of tcp, udp"},
+		{name: "UDP with TLS", cfg: Config{CheckType: checkTypeTLS, Protocol: protocolUDP}, err: `PROTOCOL=udp can only be used with check type "tcp"`},
+		{name: "UDP with reverse check", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, ReverseCheckListen: "127.0.0.1:0"}, err: "PROTOCOL=udp cannot be used with REVERSE_CHECK_LISTEN"},
+		{name: "Silent UDP", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, ProbeSend: []byte("ping"), UDPAllowSilent: true}},
+		{name: "Silent without UDP", cfg: Config{CheckType: checkTypeTCP, ProbeSend: []byte("ping"), UDPAllowSilent: true}, err: "UDP_ALLOW_SILENT requires PROTOCOL=udp"},
+		{name: "Silent without probe", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, UDPAllowSilent: true}, err: "UDP_ALLOW_SILENT requires PROBE_SEND"},
+		{name: "Silent with expected response", cfg: Config{CheckType: checkTypeTCP, Protocol: protocolUDP, ProbeSend: []byte("ping"), ProbeExpect: []byte("pong"), UDPAllowSilent: true}, err: "UDP_ALLOW_SILENT cannot be combined with PROBE_EXPECT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateProtocol(&tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const envVerifyWritable = "VERIFY_WRITABLE"
+
+// verifyWritableWait is how long a connection must stay open after it was established to be considered usable.
+const verifyWritableWait = 250 * time.Millisecond
+
+// newVerifyWritableCheck returns a tcp check verifying that each connection is usable once it was established.
+// A load balancer may accept connections while its backend is down and close or reset them right away,
+// which a plain tcp check would report as ready.
+func newVerifyWritableCheck(cfg Config, dialer Dialer) checkFunc {
+	return func(ctx context.Context) error {
+		conn, err := dialer.DialContext(ctx, "tcp", cfg.TargetAddress)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		return verifyConnection(conn, verifyWritableWait)
+	}
+}
+
+// verifyConnection checks that the connection is neither closed nor reset by the peer within the given wait.
+// Nothing is sent to the target: an empty write only surfaces a reset that was already received,
+// and the connection is then read until the deadline. Data sent by a target that speaks first,
+// like a greeting banner, proves the connection usable as well.
+func verifyConnection(conn net.Conn, wait time.Duration) error {
+	if err := conn.SetDeadline(time.Now().Add(wait)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(nil); err != nil {
+		return fmt.Errorf("connection is not writable: %w", err)
+	}
+
+	_, err := conn.Read(make([]byte, 1))
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return nil
+	case errors.Is(err, io.EOF):
+		return errors.New("connection was closed right after it was established")
+	default:
+		return fmt.Errorf("connection was reset right after it was established: %w", err)
+	}
+}
+
+// validateVerifyWritable checks if verifying connections is supported with the given configuration.
+func validateVerifyWritable(cfg Config) error {
+	if !cfg.VerifyWritable {
+		return nil
+	}
+
+	for _, targetCfg := range targetConfigs(cfg) {
+		if targetCfg.CheckType != checkTypeTCP {
+			return fmt.Errorf("%s can only be used with check type %q", envVerifyWritable, checkTypeTCP)
+		}
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envProtocol, cfg.Protocol == protocolUDP},
+		{envConcurrentConns, cfg.ConcurrentConns > 0},
+		{envLogTCPMSS, cfg.LogTCPMSS},
+		{envRequireDualStack, cfg.RequireDualStack},
+		{envReverseCheckListen, cfg.ReverseCheckListen != ""},
+		{envProbeSend, len(cfg.ProbeSend) > 0},
+		{envProbeExpect, len(cfg.ProbeExpect) > 0},
+		{envCaptureResponseFile, cfg.CaptureResponseFile != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envVerifyWritable, conflict.env)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package wait
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newConnServer returns the address of a server handing every accepted connection to handle.
+func newConnServer(t *testing.T, handle func(conn *net.TCPConn)) string {
+	t.Helper()
+
+	lis := newListener(t)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn.(*net.TCPConn))
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestVerifyWritableCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		address func(t *testing.T) string
+		err     string
+	}{
+		{name: "Open connection", address: func(t *testing.T) string { return newListener(t).Addr().String() }},
+		{name: "Target speaking first", address: func(t *testing.T) string {
+			return newConnServer(t, func(conn *net.TCPConn) {
+				defer conn.Close()
+				_, _ = conn.Write([]byte("220 ready\r\n"))
+				time.Sleep(time.Second)
+			})
+		}},
+		{name: "Closed connection", address: func(t *testing.T) string {
+			return newConnServer(t, func(conn *net.TCPConn) { conn.Close() })
+		}, err: "connection was closed right after it was established"},
+		{name: "Reset connection", address: func(t *testing.T) string {
+			return newConnServer(t, func(conn *net.TCPConn) {
+				_ = conn.SetLinger(0)
+				conn.Close()
+			})
+		}, err: "connection reset by peer"},
+		{name: "Refused", address: closedAddress, err: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{TargetName: "postgres", TargetAddress: tt.address(t), VerifyWritable: true}
+
+			err := newVerifyWritableCheck(cfg, &net.Dialer{Timeout: time.Second})(context.Background())
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateVerifyWritable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "Disabled", cfg: Config{CheckType: checkTypeTLS}},
+		{name: "TCP check", cfg: Config{CheckType: checkTypeTCP, VerifyWritable: true}},
+		{name: "HTTP check", cfg: Config{CheckType: checkTypeHTTP, VerifyWritable: true}, err: `VERIFY_WRITABLE can only be used with check type "tcp"`},
+		{name: "Probe", cfg: Config{CheckType: checkTypeTCP, ProbeExpect: []byte("PONG"), VerifyWritable: true}, err: "VERIFY_WRITABLE cannot be combined with PROBE_EXPECT"},
+		{name: "Dual stack", cfg: Config{CheckType: checkTypeTCP, RequireDualStack: true, VerifyWritable: true}, err: "VERIFY_WRITABLE cannot be combined with REQUIRE_DUAL_STACK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateVerifyWritable(tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1768 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const version = "0.0.26"
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+const (
+	envTargetName     = "TARGET_NAME"
+	envTargetAddress  = "TARGET_ADDRESS"
+	envInterval       = "INTERVAL"
+	envDialTimeout    = "DIAL_TIMEOUT"
+	envLogExtraFields = "LOG_EXTRA_FIELDS"
+	envLogFormat      = "LOG_FORMAT"
+	envLogFile        = "LOG_FILE"
+	envCheckType      = "CHECK_TYPE"
+	envTLSMinVersion  = "TLS_MIN_VERSION"
+	envStartupMatrix  = "STARTUP_MATRIX"
+	envSettleAfter    = "SETTLE_AFTER"
+	envInitialDelay   = "INITIAL_DELAY"
+	envMaxWait        = "MAX_WAIT"
+	envMaxRetries     = "MAX_RETRIES"
+	envOneShot        = "ONE_SHOT"
+	envMaxDNSAttempts = "MAX_DNS_ATTEMPTS"
+	envSkipIfUnset    = "SKIP_IF_UNSET"
+
+	envExitCodeConfig     = "EXIT_CODE_CONFIG"
+	envExitCodeTimeout    = "EXIT_CODE_TIMEOUT"
+	envExitCodeDNS        = "EXIT_CODE_DNS"
+	envExitCodeConnection = "EXIT_CODE_CONNECTION"
+)
+
+// Config holds the required environment variables.
+type Config struct {
+	TargetName     string        // The name of the target to check.
+	TargetAddress  string        // The address of the target in the format 'host:port'.
+	Interval       time.Duration // The interval between connection attempts.
+	DialTimeout    time.Duration // The timeout for each connection attempt.
+	AttemptTimeout time.Duration // The timeout for each attempt including the protocol exchange, 0 disables it.
+	LogExtraFields bool          // Whether to log the fields in the log message.
+	LogFormat      string        // The format of the log output, either 'text' or 'json'.
+	Color          string        // Whether to color the text log output: 'auto', 'always' or 'never'.
+	LogLevel       slog.Level    // The minimum level of logged records.
+	LogAttempt     slog.Level    // The level failed attempts are logged at.
+	LogFile        string        // The file the log output is additionally written to.
+	LogTimeFormat  string        // The format of the time of log records, slog's default if empty.
+	LogUTC         bool          // Whether the time of log records is converted to UTC.
+	OnLogError     string        // What to do once writing the log output consistently fails.
+	SplitStreams   bool          // Whether not ready records, warnings and errors are written to stderr.
+	DedupLogs      bool          // Whether consecutive identical not ready records are collapsed into a periodic summary.
+	CheckType      string        // The type of check to perform against the target.
+	WaitFor        string        // Whether to wait for the target to go up or down.
+	Targets        []Target      // Additional targets defined via TARGETS or indexed environment variables.
+	TLSMinVersion  uint16        // The minimum TLS version a target must negotiate in TLS checks.
+	HTTPTrace      bool          // Whether to log the timing breakdown of each HTTP check.
+	StartupMatrix  bool          // Whether to log the initial state of all targets before waiting.
+	InitialDelay   time.Duration // How long to wait before the first check.
+	SettleAfter    time.Duration // How long to wait after all targets are ready before exiting.
+	MaxWait        time.Duration // How long to wait for the targets before giving up, 0 waits forever.
+	MaxRetries     int           // How often a failed check is retried before giving up, 0 retries forever.
+	OneShot        bool          // Whether to check the targets once instead of waiting, e.g. for a container health check.
+	Monitor        bool          // Whether to keep checking the targets once they are ready and log state changes until canceled.
+	MaxDNSAttempts int           // How many attempts may fail to resolve the target before giving up, 0 means unlimited.
+	SkipIfUnset    string        // The environment variable that must be set, otherwise waiting is skipped.
+	LogOutcome     bool          // Whether to log a final record with the outcome of the run.
+	MetricsAddr    string        // The address to serve Prometheus metrics on, metrics are disabled if empty.
+	OTLPEndpoint   string        // The OTLP/HTTP endpoint to export traces to, tracing is disabled if empty.
+
+	MsgReady           string        // The template of the message logged once a target is ready.
+	MsgNotReady        string        // The template of the message logged for each failed check.
+	StartupMessageMode string        // Whether multiple targets log one waiting message each or a combined one.
+	QuietStartup       bool          // Whether to suppress the waiting messages logged before the first check.
+	MaxSpread          time.Duration // How far apart multiple targets may become ready, 0 disables the check.
+	Match              string        // Whether multiple targets must all be ready or only any of them.
+	Quorum             int           // How many of multiple targets must be ready, 0 requires all of them.
+	QuorumPercent      float64       // The percentage of multiple targets that must be ready, overrides Quorum.
+
+	CaptureResponseFile string // The file the response of the successful check is written to.
+	TargetAddressFile   string // The file the target address is read from before each attempt, overrides TargetAddress.
+	StatusFile          string // The file a JSON document with the outcome is written to once waiting finished.
+
+	Protocol       string // The transport protocol of tcp checks, either 'tcp' or 'udp'.
+	UDPAllowEmpty  bool   // Whether an empty UDP response counts as ready.
+	UDPAllowSilent bool   // Whether a UDP target not responding to the probe counts as ready.
+
+	ConcurrentConns int // The number of simultaneous connections a tcp check must open, 0 disables the check.
+	FDThreshold     int // The number of open file descriptors a local process needs in fd checks.
+
+	ExecCommand string // The command run with a shell in exec checks.
+
+	TLSHandshakeRetries int           // How often a failed TLS handshake is retried within a single attempt.
+	ClockSkew           time.Duration // The tolerated clock difference when checking the validity period of certificates.
+
+	TLSServerName         string // The server name sent via SNI and verified against the certificate, defaults to the target host.
+	TLSInsecureSkipVerify bool   // Whether the certificate of the target is accepted without verification.
+
+	ExpectCertChange        bool   // Whether the certificate of tls checks must change from the first one seen.
+	ExpectedCertFingerprint string // The SHA-256 fingerprint the certificate of tls checks must have.
+
+	GRPCService string // The service name sent in gRPC health checks, empty checks the overall server health.
+	GRPCTLS     bool   // Whether gRPC health checks connect via TLS.
+
+	GRPCReflectionFallback bool // Whether a server without the health service is ready once it answers a reflection request.
+
+	GRPCReadyEndpoint string // The https URL of the control plane to report readiness to via gRPC.
+	GRPCReadyMethod   string // The gRPC method called to report readiness, e.g. '/controlplane.v1.Readiness/ReportReady'.
+
+	NotifySocket string        // The unix socket a supervisor listens on for READY=1, as with systemd's notify protocol.
+	Watchdog     time.Duration // The systemd watchdog timeout, pinged via NotifySocket at half the timeout, 0 disables it.
+
+	OnReadyExec              string        // The shell command to run once all targets are ready.
+	OnReadyExecRetries       int           // How often to retry a failing on-ready command.
+	OnReadyExecRetryInterval time.Duration // The interval between retries of the on-ready command.
+	OnReadyExecRequired      bool          // Whether a failing on-ready command or webhook fails the process.
+	OnReadyWebhook           string        // The URL to post to once all targets are ready.
+
+	StableBodyAttempts int         // The number of consecutive attempts with an identical HTTP response body required for readiness.
+	ExpectedStatus     []int       // The HTTP status codes indicating a ready target, any 2xx if empty.
+	FatalStatus        []int       // The HTTP status codes aborting waiting instead of retrying.
+	ExpectedBody       string      // The substring the HTTP response body must contain for readiness.
+	HTTPMethod         string      // The method of the requests of HTTP checks.
+	HTTPHeaders        http.Header // The additional headers sent with the requests of HTTP checks.
+
+	Backoff     string        // How the wait between failed attempts grows, either 'constant' or 'exponential'.
+	BackoffMax  time.Duration // The maximum wait between attempts with exponential backoff, 0 means unlimited.
+	Jitter      time.Duration // The maximum random deviation of the wait between attempts.
+	JitterRatio float64       // The maximum random deviation relative to the wait between attempts, overrides Jitter.
+
+	FastInterval time.Duration // The interval between attempts during the first FastDuration.
+	FastDuration time.Duration // How long to poll with FastInterval before switching to Interval, 0 disables it.
+
+	FailFastOnTimeout int // How many attempts may time out before giving up, 0 means unlimited.
+
+	WindowSize      int // The number of most recent checks considered for readiness, 0 disables the window.
+	WindowSuccesses int // The number of successful checks within the window required for readiness.
+
+	SuccessThreshold int           // How many consecutive checks must succeed for readiness, 0 and 1 require a single one.
+	StabilizeFor     time.Duration // How long a target must pass every check after the first successful one, 0 disables it.
+
+	MaxConcurrency  int           // The maximum number of concurrent checks across all targets, 0 means unlimited.
+	ConcurrencyRamp time.Duration // The window over which the concurrency grows up to MaxConcurrency.
+
+	ExpectedIPs   []netip.Prefix // The addresses the target host may resolve to.
+	LogCNAMEChain bool           // Whether to log the CNAME chain of the target host once it was resolved.
+	LogTCPMSS     bool           // Whether to log the TCP MSS negotiated for each connection in tcp checks.
+
+	RequireDualStack bool // Whether tcp checks must connect over both IPv4 and IPv6.
+	VerifyWritable   bool // Whether tcp checks verify that the connection is not closed or reset right away.
+
+	ProxyAddress     string // The SOCKS5 proxy to connect to the targets through, targets are dialed directly if empty.
+	HTTPProxyAddress string // The HTTP proxy to tunnel to the targets through with CONNECT, targets are dialed directly if empty.
+	SendProxyHeader  bool   // Whether a PROXY protocol v1 header is sent on every new TCP connection to the target.
+	CloseReset       bool   // Whether TCP connections to the target are closed with a RST instead of a FIN, avoiding TIME_WAIT.
+
+	ReverseCheckListen  string        // The address to listen on for the target connecting back.
+	ReverseCheckAddress string        // The address the target should connect back to.
+	ReverseCheckTimeout time.Duration // How long to wait for the target to connect back.
+
+	ProbeSend      []byte // The payload to send after connecting in TCP checks.
+	ProbeExpect    []byte // The data the response must contain in TCP checks.
+	ProbeFirstLine bool   // Whether only the first line of the probe response is read.
+
+	S3Bucket          string // The bucket to check in S3 checks.
+	S3Region          string // The region used to sign S3 requests.
+	S3AccessKeyID     string // The access key ID used to sign S3 requests.
+	S3SecretAccessKey string // The secret access key used to sign S3 requests.
+	S3SessionToken    string // The optional session token for temporary S3 credentials.
+
+	ExitCodeTimeout    int // The exit code when giving up after MAX_WAIT, 0 uses the exit code of the dominant failure reason.
+	ExitCodeDNS        int // The exit code when giving up mostly due to DNS failures.
+	ExitCodeConnection int // The exit code when giving up mostly due to connection failures.
+
+	// OnAttempt is called after each check with the number of the attempt, starting at 1, and the result of the check.
+	// It is only set by library consumers and ignored by the CLI. With multiple targets, it is called concurrently
+	// for each target, so it must be safe for concurrent use.
+	OnAttempt func(attempt int, err error)
+}
+
+// ParseConfig retrieves and parses the required environment variables.
+// Values missing in the environment are read from ENV_FILE and then CONFIG_FILE if set.
+// Provides default values if the environment variables are not set.
+func ParseConfig(getenv func(string) string) (Config, error) {
+	if envFile := getenv(envEnvFile); envFile != "" {
+		values, err := loadEnvFile(envFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envEnvFile, err)
+		}
+		getenv = withFallback(getenv, values) // environment variables override the env file
+	}
+
+	if configFile := getenv(envConfigFile); configFile != "" {
+		values, err := loadConfigFile(configFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envConfigFile, err)
+		}
+		getenv = withFallback(getenv, values) // environment variables override the config file
+	}
+
+	cfg := Config{
+		TargetName:     getenv(envTargetName),
+		TargetAddress:  getenv(envTargetAddress),
+		Interval:       2 * time.Second, // default interval
+		DialTimeout:    1 * time.Second, // default dial timeout
+		LogExtraFields: false,
+		LogFormat:      logFormatText,
+		Color:          colorAuto,
+		LogAttempt:     slog.LevelWarn,
+		OnLogError:     logErrorIgnore,
+		CheckType:      checkTypeTCP,
+		WaitFor:        waitForUp,
+		Protocol:       protocolTCP,
+		Backoff:        backoffConstant,
+		HTTPMethod:     http.MethodGet,
+
+		SuccessThreshold: 1, // default success threshold
+
+		StartupMessageMode: startupMessagePerTarget,
+		Match:              matchAll,
+		ClockSkew:          defaultClockSkew,
+
+		OnReadyExecRetryInterval: 1 * time.Second, // default on-ready command retry interval
+		ReverseCheckTimeout:      5 * time.Second, // default reverse check timeout
+
+		ExitCodeDNS:        defaultExitCodeDNS,
+		ExitCodeConnection: defaultExitCodeConnection,
+	}
+
+	if intervalStr := getenv(envInterval); intervalStr != "" {
+		var err error
+		cfg.Interval, err = time.ParseDuration(intervalStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envInterval, err)
+		}
+	}
+
+	if dialTimeoutStr := getenv(envDialTimeout); dialTimeoutStr != "" {
+		var err error
+		cfg.DialTimeout, err = time.ParseDuration(dialTimeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envDialTimeout, err)
+		}
+	}
+
+	if attemptTimeoutStr := getenv(envAttemptTimeout); attemptTimeoutStr != "" {
+		var err error
+		cfg.AttemptTimeout, err = time.ParseDuration(attemptTimeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envAttemptTimeout, err)
+		}
+	}
+
+	cfg.LogFile = getenv(envLogFile)
+	cfg.LogTimeFormat = getenv(envLogTimeFormat)
+
+	if logUTCStr := getenv(envLogUTC); logUTCStr != "" {
+		var err error
+		cfg.LogUTC, err = strconv.ParseBool(logUTCStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogUTC, err)
+		}
+	}
+
+	if logFieldsStr := getenv(envLogExtraFields); logFieldsStr != "" {
+		var err error
+		cfg.LogExtraFields, err = strconv.ParseBool(logFieldsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogExtraFields, err)
+		}
+	}
+
+	if logFormat := getenv(envLogFormat); logFormat != "" {
+		cfg.LogFormat = logFormat
+	}
+
+	if color := getenv(envColor); color != "" {
+		cfg.Color = color
+	}
+
+	if logLevelStr := getenv(envLogLevel); logLevelStr != "" {
+		var err error
+		cfg.LogLevel, err = parseLogLevel(logLevelStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogLevel, err)
+		}
+	}
+
+	if logAttemptLevelStr := getenv(envLogAttemptLevel); logAttemptLevelStr != "" {
+		var err error
+		cfg.LogAttempt, err = parseLogLevel(logAttemptLevelStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogAttemptLevel, err)
+		}
+	}
+
+	if onLogError := getenv(envOnLogError); onLogError != "" {
+		cfg.OnLogError = onLogError
+	}
+
+	if splitStreamsStr := getenv(envSplitStreams); splitStreamsStr != "" {
+		var err error
+		cfg.SplitStreams, err = strconv.ParseBool(splitStreamsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSplitStreams, err)
+		}
+	}
+
+	if dedupLogsStr := getenv(envDedupLogs); dedupLogsStr != "" {
+		var err error
+		cfg.DedupLogs, err = strconv.ParseBool(dedupLogsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envDedupLogs, err)
+		}
+	}
+
+	if checkType := getenv(envCheckType); checkType != "" {
+		cfg.CheckType = checkType
+	}
+
+	if waitFor := getenv(envWaitFor); waitFor != "" {
+		cfg.WaitFor = waitFor
+	}
+
+	if tlsMinVersionStr := getenv(envTLSMinVersion); tlsMinVersionStr != "" {
+		var err error
+		cfg.TLSMinVersion, err = parseTLSVersion(tlsMinVersionStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envTLSMinVersion, err)
+		}
+	}
+
+	if tlsHandshakeRetriesStr := getenv(envTLSHandshakeRetries); tlsHandshakeRetriesStr != "" {
+		var err error
+		cfg.TLSHandshakeRetries, err = strconv.Atoi(tlsHandshakeRetriesStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envTLSHandshakeRetries, err)
+		}
+	}
+
+	cfg.TLSServerName = getenv(envTLSServerName)
+
+	if insecureSkipVerifyStr := getenv(envTLSInsecureSkipVerify); insecureSkipVerifyStr != "" {
+		var err error
+		cfg.TLSInsecureSkipVerify, err = strconv.ParseBool(insecureSkipVerifyStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envTLSInsecureSkipVerify, err)
+		}
+	}
+
+	if expectCertChangeStr := getenv(envExpectCertChange); expectCertChangeStr != "" {
+		var err error
+		cfg.ExpectCertChange, err = strconv.ParseBool(expectCertChangeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExpectCertChange, err)
+		}
+	}
+
+	if fingerprintStr := getenv(envExpectedCertFingerprint); fingerprintStr != "" {
+		var err error
+		cfg.ExpectedCertFingerprint, err = parseCertFingerprint(fingerprintStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExpectedCertFingerprint, err)
+		}
+	}
+
+	if clockSkewStr := getenv(envClockSkew); clockSkewStr != "" {
+		var err error
+		cfg.ClockSkew, err = time.ParseDuration(clockSkewStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envClockSkew, err)
+		}
+	}
+
+	if httpTraceStr := getenv(envHTTPTrace); httpTraceStr != "" {
+		var err error
+		cfg.HTTPTrace, err = strconv.ParseBool(httpTraceStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envHTTPTrace, err)
+		}
+	}
+
+	if startupMessageMode := getenv(envStartupMessageMode); startupMessageMode != "" {
+		cfg.StartupMessageMode = startupMessageMode
+	}
+
+	if quietStartupStr := getenv(envQuietStartup); quietStartupStr != "" {
+		var err error
+		cfg.QuietStartup, err = strconv.ParseBool(quietStartupStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envQuietStartup, err)
+		}
+	}
+
+	cfg.MsgReady = getenv(envMsgReady)
+	cfg.MsgNotReady = getenv(envMsgNotReady)
+
+	if maxSpreadStr := getenv(envMaxSpread); maxSpreadStr != "" {
+		var err error
+		cfg.MaxSpread, err = time.ParseDuration(maxSpreadStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMaxSpread, err)
+		}
+	}
+
+	if match := getenv(envMatch); match != "" {
+		cfg.Match = match
+	}
+
+	if quorumStr := getenv(envQuorum); quorumStr != "" {
+		var err error
+		cfg.Quorum, cfg.QuorumPercent, err = parseQuorum(quorumStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envQuorum, err)
+		}
+	}
+
+	if startupMatrixStr := getenv(envStartupMatrix); startupMatrixStr != "" {
+		var err error
+		cfg.StartupMatrix, err = strconv.ParseBool(startupMatrixStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envStartupMatrix, err)
+		}
+	}
+
+	cfg.CaptureResponseFile = getenv(envCaptureResponseFile)
+	cfg.TargetAddressFile = getenv(envTargetAddressFile)
+	cfg.StatusFile = getenv(envStatusFile)
+
+	if protocol := getenv(envProtocol); protocol != "" {
+		cfg.Protocol = protocol
+	}
+
+	if concurrentConnsStr := getenv(envConcurrentConns); concurrentConnsStr != "" {
+		var err error
+		cfg.ConcurrentConns, err = strconv.Atoi(concurrentConnsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envConcurrentConns, err)
+		}
+	}
+
+	if fdThresholdStr := getenv(envFDThreshold); fdThresholdStr != "" {
+		var err error
+		cfg.FDThreshold, err = strconv.Atoi(fdThresholdStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envFDThreshold, err)
+		}
+	}
+
+	cfg.ExecCommand = getenv(envExecCommand)
+
+	if udpAllowEmptyStr := getenv(envUDPAllowEmpty); udpAllowEmptyStr != "" {
+		var err error
+		cfg.UDPAllowEmpty, err = strconv.ParseBool(udpAllowEmptyStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envUDPAllowEmpty, err)
+		}
+	}
+
+	if udpAllowSilentStr := getenv(envUDPAllowSilent); udpAllowSilentStr != "" {
+		var err error
+		cfg.UDPAllowSilent, err = strconv.ParseBool(udpAllowSilentStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envUDPAllowSilent, err)
+		}
+	}
+
+	if logOutcomeStr := getenv(envLogOutcome); logOutcomeStr != "" {
+		var err error
+		cfg.LogOutcome, err = strconv.ParseBool(logOutcomeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogOutcome, err)
+		}
+	}
+
+	if settleAfterStr := getenv(envSettleAfter); settleAfterStr != "" {
+		var err error
+		cfg.SettleAfter, err = time.ParseDuration(settleAfterStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSettleAfter, err)
+		}
+	}
+
+	if successThresholdStr := getenv(envSuccessThreshold); successThresholdStr != "" {
+		var err error
+		cfg.SuccessThreshold, err = strconv.Atoi(successThresholdStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSuccessThreshold, err)
+		}
+	}
+
+	if stabilizeForStr := getenv(envStabilizeFor); stabilizeForStr != "" {
+		var err error
+		cfg.StabilizeFor, err = time.ParseDuration(stabilizeForStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envStabilizeFor, err)
+		}
+	}
+
+	if initialDelayStr := getenv(envInitialDelay); initialDelayStr != "" {
+		var err error
+		cfg.InitialDelay, err = time.ParseDuration(initialDelayStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envInitialDelay, err)
+		}
+	}
+
+	if maxWaitStr := getenv(envMaxWait); maxWaitStr != "" {
+		var err error
+		cfg.MaxWait, err = time.ParseDuration(maxWaitStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMaxWait, err)
+		}
+	}
+
+	if maxDNSAttemptsStr := getenv(envMaxDNSAttempts); maxDNSAttemptsStr != "" {
+		var err error
+		cfg.MaxDNSAttempts, err = strconv.Atoi(maxDNSAttemptsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMaxDNSAttempts, err)
+		}
+	}
+
+	if failFastOnTimeoutStr := getenv(envFailFastOnTimeout); failFastOnTimeoutStr != "" {
+		var err error
+		cfg.FailFastOnTimeout, err = strconv.Atoi(failFastOnTimeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envFailFastOnTimeout, err)
+		}
+	}
+
+	if maxRetriesStr := getenv(envMaxRetries); maxRetriesStr != "" {
+		var err error
+		cfg.MaxRetries, err = strconv.Atoi(maxRetriesStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMaxRetries, err)
+		}
+	}
+
+	if oneShotStr := getenv(envOneShot); oneShotStr != "" {
+		var err error
+		cfg.OneShot, err = strconv.ParseBool(oneShotStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envOneShot, err)
+		}
+	}
+
+	if monitorStr := getenv(envMonitor); monitorStr != "" {
+		var err error
+		cfg.Monitor, err = strconv.ParseBool(monitorStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMonitor, err)
+		}
+	}
+
+	// the exit code for configuration errors is read by Run on its own, it is only checked here
+	if exitCodeStr := getenv(envExitCodeConfig); exitCodeStr != "" {
+		exitCode, err := strconv.Atoi(exitCodeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExitCodeConfig, err)
+		}
+		if exitCode < 1 || exitCode > 255 {
+			return Config{}, fmt.Errorf("invalid %s value: exit code must be between 1 and 255", envExitCodeConfig) // a configuration error must not report success
+		}
+	}
+
+	if exitCodeStr := getenv(envExitCodeTimeout); exitCodeStr != "" {
+		var err error
+		cfg.ExitCodeTimeout, err = strconv.Atoi(exitCodeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExitCodeTimeout, err)
+		}
+	}
+
+	if exitCodeStr := getenv(envExitCodeDNS); exitCodeStr != "" {
+		var err error
+		cfg.ExitCodeDNS, err = strconv.Atoi(exitCodeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExitCodeDNS, err)
+		}
+	}
+
+	if exitCodeStr := getenv(envExitCodeConnection); exitCodeStr != "" {
+		var err error
+		cfg.ExitCodeConnection, err = strconv.Atoi(exitCodeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExitCodeConnection, err)
+		}
+	}
+
+	cfg.SkipIfUnset = getenv(envSkipIfUnset)
+	cfg.MetricsAddr = getenv(envMetricsAddr)
+	cfg.OTLPEndpoint = getenv(envOTLPEndpoint)
+
+	cfg.GRPCService = getenv(envGRPCService)
+
+	if grpcTLSStr := getenv(envGRPCTLS); grpcTLSStr != "" {
+		var err error
+		cfg.GRPCTLS, err = strconv.ParseBool(grpcTLSStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envGRPCTLS, err)
+		}
+	}
+
+	if reflectionFallbackStr := getenv(envGRPCReflectionFallback); reflectionFallbackStr != "" {
+		var err error
+		cfg.GRPCReflectionFallback, err = strconv.ParseBool(reflectionFallbackStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envGRPCReflectionFallback, err)
+		}
+	}
+
+	cfg.GRPCReadyEndpoint = getenv(envGRPCReadyEndpoint)
+	cfg.GRPCReadyMethod = getenv(envGRPCReadyMethod)
+
+	cfg.NotifySocket = getenv(envNotifySocket)
+
+	// like sd_watchdog_enabled, the watchdog is ignored if it is meant for another process, e.g. the parent of TACO
+	if watchdogUsecStr := getenv(envWatchdogUsec); watchdogUsecStr != "" {
+		usec, err := strconv.ParseInt(watchdogUsecStr, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envWatchdogUsec, err)
+		}
+		if watchdogPID := getenv(envWatchdogPID); watchdogPID == "" || watchdogPID == strconv.Itoa(os.Getpid()) {
+			cfg.Watchdog = time.Duration(usec) * time.Microsecond
+		}
+	}
+
+	cfg.OnReadyExec = getenv(envOnReadyExec)
+
+	if retriesStr := getenv(envOnReadyExecRetries); retriesStr != "" {
+		var err error
+		cfg.OnReadyExecRetries, err = strconv.Atoi(retriesStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envOnReadyExecRetries, err)
+		}
+	}
+
+	if retryIntervalStr := getenv(envOnReadyExecRetryInterval); retryIntervalStr != "" {
+		var err error
+		cfg.OnReadyExecRetryInterval, err = time.ParseDuration(retryIntervalStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envOnReadyExecRetryInterval, err)
+		}
+	}
+
+	if requiredStr := getenv(envOnReadyExecRequired); requiredStr != "" {
+		var err error
+		cfg.OnReadyExecRequired, err = strconv.ParseBool(requiredStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envOnReadyExecRequired, err)
+		}
+	}
+
+	cfg.OnReadyWebhook = getenv(envOnReadyWebhook)
+
+	if stableBodyAttemptsStr := getenv(envStableBodyAttempts); stableBodyAttemptsStr != "" {
+		var err error
+		cfg.StableBodyAttempts, err = strconv.Atoi(stableBodyAttemptsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envStableBodyAttempts, err)
+		}
+	}
+
+	if expectedStatusStr := getenv(envExpectedStatus); expectedStatusStr != "" {
+		var err error
+		cfg.ExpectedStatus, err = parseStatusCodes(expectedStatusStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExpectedStatus, err)
+		}
+	}
+
+	cfg.ExpectedBody = getenv(envExpectedBody)
+
+	if httpMethod := getenv(envHTTPMethod); httpMethod != "" {
+		cfg.HTTPMethod = strings.ToUpper(httpMethod)
+	}
+
+	if httpHeadersStr := getenv(envHTTPHeaders); httpHeadersStr != "" {
+		var err error
+		cfg.HTTPHeaders, err = parseHTTPHeaders(httpHeadersStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envHTTPHeaders, err)
+		}
+	}
+
+	if fatalStatusStr := getenv(envFatalStatus); fatalStatusStr != "" {
+		var err error
+		cfg.FatalStatus, err = parseStatusCodes(fatalStatusStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envFatalStatus, err)
+		}
+	}
+
+	if backoffStr := getenv(envBackoff); backoffStr != "" {
+		cfg.Backoff = backoffStr
+	}
+
+	if backoffMaxStr := getenv(envBackoffMax); backoffMaxStr != "" {
+		var err error
+		cfg.BackoffMax, err = time.ParseDuration(backoffMaxStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envBackoffMax, err)
+		}
+	}
+
+	if jitterStr := getenv(envJitter); jitterStr != "" {
+		var err error
+		cfg.Jitter, cfg.JitterRatio, err = parseJitter(jitterStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envJitter, err)
+		}
+	}
+
+	if fastIntervalStr := getenv(envFastInterval); fastIntervalStr != "" {
+		var err error
+		cfg.FastInterval, err = time.ParseDuration(fastIntervalStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envFastInterval, err)
+		}
+	}
+
+	if fastDurationStr := getenv(envFastDuration); fastDurationStr != "" {
+		var err error
+		cfg.FastDuration, err = time.ParseDuration(fastDurationStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envFastDuration, err)
+		}
+	}
+
+	if windowSizeStr := getenv(envWindowSize); windowSizeStr != "" {
+		var err error
+		cfg.WindowSize, err = strconv.Atoi(windowSizeStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envWindowSize, err)
+		}
+	}
+
+	if windowSuccessesStr := getenv(envWindowSuccesses); windowSuccessesStr != "" {
+		var err error
+		cfg.WindowSuccesses, err = strconv.Atoi(windowSuccessesStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envWindowSuccesses, err)
+		}
+	}
+
+	if maxConcurrencyStr := getenv(envMaxConcurrency); maxConcurrencyStr != "" {
+		var err error
+		cfg.MaxConcurrency, err = strconv.Atoi(maxConcurrencyStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envMaxConcurrency, err)
+		}
+	}
+
+	if concurrencyStr := getenv(envConcurrency); concurrencyStr != "" {
+		if getenv(envMaxConcurrency) != "" {
+			return Config{}, fmt.Errorf("%s cannot be combined with %s", envConcurrency, envMaxConcurrency)
+		}
+		var err error
+		cfg.MaxConcurrency, err = strconv.Atoi(concurrencyStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envConcurrency, err)
+		}
+		if cfg.MaxConcurrency < 0 {
+			return Config{}, fmt.Errorf("invalid %s value: concurrency cannot be negative", envConcurrency)
+		}
+	}
+
+	if concurrencyRampStr := getenv(envConcurrencyRamp); concurrencyRampStr != "" {
+		var err error
+		cfg.ConcurrencyRamp, err = time.ParseDuration(concurrencyRampStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envConcurrencyRamp, err)
+		}
+	}
+
+	if expectedIPsStr := getenv(envExpectedIPs); expectedIPsStr != "" {
+		var err error
+		cfg.ExpectedIPs, err = parseExpectedIPs(expectedIPsStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envExpectedIPs, err)
+		}
+	}
+
+	if logCNAMEChainStr := getenv(envLogCNAMEChain); logCNAMEChainStr != "" {
+		var err error
+		cfg.LogCNAMEChain, err = strconv.ParseBool(logCNAMEChainStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogCNAMEChain, err)
+		}
+	}
+
+	if logTCPMSSStr := getenv(envLogTCPMSS); logTCPMSSStr != "" {
+		var err error
+		cfg.LogTCPMSS, err = strconv.ParseBool(logTCPMSSStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envLogTCPMSS, err)
+		}
+	}
+
+	if requireDualStackStr := getenv(envRequireDualStack); requireDualStackStr != "" {
+		var err error
+		cfg.RequireDualStack, err = strconv.ParseBool(requireDualStackStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envRequireDualStack, err)
+		}
+	}
+
+	if verifyWritableStr := getenv(envVerifyWritable); verifyWritableStr != "" {
+		var err error
+		cfg.VerifyWritable, err = strconv.ParseBool(verifyWritableStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envVerifyWritable, err)
+		}
+	}
+
+	cfg.ProxyAddress = getenv(envProxyAddress)
+	cfg.HTTPProxyAddress = getenv(envHTTPProxyAddress)
+
+	if sendProxyHeaderStr := getenv(envSendProxyHeader); sendProxyHeaderStr != "" {
+		var err error
+		cfg.SendProxyHeader, err = strconv.ParseBool(sendProxyHeaderStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSendProxyHeader, err)
+		}
+	}
+
+	if closeResetStr := getenv(envCloseReset); closeResetStr != "" {
+		var err error
+		cfg.CloseReset, err = strconv.ParseBool(closeResetStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envCloseReset, err)
+		}
+	}
+
+	cfg.ReverseCheckListen = getenv(envReverseCheckListen)
+	cfg.ReverseCheckAddress = getenv(envReverseCheckAddress)
+
+	if timeoutStr := getenv(envReverseCheckTimeout); timeoutStr != "" {
+		var err error
+		cfg.ReverseCheckTimeout, err = time.ParseDuration(timeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envReverseCheckTimeout, err)
+		}
+	}
+
+	if err := parseProbeConfig(getenv, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	parseS3Config(getenv, &cfg)
+
+	targets, err := parseIndexedTargets(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Targets = targets
+
+	if targetsStr := getenv(envTargets); targetsStr != "" {
+		if len(cfg.Targets) > 0 {
+			return Config{}, fmt.Errorf("%s cannot be combined with %s", envTargets, fmt.Sprintf(envIndexedTargetAddress, 1))
+		}
+		if cfg.TargetAddress != "" {
+			return Config{}, fmt.Errorf("%s cannot be combined with %s", envTargets, envTargetAddress)
+		}
+
+		cfg.Targets, err = parseTargets(targetsStr, cfg.CheckType)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// ValidateConfig checks if the configuration is valid.
+func ValidateConfig(cfg *Config) error {
+	if cfg.CheckType == "" {
+		cfg.CheckType = checkTypeTCP
+	}
+
+	if err := validateCheckType(envCheckType, cfg.CheckType); err != nil {
+		return err
+	}
+
+	if err := validateTargetAddressFile(cfg); err != nil {
+		return err
+	}
+
+	if strings.Contains(cfg.TargetAddress, ",") && len(cfg.Targets) == 0 {
+		targets, err := splitTargetAddress(*cfg)
+		if err != nil {
+			return err
+		}
+		cfg.Targets = targets
+		cfg.TargetName, cfg.TargetAddress = "", ""
+	}
+
+	if len(cfg.Targets) > 0 {
+		if cfg.TargetAddress != "" {
+			return fmt.Errorf("%s cannot be combined with %s", envTargetAddress, fmt.Sprintf(envIndexedTargetAddress, 1))
+		}
+
+		for i := range cfg.Targets {
+			if err := validateTarget(i+1, &cfg.Targets[i], cfg.CheckType); err != nil {
+				return err
+			}
+		}
+	} else if cfg.TargetAddressFile == "" {
+		if err := validateAddress(envTargetAddress, cfg.CheckType, cfg.TargetAddress); err != nil {
+			return err
+		}
+
+		if cfg.TargetName == "" {
+			cfg.TargetName = inferTargetName(cfg.TargetAddress)
+		}
+	}
+
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = logFormatText
+	}
+
+	if cfg.LogFormat != logFormatText && cfg.LogFormat != logFormatJSON {
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envLogFormat, logFormatText, logFormatJSON)
+	}
+
+	if err := validateColor(cfg); err != nil {
+		return err
+	}
+
+	if err := validateLogTimeFormat(cfg.LogTimeFormat); err != nil {
+		return err
+	}
+
+	if err := validateProtocol(cfg); err != nil {
+		return err
+	}
+
+	if usesCheckType(*cfg, checkTypeS3) {
+		if cfg.S3Bucket == "" {
+			return fmt.Errorf("%s environment variable is required for check type %q", envS3Bucket, checkTypeS3)
+		}
+
+		if cfg.S3AccessKeyID != "" && cfg.S3SecretAccessKey == "" {
+			return fmt.Errorf("%s environment variable is required when %s is set", envS3SecretAccessKey, envS3AccessKeyID)
+		}
+	}
+
+	if cfg.Interval < 0 {
+		return fmt.Errorf("invalid %s value: interval cannot be negative", envInterval)
+	}
+
+	if err := validateAttemptTimeout(*cfg); err != nil {
+		return err
+	}
+
+	if cfg.DialTimeout < 0 {
+		return fmt.Errorf("invalid %s value: dial timeout cannot be negative", envDialTimeout)
+	}
+
+	if cfg.SettleAfter < 0 {
+		return fmt.Errorf("invalid %s value: settle time cannot be negative", envSettleAfter)
+	}
+
+	if cfg.InitialDelay < 0 {
+		return fmt.Errorf("invalid %s value: delay cannot be negative", envInitialDelay)
+	}
+
+	if err := validateOneShot(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateMonitor(*cfg); err != nil {
+		return err
+	}
+
+	if cfg.MaxWait < 0 {
+		return fmt.Errorf("invalid %s value: wait time cannot be negative", envMaxWait)
+	}
+
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("invalid %s value: retries cannot be negative", envMaxRetries)
+	}
+
+	if cfg.MaxDNSAttempts < 0 {
+		return fmt.Errorf("invalid %s value: attempts cannot be negative", envMaxDNSAttempts)
+	}
+
+	if cfg.FailFastOnTimeout < 0 {
+		return fmt.Errorf("invalid %s value: attempts cannot be negative", envFailFastOnTimeout)
+	}
+
+	if err := validateClockSkew(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateCertChange(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateMetricsAddr(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateOTLPEndpoint(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateGRPCCheck(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateGRPCReady(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateNotifySocket(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateTLSHandshakeRetries(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateFDCheck(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateExecCheck(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateConcurrentConns(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateTCPMSS(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateDualStack(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateVerifyWritable(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateProxy(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateHTTPProxy(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateSendProxyHeader(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateCloseReset(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateCaptureResponse(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateStableBody(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateStatus(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateExpectedBody(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateHTTPRequest(cfg); err != nil {
+		return err
+	}
+
+	if err := validateWaitFor(cfg); err != nil {
+		return err
+	}
+
+	if err := validateMaxSpread(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateMatch(cfg); err != nil {
+		return err
+	}
+
+	if err := validateQuorum(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateMessages(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateStartupMessageMode(cfg); err != nil {
+		return err
+	}
+
+	if err := validateOnLogError(cfg); err != nil {
+		return err
+	}
+
+	if err := validateBackoff(cfg); err != nil {
+		return err
+	}
+
+	if err := validateWindow(cfg); err != nil {
+		return err
+	}
+
+	if err := validateSuccessThreshold(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateStabilizeFor(*cfg); err != nil {
+		return err
+	}
+
+	if err := validateReverseCheck(cfg); err != nil {
+		return err
+	}
+
+	if err := validateOnReadyHooks(*cfg); err != nil {
+		return err
+	}
+
+	if cfg.MaxConcurrency < 0 {
+		return fmt.Errorf("invalid %s value: concurrency cannot be negative", envMaxConcurrency)
+	}
+
+	if cfg.ConcurrencyRamp < 0 {
+		return fmt.Errorf("invalid %s value: ramp cannot be negative", envConcurrencyRamp)
+	}
+
+	if cfg.ExitCodeTimeout < 0 || cfg.ExitCodeTimeout > 255 {
+		return fmt.Errorf("invalid %s value: exit code must not be negative or greater than 255", envExitCodeTimeout)
+	}
+
+	if cfg.ExitCodeDNS < 0 || cfg.ExitCodeDNS > 255 {
+		return fmt.Errorf("invalid %s value: exit code must not be negative or greater than 255", envExitCodeDNS)
+	}
+
+	if cfg.ExitCodeConnection < 0 || cfg.ExitCodeConnection > 255 {
+		return fmt.Errorf("invalid %s value: exit code must not be negative or greater than 255", envExitCodeConnection)
+	}
+
+	return nil
+}
+
+// validateAddress checks if the given address is valid for the check type.
+// URL based check types expect a URL, all others a 'host:port' address.
+func validateAddress(envName, checkType, address string) error {
+	if address == "" {
+		return fmt.Errorf("%s environment variable is required", envName)
+	}
+
+	if isURLCheckType(checkType) {
+		u, err := url.Parse(address)
+		if err != nil {
+			return fmt.Errorf("invalid %s value: %s", envName, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid %s format, must be a http or https URL", envName)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid %s format, URL must include a host", envName)
+		}
+		return nil
+	}
+
+	if checkType == checkTypeFD {
+		return nil // the PID or name of a local process
+	}
+
+	if checkType == checkTypeExec {
+		return nil // only passed to the command
+	}
+
+	if schema := strings.SplitN(address, "://", 2); len(schema) > 1 {
+		return fmt.Errorf("%s should not include a schema (%s)", envName, schema[0])
+	}
+
+	if checkType == checkTypeDNS {
+		return nil // a host name, the port is optional as it is not dialed
+	}
+
+	if checkType == checkTypeSRV {
+		if strings.Contains(address, ":") {
+			return fmt.Errorf("invalid %s format, must be an SRV record name without a port, e.g. _postgres._tcp.example.com", envName)
+		}
+		return nil // the port is taken from the SRV record
+	}
+
+	if !strings.Contains(address, ":") {
+		return fmt.Errorf("invalid %s format, must be host:port", envName)
+	}
+
+	return nil
+}
+
+// inferTargetName infers the target name from the host part of the target address.
+func inferTargetName(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		address = u.Host
+	}
+	hostPart := strings.SplitN(address, ":", 2)[0]   // get the host part
+	hostSegments := strings.SplitN(hostPart, ".", 2) // get the first part of the host
+	return hostSegments[0]
+}
+
+// openLogFile opens the log file for appending, creating it if necessary.
+func openLogFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envLogFile, err)
+	}
+	return f, nil
+}
+
+// closeLogFile flushes the log file to disk and closes it.
+func closeLogFile(f *os.File) {
+	_ = f.Sync()
+	_ = f.Close()
+}
+
+// setupLogger configures the logger based on the configuration
+func setupLogger(cfg Config, output, errOutput io.Writer) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: cfg.LogLevel}
+
+	if cfg.LogExtraFields {
+		handlerOpts.ReplaceAttr = withLogTime(cfg, nil)
+		logger := slog.New(newStreamsHandler(cfg, output, errOutput, handlerOpts))
+		if cfg.TargetAddress != "" {
+			// with multiple targets, each target logs its own address
+			logger = logger.With(slog.String("target_address", cfg.TargetAddress))
+		}
+		return logger.With(
+			slog.String("interval", cfg.Interval.String()),
+			slog.String("dial_timeout", cfg.DialTimeout.String()),
+			slog.String("version", version),
+		)
+	}
+
+	// If logAdditionalFields is false, remove the error attribute from the handler
+	handlerOpts.ReplaceAttr = withLogTime(cfg, func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == "error" {
+			return slog.Attr{}
+		}
+		return a
+	})
+
+	return slog.New(newStreamsHandler(cfg, output, errOutput, handlerOpts))
+}
+
+// newHandler returns the log handler matching the log format.
+// Text output is colored if enabled, in auto mode only on a terminal.
+func newHandler(cfg Config, output io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if cfg.LogFormat == logFormatJSON {
+		return slog.NewJSONHandler(output, opts)
+	}
+	if resolveColor(cfg.Color, output) == colorAlways {
+		return newColorHandler(output, opts)
+	}
+	return slog.NewTextHandler(output, opts)
+}
+
+// ReportError writes the error returned by Run to output.
+// In JSON log format, the error is written as a structured record to keep the output machine-parseable.
+// The log format is the one Run resolved from the flags, environment variables, ENV_FILE and CONFIG_FILE.
+func ReportError(output io.Writer, err error) {
+	var runErr *runError
+	if errors.As(err, &runErr) && runErr.logFormat == logFormatJSON {
+		slog.New(slog.NewJSONHandler(output, nil)).Error(err.Error(), slog.Int("exit_code", ExitCode(err)))
+		return
+	}
+	fmt.Fprintf(output, "%s\n", err)
+}
+
+// CheckConnection tries to establish a connection to the given address.
+func CheckConnection(ctx context.Context, dialer Dialer, address string) error {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return nil
+}
+
+// Result describes how waiting for a target ended.
+type Result struct {
+	Attempts  int           // The number of checks performed.
+	Elapsed   time.Duration // How long the wait took.
+	LastError error         // The error of the most recent failed check, nil if no check failed.
+}
+
+// WaitForTarget continuously attempts to connect to the single target of the validated configuration
+// until it becomes available or the context is canceled.
+// Besides the error, it returns the result of the wait, which is also set if waiting was abandoned.
+func WaitForTarget(ctx context.Context, cfg Config, logger *slog.Logger) (Result, error) {
+	var result Result
+	check := newTargetCheck(cfg, logger)
+
+	start := time.Now()
+	err := pollTarget(ctx, cfg, logger, func(ctx context.Context) error {
+		result.Attempts++
+		err := check(ctx)
+		if err != nil {
+			result.LastError = err
+		}
+		return err
+	})
+	result.Elapsed = time.Since(start)
+
+	return result, err
+}
+
+// pollTarget runs the check on every interval until it succeeds or the context is canceled.
+// With tracing, the wait and each of its attempts are recorded as spans.
+func pollTarget(ctx context.Context, cfg Config, logger *slog.Logger, check checkFunc) (err error) {
+	ctx, span := startSpan(ctx, "wait "+cfg.TargetName,
+		otlpString("taco.target.name", cfg.TargetName),
+		otlpString("taco.target.address", cfg.TargetAddress),
+		otlpString("taco.check.type", cfg.CheckType),
+	)
+	defer func() { endSpan(ctx, span, err) }()
+	check = withPanicRecovery(cfg, logger, check)
+	check = traceAttempts(check)
+	check = withOnAttempt(cfg.OnAttempt, check)
+
+	if cfg.WaitFor == waitForDown {
+		return pollTargetDown(ctx, cfg, logger, check)
+	}
+
+	if cfg.StartupMessageMode != startupMessageCombined && !cfg.QuietStartup {
+		logger.Info(fmt.Sprintf("Waiting for %s to become ready...", cfg.TargetName))
+	}
+
+	if cfg.HTTPTrace && isURLCheckType(cfg.CheckType) {
+		check = traceHTTPCheck(cfg, logger, check)
+	}
+
+	failures := failureTally{}
+	var attempts, failed, timeouts int
+	var lastErr error
+	var lastReason failureReason
+	var latencies latencyStats
+
+	backoff := newBackoff(cfg)
+
+	var window *successWindow
+	if cfg.WindowSize > 0 {
+		window = newSuccessWindow(cfg.WindowSize)
+	}
+
+	var dedup *logDeduper
+	if cfg.DedupLogs {
+		dedup = &logDeduper{}
+	}
+
+	streak := newSuccessStreak(cfg.SuccessThreshold)
+	stable := newStabilizer(cfg.StabilizeFor)
+
+	waitStart := time.Now()
+	for {
+		start := time.Now()
+		attemptCtx, local := withLocalAddr(ctx)
+		err := check(attemptCtx)
+		attempts++
+		wait := backoff.wait(err != nil)
+		if err == nil {
+			latencies.record(time.Since(start))
+
+			if window != nil && window.add(true) < cfg.WindowSuccesses {
+				logger.Info(fmt.Sprintf("%s check succeeded, waiting for %d successful checks within the last %d", cfg.TargetName, cfg.WindowSuccesses, cfg.WindowSize), window.attr())
+				dedup.reset()
+			} else if !streak.succeeded() {
+				logger.Info(fmt.Sprintf("%s healthy check %s", cfg.TargetName, streak.progress()))
+				dedup.reset()
+			} else if remaining, started := stable.succeeded(time.Now()); remaining > 0 {
+				if started {
+					logger.Info(fmt.Sprintf("%s check succeeded, waiting for it to stay ready for %s", cfg.TargetName, remaining))
+				}
+				dedup.reset()
+				wait = min(wait, remaining) // the target is ready as soon as the duration elapsed
+			} else {
+				var attrs []any
+				if window != nil {
+					attrs = append(attrs, window.attr())
+				}
+				if cfg.LogExtraFields {
+					attrs = append(attrs, latencies.attr("latency"))
+				}
+				attrs = append(attrs, local.attrs()...)
+				logger.InfoContext(withLogTone(ctx, toneReady), formatMessage(cfg.MsgReady, defaultMsgReady, cfg), attrs...)
+				logReadySummary(cfg, logger, attempts, time.Since(waitStart))
+				return nil
+			}
+		} else {
+			var abortErr *abortError
+			if errors.As(err, &abortErr) {
+				logger.Error(fmt.Sprintf("%s cannot become ready ✗", cfg.TargetName), "error", err.Error())
+				return err
+			}
+
+			lastErr = err
+			lastReason = failures.add(err)
+			failed++
+
+			streak.failed()
+			if stable.failed() {
+				logger.Info(fmt.Sprintf("%s failed within %s of %s, waiting for it to stay ready again", cfg.TargetName, envStabilizeFor, cfg.StabilizeFor))
+			}
+
+			attrs := []any{slog.String("error", err.Error())}
+			dialErr := classifyDialError(err)
+			if dialErr != "" {
+				attrs = append(attrs, slog.String("dial_error", string(dialErr)))
+			}
+			if dialErr == dialErrorTimeout {
+				timeouts++
+			}
+			attrs = append(attrs, local.attrs()...)
+			if window != nil {
+				window.add(false)
+				attrs = append(attrs, window.attr())
+			}
+			switch log, summarize := dedup.failure(err, time.Now()); {
+			case log:
+				logger.Log(withLogTone(ctx, toneNotReady), cfg.LogAttempt, notReadyMessage(cfg, lastReason), attrs...)
+			case summarize:
+				logger.Log(withLogTone(ctx, toneNotReady), cfg.LogAttempt, fmt.Sprintf("%s is still not ready after %d attempts, last error: %s", cfg.TargetName, dedup.repeated, err), attrs...)
+			}
+
+			// a record that is missing after several attempts is unlikely to appear, unlike a refused connection
+			if cfg.MaxDNSAttempts > 0 && failures[reasonDNS] >= cfg.MaxDNSAttempts {
+				return &giveUpError{
+					cause:    fmt.Errorf("%s exhausted after %d failed DNS resolutions", envMaxDNSAttempts, failures[reasonDNS]),
+					outcome:  outcomeExhausted,
+					lastErr:  lastErr,
+					reason:   reasonDNS,
+					exitCode: exitCodeFor(cfg, reasonDNS),
+				}
+			}
+
+			// a target that does not answer at all is more likely blocked by the network than still starting
+			if cfg.FailFastOnTimeout > 0 && timeouts >= cfg.FailFastOnTimeout {
+				return &giveUpError{
+					cause:    fmt.Errorf("%s exhausted after %d timed out attempts", envFailFastOnTimeout, timeouts),
+					outcome:  outcomeExhausted,
+					lastErr:  lastErr,
+					reason:   reasonConnection,
+					exitCode: exitCodeFor(cfg, reasonConnection),
+				}
+			}
+
+			// a health check reports the current state, so it must neither retry nor use the exit codes reserved by Docker
+			if cfg.OneShot {
+				return &giveUpError{
+					cause:    fmt.Errorf("%s check failed", envOneShot),
+					outcome:  outcomeFailed,
+					lastErr:  lastErr,
+					reason:   failures.dominant(lastReason),
+					exitCode: 1,
+				}
+			}
+
+			if cfg.MaxRetries > 0 && failed > cfg.MaxRetries {
+				reason := failures.dominant(lastReason)
+				return &giveUpError{
+					cause:    fmt.Errorf("%s exhausted after %d failed attempts", envMaxRetries, failed),
+					outcome:  outcomeExhausted,
+					lastErr:  lastErr,
+					reason:   reason,
+					exitCode: exitCodeFor(cfg, reason),
+				}
+			}
+		}
+
+		select {
+		case <-time.After(wait):
+			// Continue to the next connection attempt after the interval
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil // Treat context cancellation as expected behavior
+			}
+			reason := failures.dominant(lastReason)
+			return &giveUpError{
+				cause:    ctx.Err(),
+				outcome:  outcomeTimeout,
+				lastErr:  lastErr,
+				reason:   reason,
+				exitCode: timeoutExitCode(cfg, exitCodeFor(cfg, reason)),
+			}
+		}
+	}
+}
+
+// validateOneShot checks if one-shot mode is combined with settings that require multiple attempts.
+func validateOneShot(cfg Config) error {
+	if !cfg.OneShot {
+		return nil
+	}
+
+	conflicts := []struct {
+		env string
+		set bool
+	}{
+		{envWindowSize, cfg.WindowSize > 0},
+		{envStableBodyAttempts, cfg.StableBodyAttempts > 1},
+		{envMaxSpread, cfg.MaxSpread > 0},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("%s cannot be combined with %s", envOneShot, conflict.env)
+		}
+	}
+
+	return nil
+}
+
+// delayFirstCheck waits for the given duration before the first check, as some targets accept connections before they are initialized.
+// It returns the context error if the context is done before the delay elapsed.
+func delayFirstCheck(ctx context.Context, delay time.Duration, logger *slog.Logger) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Delaying the first check by %s...", delay))
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// settle waits for the given duration after the targets became ready, so downstreams can warm up.
+// Context cancellation ends the settle period early.
+func settle(ctx context.Context, settleAfter time.Duration, logger *slog.Logger) {
+	if settleAfter <= 0 || ctx.Err() != nil {
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Settling for %s before exiting...", settleAfter))
+
+	select {
+	case <-time.After(settleAfter):
+	case <-ctx.Done():
+	}
+}
+
+// Run is the entry point of the taco command, configured by the command-line arguments and environment variables.
+// It sets up signal handling, flag and configuration parsing, and starts the waitForTarget loop.
+// SIGHUP reloads the configuration while waiting.
+// With SPLIT_STREAMS set, not ready records, warnings and errors are written to errOutput, which also receives
+// the log output if writing to output fails with ON_LOG_ERROR=fallback-stderr.
+func Run(ctx context.Context, args []string, getenv func(string) string, output, errOutput io.Writer) (err error) {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// the log format is resolved along with the configuration, so ReportError can use it for the returned error
+	logFormat := getenv(envLogFormat)
+	defer func() {
+		if err != nil {
+			err = &runError{err: err, logFormat: logFormat}
+		}
+	}()
+
+	flagGetenv, err := withFlags(args, getenv, output)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		// the flags could not be parsed, so EXIT_CODE_CONFIG is only taken from the environment
+		return &configError{err: fmt.Errorf("configuration error: %w", err), exitCode: configExitCode(getenv)}
+	}
+	getenv = flagGetenv
+	logFormat = getenv(envLogFormat)
+
+	cfg, err := ParseConfig(getenv)
+	if err != nil {
+		return &configError{err: fmt.Errorf("configuration error: %w", err), exitCode: configExitCode(getenv)}
+	}
+	logFormat = cfg.LogFormat
+
+	if cfg.LogFile != "" {
+		logFile, err := openLogFile(cfg.LogFile)
+		if err != nil {
+			return &configError{err: fmt.Errorf("configuration error: %w", err), exitCode: configExitCode(getenv)}
+		}
+		defer closeLogFile(logFile)
+
+		output = io.MultiWriter(output, logFile)
+		errOutput = io.MultiWriter(errOutput, logFile)
+	}
+
+	// the precondition is evaluated before validating, as an optional dependency may not be configured at all
+	if cfg.SkipIfUnset != "" && getenv(cfg.SkipIfUnset) == "" {
+		logger := setupLogger(cfg, output, errOutput)
+		logger.Info(fmt.Sprintf("Skipping wait, %s is not set", cfg.SkipIfUnset))
+		return nil
+	}
+
+	if err := ValidateConfig(&cfg); err != nil {
+		return &configError{err: fmt.Errorf("validation error: %w", err), exitCode: configExitCode(getenv)}
+	}
+
+	// the terminal is detected before the output is wrapped
+	cfg.Color = resolveColor(cfg.Color, output)
+
+	var logOutput *logWriter
+	if cfg.OnLogError != logErrorIgnore {
+		var cancelOnLogError context.CancelFunc
+		ctx, cancelOnLogError = context.WithCancel(ctx)
+		defer cancelOnLogError()
+
+		logOutput = newLogWriter(output, cfg.OnLogError, errOutput, cancelOnLogError)
+		output = logOutput
+	}
+
+	logger := setupLogger(cfg, output, errOutput)
+
+	if cfg.StartupMatrix {
+		logStartupMatrix(ctx, cfg, logger)
+	}
+
+	// MAX_WAIT only bounds the wait, so neither the on-ready command nor settling are cut short
+	waitCtx := ctx
+	if cfg.MaxWait > 0 {
+		var cancelWait context.CancelFunc
+		waitCtx, cancelWait = context.WithTimeout(ctx, cfg.MaxWait)
+		defer cancelWait()
+	}
+
+	m := newMetrics(cfg)
+	if cfg.MetricsAddr != "" {
+		metricsCtx, stopMetrics := context.WithCancel(ctx)
+		waitMetrics, err := serveMetrics(metricsCtx, cfg.MetricsAddr, m, logger)
+		if err != nil {
+			stopMetrics()
+			return err
+		}
+		defer waitMetrics() // deferred calls run last in first out, so the server is stopped first
+		defer stopMetrics()
+	}
+
+	if cfg.OTLPEndpoint != "" {
+		tr := newTracer(cfg.OTLPEndpoint, &http.Client{Timeout: otlpExportTimeout})
+		defer tr.export(context.WithoutCancel(ctx), logger) // the spans are exported even if waiting was interrupted
+		waitCtx = withTracer(waitCtx, tr)
+	}
+	waitCtx, span := startSpan(waitCtx, "wait for targets")
+
+	if cfg.Watchdog > 0 {
+		watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+		defer stopWatchdog()
+		go runWatchdog(watchdogCtx, cfg.NotifySocket, cfg.Watchdog/2, logger)
+	}
+
+	start := time.Now()
+	maxWait := cfg.MaxWait // MAX_WAIT is not reloaded
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	// the environment of the process cannot change, so only changes to CONFIG_FILE and ENV_FILE are picked up
+	cfg, err = waitWithReload(waitCtx, cfg, logger, m, reload, func() (Config, error) {
+		newCfg, err := ParseConfig(getenv)
+		if err != nil {
+			return Config{}, fmt.Errorf("configuration error: %w", err)
+		}
+		if err := ValidateConfig(&newCfg); err != nil {
+			return Config{}, fmt.Errorf("validation error: %w", err)
+		}
+		newCfg.Color = cfg.Color // the logger is not reloaded
+		return newCfg, nil
+	})
+	endSpan(waitCtx, span, err)
+	if logOutput != nil && logOutput.abortErr() != nil {
+		return logOutput.abortErr() // waiting was canceled, so the result does not tell whether the targets are ready
+	}
+	outcome, elapsed := waitOutcome(ctx, err), time.Since(start)
+
+	if cfg.LogOutcome {
+		// logged last on every return path, so the outcome can always be parsed from the last line,
+		// a failing required on-ready hook or a target down when monitoring stopped fail the run after all
+		defer func() {
+			if err != nil && outcome == outcomeReady {
+				outcome = waitOutcome(ctx, err)
+			}
+			logOutcome(logger, outcome, m, elapsed)
+		}()
+	}
+
+	if cfg.StatusFile != "" {
+		writeStatusFile(cfg, m, outcome, elapsed, logger)
+	}
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("not ready within %s of %s: %w", envMaxWait, maxWait, err)
+		}
+		return err
+	}
+
+	if cfg.NotifySocket != "" && ctx.Err() == nil {
+		notifyReady(ctx, cfg.NotifySocket, logger)
+	}
+
+	if cfg.GRPCReadyEndpoint != "" && ctx.Err() == nil {
+		reportReady(ctx, cfg, newGRPCClient(), logger)
+	}
+
+	// a failing on-ready hook is logged, but only fails the wait if required
+	if cfg.OnReadyWebhook != "" && ctx.Err() == nil {
+		client := &http.Client{Timeout: onReadyWebhookTimeout}
+		if err := callOnReadyWebhook(ctx, cfg, client, logger); err != nil && cfg.OnReadyExecRequired && ctx.Err() == nil {
+			return err
+		}
+	}
+
+	if cfg.OnReadyExec != "" && ctx.Err() == nil {
+		if err := runOnReadyExec(ctx, cfg, logger, output); err != nil && cfg.OnReadyExecRequired && ctx.Err() == nil {
+			return err
+		}
+	}
+
+	settle(ctx, cfg.SettleAfter, logger)
+
+	if cfg.Monitor && ctx.Err() == nil {
+		if err := monitorTargets(ctx, cfg, logger, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,1451 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newListener starts a TCP listener on a free local port, which is closed when the test ends.
+func newListener(t *testing.T) net.Listener {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	return lis
+}
+
+// closedAddress returns a free local address nothing listens on.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	return lis.Addr().String()
+}
+
+func TestParseEnv(t *testing.T) {
+	t.Run("Valid environment variables", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":      "database",
+			"TARGET_ADDRESS":   "localhost:5432",
+			"INTERVAL":         "1s",
+			"DIAL_TIMEOUT":     "1s",
+			"LOG_EXTRA_FIELDS": "true",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(getenv)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := Config{
+			TargetName:     "database",
+			TargetAddress:  "localhost:5432",
+			Interval:       1 * time.Second,
+			DialTimeout:    1 * time.Second,
+			LogExtraFields: true,
+			LogFormat:      "text",
+			Color:          "auto",
+			LogAttempt:     slog.LevelWarn,
+			OnLogError:     "ignore",
+			CheckType:      "tcp",
+			WaitFor:        "up",
+			Protocol:       "tcp",
+			Backoff:        "constant",
+			HTTPMethod:     "GET",
+			ClockSkew:      5 * time.Minute,
+			S3Region:       "us-east-1",
+
+			OnReadyExecRetryInterval: 1 * time.Second,
+			ReverseCheckTimeout:      5 * time.Second,
+			StartupMessageMode:       "per-target",
+			SuccessThreshold:         1,
+			Match:                    "all",
+
+			ExitCodeDNS:        defaultExitCodeDNS,
+			ExitCodeConnection: defaultExitCodeConnection,
+		}
+		if !reflect.DeepEqual(cfg, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, cfg)
+		}
+	})
+
+	t.Run("Invalid INTERVAL", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"INTERVAL": "-s",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		_, err := ParseConfig(getenv)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := fmt.Sprintf("invalid INTERVAL value: time: invalid duration \"%s\"", env["INTERVAL"])
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid DIAL_TIMEOUT", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"DIAL_TIMEOUT": "-s",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		_, err := ParseConfig(getenv)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := fmt.Sprintf("invalid DIAL_TIMEOUT value: time: invalid duration \"%s\"", env["DIAL_TIMEOUT"])
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid LOG_EXTRA_FIELDS", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"LOG_EXTRA_FIELDS": "tr",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		_, err := ParseConfig(getenv)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := fmt.Sprintf("invalid LOG_EXTRA_FIELDS value: strconv.ParseBool: parsing \"%s\": invalid syntax", env["LOG_EXTRA_FIELDS"])
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+	t.Run("Invalid TLS_INSECURE_SKIP_VERIFY", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TLS_INSECURE_SKIP_VERIFY": "yes",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		_, err := ParseConfig(getenv)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid TLS_INSECURE_SKIP_VERIFY value: strconv.ParseBool: parsing \"yes\": invalid syntax"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid EXIT_CODE_DNS", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"EXIT_CODE_DNS": "dns",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		_, err := ParseConfig(getenv)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := fmt.Sprintf("invalid EXIT_CODE_DNS value: strconv.Atoi: parsing \"%s\": invalid syntax", env["EXIT_CODE_DNS"])
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestValidateEnv(t *testing.T) {
+	t.Run("Valid environment variables", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Generate TARGET_NAME", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetAddress: "localhost:5432",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		if cfg.TargetName == "" {
+			t.Errorf("Expected TargetName to be generated")
+		}
+
+		expected := strings.SplitN(cfg.TargetAddress, ":", 2)[0]
+		if cfg.TargetName != expected {
+			t.Errorf("Expected target name %q but got %q", expected, cfg.TargetName)
+		}
+	})
+
+	t.Run("Missing TARGET_ADDRESS", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName: "database",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "TARGET_ADDRESS environment variable is required"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid TARGET_ADDRESS (port)", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "invalid TARGET_ADDRESS format, must be host:port"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid TARGET_ADDRESS (schema)", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "http://localhost:5432",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "TARGET_ADDRESS should not include a schema (http)"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid INTERVAL", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			Interval:      -1 * time.Second,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "invalid INTERVAL value: interval cannot be negative"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid DIAL_TIMEOUT", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			DialTimeout:   -1 * time.Second,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "invalid DIAL_TIMEOUT value: dial timeout cannot be negative"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid LOG_FORMAT", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			LogFormat:     "xml",
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid LOG_FORMAT value: must be one of text, json"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid MAX_WAIT", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			MaxWait:       -1 * time.Second,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid MAX_WAIT value: wait time cannot be negative"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid INITIAL_DELAY", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			InitialDelay:  -1 * time.Second,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid INITIAL_DELAY value: delay cannot be negative"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid EXIT_CODE_CONNECTION", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:         "database",
+			TargetAddress:      "localhost:5432",
+			ExitCodeConnection: 256,
+		}
+
+		err := ValidateConfig(&cfg)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "invalid EXIT_CODE_CONNECTION value: exit code must not be negative or greater than 255"
+		if err.Error() != expected {
+			t.Errorf("Expected output %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestCheckConnection(t *testing.T) {
+	t.Run("Successful connection", func(t *testing.T) {
+		t.Parallel()
+
+		targetAddress := newListener(t).Addr().String()
+
+		dialer := &net.Dialer{
+			Timeout: 2 * time.Second,
+		}
+
+		ctx := context.Background()
+		if err := CheckConnection(ctx, dialer, targetAddress); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Failed connection", func(t *testing.T) {
+		t.Parallel()
+
+		targetAddress := closedAddress(t)
+
+		dialer := &net.Dialer{
+			Timeout: 2 * time.Second,
+		}
+
+		ctx := context.Background()
+		err := CheckConnection(ctx, dialer, targetAddress)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+}
+
+func TestWaitForTarget(t *testing.T) {
+	t.Run("Target is ready", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: newListener(t).Addr().String(),
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		// cancel WaitForTarget after 2 Seconds
+		go func() {
+			time.Sleep(2 * time.Second)
+			cancel()
+		}()
+
+		_, err := WaitForTarget(ctx, cfg, logger)
+		if err != nil && err != context.Canceled {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := fmt.Sprintf("%s is ready ✓", cfg.TargetName)
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Target is not ready", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		// cancel WaitForTarget after 2 Seconds
+		go func() {
+			time.Sleep(2 * time.Second)
+			cancel()
+		}()
+
+		_, err := WaitForTarget(ctx, cfg, logger)
+		if err != nil && err != context.Canceled {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := fmt.Sprintf("%s is not ready ✗", cfg.TargetName)
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Successful run after 3 attempts", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:     "PostgreSQL",
+			TargetAddress:  closedAddress(t),
+			Interval:       50 * time.Millisecond,
+			DialTimeout:    50 * time.Millisecond,
+			LogExtraFields: true,
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		var lis net.Listener
+		go func() {
+			defer wg.Done() // Mark the WaitGroup as done when the goroutine completes
+			// start listening between the third and fourth attempt
+			time.Sleep(cfg.Interval*3 - cfg.Interval/2)
+			var err error
+			lis, err = net.Listen("tcp", cfg.TargetAddress)
+			if err != nil {
+				panic("failed to listen: " + err.Error())
+			}
+			time.Sleep(200 * time.Millisecond) // Ensure runloop get a successful attempt
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, &slog.HandlerOptions{}))
+		logger = logger.With(
+			"target_name", cfg.TargetName,
+			"target_address", cfg.TargetAddress,
+			"interval", cfg.Interval.String(),
+			"dial_timeout", cfg.DialTimeout.String(),
+			"version", version,
+		)
+
+		if _, err := WaitForTarget(ctx, cfg, logger); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		wg.Wait()
+		defer lis.Close() // listener must be closed after waiting group is done
+
+		stdOutEntries := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+		// output must be:
+		// 0: Waiting for database to become ready...
+		// 1: database is not ready ✗
+		// 2: database is not ready ✗
+		// 3: database is not ready ✗
+		// 4: database is ready ✓
+		// 5: database became ready after 4 attempts in ...
+
+		lenExpectedOuts := 6
+		if len(stdOutEntries) != lenExpectedOuts {
+			t.Errorf("Expected output to contain '%d' lines but got '%d'.", lenExpectedOuts, len(stdOutEntries))
+		}
+
+		expected := fmt.Sprintf("Waiting for %s to become ready...", cfg.TargetName)
+		if !strings.Contains(stdOutEntries[0], expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOutEntries[0])
+		}
+
+		from := 1
+		to := 3
+		for i := from; i < to; i++ {
+			expected = fmt.Sprintf("%s is not ready ✗", cfg.TargetName)
+			if !strings.Contains(stdOutEntries[i], expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOutEntries[i])
+			}
+
+			expected = fmt.Sprintf("error=\"dial tcp %s: connect: connection refused\"", cfg.TargetAddress)
+			if !strings.Contains(stdOutEntries[i], expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOutEntries[i])
+			}
+		}
+
+		expected = fmt.Sprintf("%s is ready ✓", cfg.TargetName)
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-2], expected) { // lenExpectedOuts -2 = ready line before the summary
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOutEntries[1])
+		}
+
+		expected = fmt.Sprintf("version=%s", version)
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-2], expected) { // lenExpectedOuts -2 = ready line before the summary
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOutEntries[1])
+		}
+	})
+
+	t.Run("Failed connection", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		// cancel WaitForTarget after 2 Seconds
+		go func() {
+			time.Sleep(2 * time.Second)
+			cancel()
+		}()
+
+		if _, err := WaitForTarget(ctx, cfg, logger); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := "connect: connection refused"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Context timeout", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		_, err := WaitForTarget(ctx, cfg, logger)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		if code := ExitCode(err); code != defaultExitCodeConnection {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+		}
+
+		expected := "context deadline exceeded"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      10 * time.Millisecond,
+			DialTimeout:   1 * time.Second,
+			MaxRetries:    2,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		_, err := WaitForTarget(context.Background(), cfg, logger)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "MAX_RETRIES exhausted after 3 failed attempts (mostly connection failures, last error: dial tcp "
+		if !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+
+		if code := ExitCode(err); code != defaultExitCodeConnection {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+		}
+
+		if attempts := strings.Count(stdOut.String(), "database is not ready ✗"); attempts != 3 {
+			t.Errorf("Expected 3 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("DNS attempts exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:     "database",
+			TargetAddress:  "database:5432",
+			Interval:       10 * time.Millisecond,
+			MaxDNSAttempts: 2,
+		}
+
+		// connection failures in between do not count towards MAX_DNS_ATTEMPTS
+		errs := []error{
+			&net.DNSError{Err: "no such host", Name: "database", IsNotFound: true},
+			errors.New("connection refused"),
+			errors.New("connection refused"),
+			&net.DNSError{Err: "no such host", Name: "database", IsNotFound: true},
+		}
+		var attempts int
+		check := func(ctx context.Context) error {
+			err := errs[attempts%len(errs)]
+			attempts++
+			return err
+		}
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		err := pollTarget(context.Background(), cfg, logger, check)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "MAX_DNS_ATTEMPTS exhausted after 2 failed DNS resolutions (mostly dns failures, last error: lookup database: no such host)"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+
+		if code := ExitCode(err); code != defaultExitCodeDNS {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeDNS, code)
+		}
+
+		if attempts != 4 {
+			t.Errorf("Expected 4 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("Context cancel", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      1 * time.Second,
+			DialTimeout:   1 * time.Second,
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		// cancel WaitForTarget after 1 Seconds
+		go func() {
+			time.Sleep(1 * time.Second)
+			cancel()
+		}()
+
+		_, err := WaitForTarget(ctx, cfg, logger)
+		// WaitForTarget returns nil if context is canceled
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestWaitForTargetResult(t *testing.T) {
+	t.Run("Ready target", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: newListener(t).Addr().String(),
+			Interval:      50 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+		}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		result, err := WaitForTarget(context.Background(), cfg, logger)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if result.Attempts != 1 {
+			t.Errorf("Expected 1 attempt but got %d", result.Attempts)
+		}
+		if result.LastError != nil {
+			t.Errorf("Expected no last error but got %v", result.LastError)
+		}
+		if result.Elapsed <= 0 {
+			t.Errorf("Expected elapsed time to be recorded but got %s", result.Elapsed)
+		}
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: closedAddress(t),
+			Interval:      10 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+			MaxRetries:    2,
+		}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		result, err := WaitForTarget(context.Background(), cfg, logger)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if result.Attempts != 3 {
+			t.Errorf("Expected 3 attempts but got %d", result.Attempts)
+		}
+		if result.LastError == nil || !strings.Contains(result.LastError.Error(), "connection refused") {
+			t.Errorf("Expected last error to be the refused connection but got %v", result.LastError)
+		}
+	})
+}
+
+func TestConcurrentConnections(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		TargetName:    "database",
+		TargetAddress: newListener(t).Addr().String(),
+		Interval:      1 * time.Second,
+		DialTimeout:   1 * time.Second,
+	}
+
+	var stdOut strings.Builder
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+	var wg sync.WaitGroup
+	numRoutines := 4
+	wg.Add(numRoutines)
+
+	for i := 0; i < numRoutines; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := WaitForTarget(ctx, cfg, logger)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+
+	// Simulate context cancel after 2 seconds
+	go func() {
+		time.Sleep(2 * time.Second)
+		cancel()
+	}()
+
+	wg.Wait()
+
+	expected := fmt.Sprintf("%s is ready ✓", cfg.TargetName)
+	if !strings.Contains(stdOut.String(), expected) {
+		t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Successful run", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"INTERVAL":       "1s",
+			"DIAL_TIMEOUT":   "1s",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// cancel run after 2 Seconds
+		go func() {
+			time.Sleep(2 * time.Second)
+			cancel()
+		}()
+
+		if err := Run(ctx, nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		stdOutEntries := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+
+		lenExpectedOuts := 3
+		if len(stdOutEntries) != lenExpectedOuts {
+			t.Errorf("Expected output to contain '%d' lines but got '%d'", lenExpectedOuts, len(stdOutEntries))
+		}
+
+		expected := fmt.Sprintf("Waiting for %s to become ready...", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[0], expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = fmt.Sprintf("%s is ready ✓", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-2], expected) { // lenExpectedOuts -2 = ready line before the summary
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = fmt.Sprintf("%s became ready", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-1], expected) { // lenExpectedOuts -1 = last element
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Failed run due to invalid address", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": "localhost",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := Run(ctx, nil, getenv, &stdOut, io.Discard)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		expected := "invalid TARGET_ADDRESS format, must be host:port"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Skip if precondition is unset", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"SKIP_IF_UNSET": "KAFKA_ENABLED",
+			// an optional dependency may not be configured at all
+			"TARGET_ADDRESS": "",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := "Skipping wait, KAFKA_ENABLED is not set"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Wait if precondition is set", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"SKIP_IF_UNSET":  "KAFKA_ENABLED",
+			"KAFKA_ENABLED":  "true",
+			"TARGET_NAME":    "kafka",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := "kafka is ready ✓"
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("LogAdditionalFields set to true", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":      "database",
+			"TARGET_ADDRESS":   newListener(t).Addr().String(),
+			"INTERVAL":         "1s",
+			"DIAL_TIMEOUT":     "1s",
+			"LOG_EXTRA_FIELDS": "true",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// cancel run after 2 Seconds
+		go func() {
+			time.Sleep(2 * time.Second)
+			cancel()
+		}()
+
+		if err := Run(ctx, nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		stdOutEntries := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+
+		lenExpectedOuts := 3
+		if len(stdOutEntries) != lenExpectedOuts {
+			t.Errorf("Expected output to contain '%d' lines but got '%d'", lenExpectedOuts, len(stdOutEntries))
+		}
+
+		expected := fmt.Sprintf("Waiting for %s to become ready...", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[0], expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = fmt.Sprintf("%s is ready ✓", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-2], expected) { // lenExpectedOuts -2 = ready line before the summary
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = fmt.Sprintf("version=%s", version)
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-2], expected) { // lenExpectedOuts -2 = ready line before the summary
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = fmt.Sprintf("msg=\"%s became ready\"", env["TARGET_NAME"])
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-1], expected) { // lenExpectedOuts -1 = last element
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = "attempts=1 elapsed="
+		if !strings.Contains(stdOutEntries[lenExpectedOuts-1], expected) { // lenExpectedOuts -1 = last element
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Give up after MAX_WAIT", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": closedAddress(t),
+			"INTERVAL":       "50ms",
+			"MAX_WAIT":       "200ms",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		err := Run(context.Background(), nil, getenv, &stdOut, io.Discard)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "not ready within MAX_WAIT of 200ms: context deadline exceeded"
+		if !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+
+		if code := ExitCode(err); code != defaultExitCodeConnection {
+			t.Errorf("Expected exit code %d but got %d", defaultExitCodeConnection, code)
+		}
+	})
+
+	t.Run("External cancel before MAX_WAIT", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": closedAddress(t),
+			"INTERVAL":       "50ms",
+			"MAX_WAIT":       "1m",
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		var stdOut strings.Builder
+		if err := Run(ctx, nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRunOneShot(t *testing.T) {
+	t.Run("Target is ready", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"ONE_SHOT":       "true",
+		}
+
+		if err := Run(context.Background(), nil, func(key string) string { return env[key] }, io.Discard, io.Discard); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Target is not ready", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": closedAddress(t),
+			"INTERVAL":       "10s", // a retry would exceed the test timeout
+		}
+
+		start := time.Now()
+		err := Run(context.Background(), []string{"-one-shot"}, func(key string) string { return env[key] }, io.Discard, io.Discard)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected a single check but returned after %s", elapsed)
+		}
+
+		if code := ExitCode(err); code != 1 {
+			t.Errorf("Expected exit code 1 but got %d", code)
+		}
+
+		expected := "ONE_SHOT check failed (mostly connection failures, last error: "
+		if !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("Expected error to start with %q but got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Combined with WINDOW_SIZE", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: "localhost:5432",
+			OneShot:       true,
+			WindowSize:    3,
+		}
+
+		err := ValidateConfig(&cfg)
+
+		expected := "ONE_SHOT cannot be combined with WINDOW_SIZE"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestRunLogFile(t *testing.T) {
+	t.Run("Tee log output to file", func(t *testing.T) {
+		t.Parallel()
+
+		logFile := filepath.Join(t.TempDir(), "taco.log")
+		env := map[string]string{
+			"TARGET_NAME":    "database",
+			"TARGET_ADDRESS": newListener(t).Addr().String(),
+			"LOG_FILE":       logFile,
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		var stdOut strings.Builder
+		if err := Run(context.Background(), nil, getenv, &stdOut, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+
+		if string(data) != stdOut.String() {
+			t.Errorf("Expected log file to contain %q but got %q", stdOut.String(), data)
+		}
+	})
+
+	t.Run("Unwritable log file", func(t *testing.T) {
+		t.Parallel()
+
+		logFile := filepath.Join(t.TempDir(), "missing", "taco.log")
+		env := map[string]string{
+			"TARGET_ADDRESS": "localhost:5432",
+			"LOG_FILE":       logFile,
+		}
+
+		getenv := func(key string) string {
+			return env[key]
+		}
+
+		err := Run(context.Background(), nil, getenv, io.Discard, io.Discard)
+
+		expected := fmt.Sprintf("configuration error: invalid LOG_FILE value: open %s: no such file or directory", logFile)
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q but got %v", expected, err)
+		}
+	})
+}
+
+func TestDelayFirstCheck(t *testing.T) {
+	t.Run("Delay before the first check", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: newListener(t).Addr().String(),
+			Interval:      50 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+			CheckType:     checkTypeTCP,
+			InitialDelay:  100 * time.Millisecond,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		start := time.Now()
+		if err := waitForTargets(context.Background(), cfg, logger, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("Expected to delay for at least 100ms but returned after %s", elapsed)
+		}
+
+		expected := "Delaying the first check by 100ms..."
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Deadline during delay", func(t *testing.T) {
+		t.Parallel()
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := delayFirstCheck(ctx, 5*time.Second, logger)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected %v but got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestSettle(t *testing.T) {
+	t.Run("Settle after readiness", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		start := time.Now()
+		settle(context.Background(), 100*time.Millisecond, logger)
+
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("Expected to settle for at least 100ms but returned after %s", elapsed)
+		}
+
+		expected := "Settling for 100ms before exiting..."
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+
+	t.Run("Context cancel during settle", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		settle(ctx, 5*time.Second, logger)
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected settle to end on context cancel but returned after %s", elapsed)
+		}
+	})
+}
+
+func TestSetupLogger(t *testing.T) {
+	t.Run("JSON format with extra fields", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetAddress: "localhost:5432", Interval: 2 * time.Second, DialTimeout: time.Second, LogFormat: logFormatJSON, LogExtraFields: true}
+
+		var output strings.Builder
+		setupLogger(cfg, &output, nil).Warn("postgres is not ready ✗", slog.String("error", "connection refused"))
+
+		var record map[string]any
+		if err := json.Unmarshal([]byte(output.String()), &record); err != nil {
+			t.Fatalf("Expected a JSON record but got %q: %v", output.String(), err)
+		}
+
+		expected := map[string]any{
+			"msg":            "postgres is not ready ✗",
+			"target_address": "localhost:5432",
+			"interval":       "2s",
+			"dial_timeout":   "1s",
+			"error":          "connection refused",
+		}
+		for key, value := range expected {
+			if record[key] != value {
+				t.Errorf("Expected %s %q but got %q", key, value, record[key])
+			}
+		}
+	})
+
+	t.Run("JSON format without extra fields", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{TargetAddress: "localhost:5432", LogFormat: logFormatJSON}
+
+		var output strings.Builder
+		setupLogger(cfg, &output, nil).Warn("postgres is not ready ✗", slog.String("error", "connection refused"))
+
+		var record map[string]any
+		if err := json.Unmarshal([]byte(output.String()), &record); err != nil {
+			t.Fatalf("Expected a JSON record but got %q: %v", output.String(), err)
+		}
+
+		for _, key := range []string{"target_address", "interval", "error"} {
+			if _, ok := record[key]; ok {
+				t.Errorf("Expected no %s attribute but got %q", key, record[key])
+			}
+		}
+	})
+}
+
+func TestReportError(t *testing.T) {
+	t.Run("Text format", func(t *testing.T) {
+		t.Parallel()
+
+		var stdErr strings.Builder
+		err := Run(context.Background(), nil, func(key string) string { return "" }, io.Discard, io.Discard)
+		ReportError(&stdErr, err)
+
+		expected := "validation error: TARGET_ADDRESS environment variable is required\n"
+		if stdErr.String() != expected {
+			t.Errorf("Expected output %q but got %q", expected, stdErr.String())
+		}
+	})
+
+	t.Run("Error not returned by Run", func(t *testing.T) {
+		t.Parallel()
+
+		var stdErr strings.Builder
+		ReportError(&stdErr, &giveUpError{cause: context.DeadlineExceeded, exitCode: 4})
+
+		expected := "context deadline exceeded\n"
+		if stdErr.String() != expected {
+			t.Errorf("Expected output %q but got %q", expected, stdErr.String())
+		}
+	})
+
+	configFile := filepath.Join(t.TempDir(), "taco.yaml")
+	if err := os.WriteFile(configFile, []byte("LOG_FORMAT: json\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "Environment variable", env: map[string]string{"LOG_FORMAT": "json"}},
+		{name: "Flag", args: []string{"-log-format", "json"}},
+		{name: "Config file", env: map[string]string{"CONFIG_FILE": configFile}},
+		{name: "Invalid flag", args: []string{"-bogus"}, env: map[string]string{"LOG_FORMAT": "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("JSON format from "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Run(context.Background(), tt.args, func(key string) string { return tt.env[key] }, io.Discard, io.Discard)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+
+			var stdErr strings.Builder
+			ReportError(&stdErr, err)
+
+			var record map[string]any
+			if err := json.Unmarshal([]byte(stdErr.String()), &record); err != nil {
+				t.Fatalf("Expected a JSON record but got %q: %v", stdErr.String(), err)
+			}
+
+			if record["level"] != "ERROR" {
+				t.Errorf("Expected level %q but got %q", "ERROR", record["level"])
+			}
+			if record["msg"] != err.Error() {
+				t.Errorf("Expected msg %q but got %q", err.Error(), record["msg"])
+			}
+			if record["exit_code"] != float64(1) {
+				t.Errorf("Expected exit_code %d but got %v", 1, record["exit_code"])
+			}
+		})
+	}
+}
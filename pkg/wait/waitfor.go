@@ -0,0 +1,76 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const envWaitFor = "WAIT_FOR"
+
+const (
+	waitForUp   = "up"   // Wait until the target accepts connections.
+	waitForDown = "down" // Wait until the target stops accepting connections.
+)
+
+// pollTargetDown runs the check on every interval until it fails or the context is canceled.
+// It inverts pollTarget, e.g. to wait for a port to be closed during a graceful shutdown.
+func pollTargetDown(ctx context.Context, cfg Config, logger *slog.Logger, check checkFunc) error {
+	if !cfg.QuietStartup {
+		logger.Info(fmt.Sprintf("Waiting for %s to go down...", cfg.TargetName))
+	}
+
+	backoff := newBackoff(cfg)
+
+	var attempts int
+	for {
+		err := check(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.InfoContext(withLogTone(ctx, toneReady), fmt.Sprintf("%s is down ✓", cfg.TargetName), slog.String("error", err.Error()))
+			return nil
+		}
+
+		if err == nil {
+			attempts++
+			logger.Log(withLogTone(ctx, toneNotReady), cfg.LogAttempt, fmt.Sprintf("%s is still up ✗", cfg.TargetName))
+
+			if cfg.OneShot {
+				return fmt.Errorf("%s check failed: %s is still up", envOneShot, cfg.TargetName)
+			}
+
+			if cfg.MaxRetries > 0 && attempts > cfg.MaxRetries {
+				return fmt.Errorf("%s exhausted after %d attempts with %s still up", envMaxRetries, attempts, cfg.TargetName)
+			}
+		}
+
+		select {
+		case <-time.After(backoff.wait(false)):
+			// Continue to the next connection attempt after the interval
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil // Treat context cancellation as expected behavior
+			}
+			return &giveUpError{
+				cause:    fmt.Errorf("%s still up: %w", cfg.TargetName, ctx.Err()),
+				exitCode: timeoutExitCode(cfg, 1),
+			}
+		}
+	}
+}
+
+// validateWaitFor checks if the awaited state is valid.
+func validateWaitFor(cfg *Config) error {
+	switch cfg.WaitFor {
+	case "":
+		cfg.WaitFor = waitForUp
+	case waitForUp:
+	case waitForDown:
+		if cfg.WindowSize > 0 {
+			return fmt.Errorf("%s cannot be combined with %s=%s", envWindowSize, envWaitFor, waitForDown)
+		}
+	default:
+		return fmt.Errorf("invalid %s value: must be one of %s, %s", envWaitFor, waitForUp, waitForDown)
+	}
+	return nil
+}
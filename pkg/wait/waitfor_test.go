@@ -0,0 +1,123 @@
+package wait
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollTargetDown(t *testing.T) {
+	t.Run("Target goes down", func(t *testing.T) {
+		t.Parallel()
+
+		lis := newListener(t)
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: lis.Addr().String(),
+			Interval:      50 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+			CheckType:     checkTypeTCP,
+			WaitFor:       waitForDown,
+		}
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		go func() {
+			time.Sleep(120 * time.Millisecond)
+			lis.Close()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := waitForTargets(ctx, cfg, logger, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, expected := range []string{"database is still up ✗", "database is down ✓"} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+
+	t.Run("Target stays up", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:    "database",
+			TargetAddress: newListener(t).Addr().String(),
+			Interval:      10 * time.Millisecond,
+			DialTimeout:   50 * time.Millisecond,
+			CheckType:     checkTypeTCP,
+			WaitFor:       waitForDown,
+			MaxRetries:    2,
+		}
+
+		logger := slog.New(slog.NewTextHandler(&strings.Builder{}, nil))
+
+		err := waitForTargets(context.Background(), cfg, logger, nil)
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		expected := "MAX_RETRIES exhausted after 3 attempts with database still up"
+		if err.Error() != expected {
+			t.Errorf("Expected error %q but got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestValidateWaitFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "Default",
+			cfg:  Config{},
+		},
+		{
+			name: "Down",
+			cfg:  Config{WaitFor: waitForDown},
+		},
+		{
+			name: "Invalid value",
+			cfg:  Config{WaitFor: "sideways"},
+			err:  "invalid WAIT_FOR value: must be one of up, down",
+		},
+		{
+			name: "Down with window",
+			cfg:  Config{WaitFor: waitForDown, WindowSize: 5},
+			err:  "WINDOW_SIZE cannot be combined with WAIT_FOR=down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateWaitFor(&tt.cfg)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if err.Error() != tt.err {
+				t.Errorf("Expected error %q but got %q", tt.err, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package wait
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const (
+	envWindowSize      = "WINDOW_SIZE"
+	envWindowSuccesses = "WINDOW_SUCCESSES"
+)
+
+// successWindow is a ring buffer of the results of the most recent checks.
+type successWindow struct {
+	results   []bool // The results of the most recent checks.
+	next      int    // The position of the next result.
+	successes int    // The number of successful checks in the window.
+}
+
+// newSuccessWindow returns a window of the given size.
+func newSuccessWindow(size int) *successWindow {
+	return &successWindow{results: make([]bool, 0, size)}
+}
+
+// add records the result of a check and returns the number of successful checks in the window.
+func (w *successWindow) add(success bool) int {
+	if len(w.results) < cap(w.results) {
+		w.results = append(w.results, success)
+	} else {
+		if w.results[w.next] {
+			w.successes--
+		}
+		w.results[w.next] = success
+		w.next = (w.next + 1) % len(w.results)
+	}
+
+	if success {
+		w.successes++
+	}
+
+	return w.successes
+}
+
+// attr returns the ratio of successful checks in the window as log attribute.
+func (w *successWindow) attr() slog.Attr {
+	return slog.String("window", fmt.Sprintf("%d/%d", w.successes, cap(w.results)))
+}
+
+// validateWindow checks if the sliding window settings are valid.
+func validateWindow(cfg *Config) error {
+	if cfg.WindowSize < 0 {
+		return fmt.Errorf("invalid %s value: window size cannot be negative", envWindowSize)
+	}
+
+	if cfg.WindowSize == 0 {
+		if cfg.WindowSuccesses != 0 {
+			return fmt.Errorf("%s requires %s to be set", envWindowSuccesses, envWindowSize)
+		}
+		return nil
+	}
+
+	if cfg.WindowSuccesses == 0 {
+		cfg.WindowSuccesses = cfg.WindowSize // without a number of successes, the whole window must succeed
+	}
+
+	if cfg.WindowSuccesses < 1 || cfg.WindowSuccesses > cfg.WindowSize {
+		return fmt.Errorf("invalid %s value: must be between 1 and %s (%d)", envWindowSuccesses, envWindowSize, cfg.WindowSize)
+	}
+
+	return nil
+}
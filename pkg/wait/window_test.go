@@ -0,0 +1,127 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuccessWindow(t *testing.T) {
+	t.Run("Count successes in window", func(t *testing.T) {
+		t.Parallel()
+
+		window := newSuccessWindow(3)
+
+		tests := []struct {
+			success   bool
+			successes int
+		}{
+			{true, 1},
+			{false, 1},
+			{true, 2},
+			{true, 2}, // the first success is evicted
+			{false, 2},
+			{false, 1},
+			{false, 0},
+		}
+
+		for i, tt := range tests {
+			if successes := window.add(tt.success); successes != tt.successes {
+				t.Errorf("Expected %d successes after result %d but got %d", tt.successes, i+1, successes)
+			}
+		}
+	})
+
+	t.Run("Window ratio attribute", func(t *testing.T) {
+		t.Parallel()
+
+		window := newSuccessWindow(5)
+		window.add(true)
+		window.add(false)
+		window.add(true)
+
+		if attr := window.attr().String(); attr != "window=2/5" {
+			t.Errorf("Expected attribute %q but got %q", "window=2/5", attr)
+		}
+	})
+}
+
+func TestValidateWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		successes int
+		expected  int
+		err       string
+	}{
+		{name: "Disabled", size: 0, successes: 0, expected: 0},
+		{name: "Default successes", size: 4, successes: 0, expected: 4},
+		{name: "Valid successes", size: 5, successes: 3, expected: 3},
+		{name: "Negative size", size: -1, err: "invalid WINDOW_SIZE value: window size cannot be negative"},
+		{name: "Successes without size", size: 0, successes: 2, err: "WINDOW_SUCCESSES requires WINDOW_SIZE to be set"},
+		{name: "Too many successes", size: 3, successes: 4, err: "invalid WINDOW_SUCCESSES value: must be between 1 and WINDOW_SIZE (3)"},
+		{name: "Negative successes", size: 3, successes: -1, err: "invalid WINDOW_SUCCESSES value: must be between 1 and WINDOW_SIZE (3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{WindowSize: tt.size, WindowSuccesses: tt.successes}
+			err := validateWindow(&cfg)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.WindowSuccesses != tt.expected {
+				t.Errorf("Expected %d window successes but got %d", tt.expected, cfg.WindowSuccesses)
+			}
+		})
+	}
+}
+
+func TestPollTargetWindow(t *testing.T) {
+	t.Run("Ready after enough successes in window", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{
+			TargetName:      "flaky",
+			Interval:        10 * time.Millisecond,
+			WindowSize:      3,
+			WindowSuccesses: 2,
+		}
+
+		results := []error{nil, errors.New("refused"), errors.New("refused"), nil, nil}
+		attempts := 0
+		check := func(ctx context.Context) error {
+			err := results[attempts]
+			attempts++
+			return err
+		}
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		if err := pollTarget(context.Background(), cfg, logger, check); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// the first success dropped out of the window before the second one arrived
+		if attempts != 5 {
+			t.Errorf("Expected %d attempts but got %d", 5, attempts)
+		}
+
+		if !strings.Contains(output.String(), "msg=\"flaky is ready ✓\" window=2/3") {
+			t.Errorf("Expected ready message with window ratio but got %q", output.String())
+		}
+	})
+}